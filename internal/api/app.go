@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/unanet/eve-bot/internal/config"
+	"github.com/unanet/eve-bot/internal/tracing"
 	"github.com/unanet/go/pkg/log"
 	"github.com/unanet/go/pkg/metrics"
 	"github.com/unanet/go/pkg/middleware"
@@ -39,16 +40,21 @@ type Api struct {
 	sigChannel  chan os.Signal
 	config      config.Config
 	onShutdown  []func()
+	background  *background
+	// shutdownTracing flushes the traces, last (after the handlers and the background components are done)
+	shutdownTracing func(context.Context) error
 }
 
 func NewApi() *Api {
 	cfg := config.Load()
 	router := chi.NewMux()
+	bg := newBackground()
 
 	return &Api{
 		r:           router,
 		config:      cfg,
-		controllers: initController(&cfg),
+		controllers: initController(&cfg, bg),
+		background:  bg,
 		server: &http.Server{
 			ReadTimeout:  time.Duration(5) * time.Second,
 			WriteTimeout: time.Duration(30) * time.Second,
@@ -94,6 +100,18 @@ func (a *Api) gracefulShutdown() {
 	if err := a.server.Shutdown(ctx); err != nil {
 		panic("HTTP API Server Failed Graceful Shutdown")
 	}
+	// the background components are stopped once the handlers are done (they publish the webhook events),
+	// Stop also waits for the running slack commands
+	if err := a.background.Stop(ctx); err != nil {
+		log.Logger.Warn("the background components didn't stop in time", zap.Error(err))
+	}
+	// the traces are flushed last (the spans of the commands and the background components end before),
+	// with their own timeout in case the background components used up the shutdown timeout
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer tracingCancel()
+	if err := a.shutdownTracing(tracingCtx); err != nil {
+		log.Logger.Warn("failed to flush the traces", zap.Error(err))
+	}
 	if err := log.Logger.Sync(); err != nil {
 		// not much to do here
 	}
@@ -104,6 +122,12 @@ func (a *Api) gracefulShutdown() {
 func (a *Api) Start(onShutdown ...func()) {
 	a.setup()
 	a.onShutdown = onShutdown
+
+	shutdownTracing, err := tracing.Init(context.Background(), a.config.TracingConfig, a.config.ServiceName)
+	if err != nil {
+		log.Logger.Panic("Unable to Initialize the Tracing", zap.Error(err))
+	}
+	a.shutdownTracing = shutdownTracing
 	a.mServer = metrics.StartMetricsServer(a.config.MetricsPort)
 
 	signal.Notify(a.sigChannel, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
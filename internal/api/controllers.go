@@ -1,6 +1,11 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -9,10 +14,17 @@ import (
 	"github.com/unanet/eve-bot/internal/botcommander/commands/handlers"
 	"github.com/unanet/eve-bot/internal/botcommander/executor"
 	"github.com/unanet/eve-bot/internal/botcommander/resolver"
+	"github.com/unanet/eve-bot/internal/changelog"
 	chat "github.com/unanet/eve-bot/internal/chatservice"
 	"github.com/unanet/eve-bot/internal/config"
+	"github.com/unanet/eve-bot/internal/cronrouter"
+	"github.com/unanet/eve-bot/internal/datastore"
 	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/kube"
 	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/eve-bot/internal/subscriptions"
+	"github.com/unanet/eve-bot/internal/sweeper"
+	"github.com/unanet/eve-bot/internal/webhooks"
 	"github.com/unanet/go/pkg/identity"
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
@@ -22,10 +34,64 @@ type Controller interface {
 	Setup(chi.Router)
 }
 
-// initController initializes the controller (handlers)
-func initController(cfg *config.Config) []Controller {
-	eveAPI := eveapi.New(cfg.EveAPIConfig)
+// background runs the long running components (the deployment sweeper, the cron digests and the webhook deliveries)
+// until the server shuts down
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+// run starts the component with the shutdown context
+func (b *background) run(fn func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn(b.ctx)
+	}()
+}
+
+// track runs the work of a request (i.e. a slack command) past its response,
+// Stop waits for it without cancelling it (the work has its own context)
+func (b *background) track(fn func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn()
+	}()
+}
+
+// Stop cancels the shutdown context and waits for the components to return (until the ctx is done)
+func (b *background) Stop(ctx context.Context) error {
+	b.cancel()
+	drained := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// initController initializes the controller (handlers) and starts the background components
+func initController(cfg *config.Config, bg *background) []Controller {
 	chatSvc := chat.New(chat.Slack, cfg)
+	eveAPI := eveapi.NewCachedClient(eveapi.New(cfg.EveAPIConfig, eveapi.StateChangeOpt(func(state eveapi.BreakerState, since time.Time) {
+		chatSvc.PostMessage(context.Background(), breakerStateMsg(state, since), cfg.DevopsMonitoringChannel)
+	})), eveapi.CacheTTLs{
+		Environments: cfg.EveapiCacheEnvironmentsTTL,
+		Namespaces:   cfg.EveapiCacheNamespacesTTL,
+		Services:     cfg.EveapiCacheServicesTTL,
+	})
 
 	awsSession, err := session.NewSession(&aws.Config{Region: aws.String(cfg.AWSRegion)})
 	if err != nil {
@@ -37,20 +103,64 @@ func initController(cfg *config.Config) []Controller {
 		log.Logger.Panic("Unable to Initialize the Identity Service Provider", zap.Error(err))
 	}
 
-	svc := service.New(cfg,
+	db := dynamodb.New(awsSession)
+	store := datastore.New(cfg.DatastoreConfig, db)
+	cronRouter := cronrouter.New(cfg.CronRouterConfig, store, chatSvc, cfg.LoggingDashboardBaseURL)
+	dispatcher := webhooks.New(cfg.WebhooksConfig)
+
+	opts := []service.Option{
 		service.ChatProviderParam(chatSvc),
-		service.DynamoParam(dynamodb.New(awsSession)),
+		service.MaskerParam(eveapi.NewMasker(cfg.MetadataMaskPatterns, cfg.MetadataMaskEntropy, cfg.MetadataMaskMinLength)),
+		service.DynamoParam(db),
 		service.EveAPIParam(eveAPI),
-		service.ResolverParam(resolver.New(commands.NewFactory())),
+		service.AliasStoreParam(store),
+		service.ChannelContextStoreParam(store),
+		service.MetadataHistoryStoreParam(store),
+		service.DeploymentStoreParam(store),
+		service.CronRouterParam(cronRouter),
+		service.SubscriptionStoreParam(store),
+		service.ConfirmationStoreParam(store),
+		service.NotifierParam(subscriptions.New(store, chatSvc)),
+		service.WebhooksParam(dispatcher),
+		service.ResolverParam(resolver.New(commands.NewFactory(), resolver.AliasStoreOpt(store), resolver.ChannelContextStoreOpt(store))),
 		service.OpenIDConnectParam(cfg, idSvc),
-	)
+	}
+	// the release notes are optional (the git mirrors of the artifacts)
+	if len(cfg.ChangelogGitMirrorDir) > 0 {
+		gitLog, err := changelog.NewGitLog(cfg.ChangelogConfig)
+		if err != nil {
+			log.Logger.Panic("Unable to Initialize the Changelog", zap.Error(err))
+		}
+		opts = append(opts, service.ChangelogProviderParam(gitLog))
+	}
+	// the pods and logs commands are optional (the read-only service accounts of the clusters)
+	if len(cfg.KubernetesClusters) > 0 {
+		kubeClient, err := kube.New(cfg.KubernetesConfig)
+		if err != nil {
+			log.Logger.Panic("Unable to Initialize the Kubernetes Clients", zap.Error(err))
+		}
+		opts = append(opts, service.KubernetesProviderParam(kubeClient))
+	}
+	svc := service.New(cfg, opts...)
 
 	exe := executor.New(svc, handlers.NewFactory())
 
+	bg.run(sweeper.New(cfg.SweeperConfig, store, chatSvc, cfg.DevopsMonitoringChannel, cfg.LoggingDashboardBaseURL).Run)
+	bg.run(cronRouter.Run)
+	bg.run(dispatcher.Run)
+
 	return []Controller{
 		NewPingController(),
-		NewSlackController(svc, exe),
+		NewSlackController(svc, exe, bg),
 		NewEveController(svc),
 		NewAuthController(svc),
 	}
 }
+
+// breakerStateMsg is the DevopsMonitoringChannel message of the eve-api circuit breaker transitions
+func breakerStateMsg(state eveapi.BreakerState, since time.Time) string {
+	if state == eveapi.BreakerOpen {
+		return fmt.Sprintf(":red_circle: %s, the changes are rejected and the reads are served from the last known good responses", eveapi.UnavailableError{Since: since})
+	}
+	return fmt.Sprintf(":large_green_circle: eve-api is available again (it was unavailable for %s)", time.Since(since).Round(time.Second))
+}
@@ -1,18 +1,57 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	goerrors "errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve-bot/internal/changelog"
+	"github.com/unanet/eve-bot/internal/datastore"
 	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/tracing"
+	"github.com/unanet/eve-bot/internal/webhooks"
 
 	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
 	"github.com/go-chi/render"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/unanet/eve-bot/internal/service"
 	"github.com/unanet/eve/pkg/eve"
 	"github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
 )
 
+var statCallbacks = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "eve_callbacks_total",
+		Help: "The total number of eve callbacks received by type (deploy or cron) and deployment plan status",
+	}, []string{"type", "status"})
+
+var statRejectedCallbacks = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "eve_callbacks_rejected_total",
+		Help: "The total number of rejected eve callbacks by type (deploy or cron) and reason (unsigned, expired or invalid)",
+	}, []string{"type", "reason"})
+
+// callbackStatus is the status label of a callback (the statuses eve doesn't define are "unknown")
+func callbackStatus(status eve.DeploymentPlanStatus) string {
+	switch status {
+	case eve.DeploymentPlanStatusPending, eve.DeploymentPlanStatusDryrun, eve.DeploymentPlanStatusErrors,
+		eve.DeploymentPlanStatusComplete, eve.DeploymentPlanStatusMessage:
+		return string(status)
+	}
+	return "unknown"
+}
+
 // EveController for eve specific routes
 type EveController struct {
 	svc *service.Provider
@@ -25,31 +64,104 @@ func NewEveController(svc *service.Provider) *EveController {
 
 // Setup the routes
 func (c EveController) Setup(r chi.Router) {
-	r.Post("/eve-callback", c.eveCallbackHandler)
-	r.Post("/eve-cron-callback", c.eveCronCallbackHandler)
+	r.With(c.signedCallback).Post("/eve-callback", c.eveCallbackHandler)
+	r.With(c.authenticatedCronCallback).Post("/eve-cron-callback", c.eveCronCallbackHandler)
+}
+
+// signedCallback rejects the deployment callbacks without a valid signature (see eveapi.SignCallback)
+func (c EveController) signedCallback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := eveapi.VerifyCallback(r.URL.Query(), c.svc.Cfg.EveapiCallbackSecret, time.Now()); err != nil {
+			c.rejectCallback(w, r, "deploy", err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticatedCronCallback rejects the cron callbacks without the bearer token
+// (every cron callback is rejected when the token isn't configured)
+func (c EveController) authenticatedCronCallback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := jwtauth.TokenFromHeader(r)
+		if len(token) == 0 || len(c.svc.Cfg.EveapiCronCallbackToken) == 0 {
+			c.rejectCallback(w, r, "cron", errors.ErrEmptyToken)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(c.svc.Cfg.EveapiCronCallbackToken)) != 1 {
+			c.rejectCallback(w, r, "cron", errors.ErrInvalidToken)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c EveController) rejectCallback(w http.ResponseWriter, r *http.Request, callbackType string, err error) {
+	reason := "invalid"
+	switch err {
+	case eveapi.ErrCallbackUnsigned, errors.ErrEmptyToken:
+		reason = "unsigned"
+	case eveapi.ErrCallbackExpired:
+		reason = "expired"
+	}
+	statRejectedCallbacks.WithLabelValues(callbackType, reason).Inc()
+	log.Logger.Warn("rejected eve callback", zap.String("type", callbackType), zap.String("reason", reason),
+		zap.String("remote_addr", r.RemoteAddr), zap.Error(err))
+	render.Respond(w, r, botError(err, "unauthorized callback", http.StatusUnauthorized))
 }
 
 func (c EveController) eveCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	// Extract the URL Params
-	channel := r.URL.Query().Get("channel")
-	user := r.URL.Query().Get("user")
-	ts := r.URL.Query().Get("ts")
+	// the callback continues the trace of the deploy command (traceparent param)
+	ctx, span := tracing.Start(tracing.ExtractQuery(r.Context(), r.URL.Query()), "eve.callback")
+	defer span.End()
 
 	// Get the Body
 	payload := eve.NSDeploymentPlan{}
 
+	// the deployment record has the user and the thread of the command
+	id := r.URL.Query().Get(eveapi.CallbackIDParam)
+
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		c.svc.ChatService.ErrorNotificationThread(r.Context(), user, channel, ts, err)
+		tracing.Error(span, err)
+		statCallbacks.WithLabelValues("deploy", "invalid").Inc()
+		if deployment, readErr := c.svc.Deployments.ReadDeployment(ctx, id); readErr == nil {
+			c.svc.ChatService.ErrorNotificationThread(ctx, deployment.User, deployment.Channel, deployment.TS, err)
+		} else {
+			log.Logger.Error("failed to read the deployment of an invalid eve callback", zap.Error(readErr), zap.String("deployment", id))
+		}
+		render.Respond(w, r, errors.Wrap(err))
+		return
+	}
+	statCallbacks.WithLabelValues("deploy", callbackStatus(payload.Status)).Inc()
+
+	deployment, err := c.svc.Deployments.RecordDeploymentCallback(ctx, id, payload)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Logger.Error("failed to correlate the eve callback", zap.Error(err), zap.String("deployment", id),
+			zap.String("deployment_plan", payload.DeploymentID.String()))
+		if goerrors.Is(err, errors.ErrNotFound) {
+			err = botError(err, "unknown deployment", http.StatusNotFound)
+		}
 		render.Respond(w, r, errors.Wrap(err))
 		return
 	}
 
-	cbState := eveapi.CallbackState{User: user, Channel: channel, Payload: payload, TS: ts}
-	c.svc.ChatService.PostMessageThread(r.Context(), cbState.ToChatMsg(), cbState.Channel, cbState.TS)
+	cbState := eveapi.CallbackState{User: deployment.User, Channel: deployment.Channel, Payload: payload, TS: deployment.TS}
+	c.invalidateDeployed(cbState.Payload)
+	cbState.ReleaseNotes = changelog.Message(changelog.Collect(ctx, c.svc.Changelog, payload))
+	c.svc.ChatService.PostMessageThread(ctx, cbState.ToChatMsg(), cbState.Channel, cbState.TS)
+	if notes, truncated := cbState.FullReleaseNotesMsg(); truncated {
+		c.svc.ChatService.PostMessageThread(ctx, notes, cbState.Channel, cbState.TS)
+	}
+	c.svc.Notifier.Notify(ctx, payload, deployment.User)
+	c.publishDeployment(ctx, payload, webhooks.DeploymentData{Deployment: deployment.ID, User: deployment.User, Channel: deployment.Channel})
 
 	if cbState.Payload.Status == eve.DeploymentPlanStatusErrors {
-		c.svc.ChatService.PostLinkMessageThread(r.Context(), c.svc.Cfg.LoggingDashboardBaseURL, user, channel, ts)
+		c.svc.ChatService.PostLinkMessageThread(ctx, c.svc.Cfg.LoggingDashboardBaseURL, deployment.User, deployment.Channel, deployment.TS)
+		if hint := logsHint(payload); len(hint) > 0 && c.svc.Kubernetes != nil {
+			c.svc.ChatService.PostMessageThread(ctx, hint, deployment.Channel, deployment.TS)
+		}
 	}
 
 	render.Respond(w, r, nil)
@@ -59,30 +171,68 @@ func (c EveController) eveCronCallbackHandler(w http.ResponseWriter, r *http.Req
 	// Extract the URL Params
 	channel := r.URL.Query().Get("channel")
 
+	ctx, span := tracing.Start(r.Context(), "eve.cron_callback")
+	defer span.End()
+
 	// Get the Body
 	payload := eve.NSDeploymentPlan{}
 
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
-		c.svc.ChatService.ErrorNotification(r.Context(), "", channel, err)
+		tracing.Error(span, err)
+		statCallbacks.WithLabelValues("cron", "invalid").Inc()
+		c.svc.ChatService.ErrorNotification(ctx, "", channel, err)
 		render.Respond(w, r, errors.Wrap(err))
 		return
 	}
 
-	user := ""
-	if payload.Status == eve.DeploymentPlanStatusErrors {
-		user = "channel"
+	statCallbacks.WithLabelValues("cron", callbackStatus(payload.Status)).Inc()
+	c.invalidateDeployed(payload)
+	if payload.Status == eve.DeploymentPlanStatusPending || payload.NothingToDeploy() {
+		render.Respond(w, r, nil)
+		return
 	}
+	// the result is posted to the channels of its route (the channel param when no route matches)
+	c.svc.CronRouter.Post(ctx, payload, channel)
+	c.svc.Notifier.Notify(ctx, payload, "")
+	c.publishDeployment(ctx, payload, webhooks.DeploymentData{})
 
-	cbState := eveapi.CallbackState{User: user, Channel: channel, Payload: payload}
-	if cbState.Payload.Status == eve.DeploymentPlanStatusPending || cbState.Payload.NothingToDeploy() {
-		render.Respond(w, r, nil)
+	render.Respond(w, r, nil)
+}
+
+// publishDeployment publishes the result of a namespace deployment plan once it has a terminal status
+func (c EveController) publishDeployment(ctx context.Context, payload eve.NSDeploymentPlan, data webhooks.DeploymentData) {
+	eventType := webhooks.DeploymentEventType(payload.Status)
+	if len(eventType) == 0 || payload.NothingToDeploy() {
 		return
 	}
-	ts := c.svc.ChatService.PostMessage(r.Context(), cbState.ToChatMsg(), cbState.Channel)
-	if cbState.Payload.Status == eve.DeploymentPlanStatusErrors {
-		c.svc.ChatService.PostLinkMessageThread(r.Context(), c.svc.Cfg.LoggingDashboardBaseURL, user, channel, ts)
+	data.Result = datastore.NewDeploymentResult(payload)
+	c.svc.Webhooks.Publish(ctx, eventType, data)
+}
+
+// logsHint suggests the pods and logs commands of the failed services
+func logsHint(payload eve.NSDeploymentPlan) string {
+	if payload.Namespace == nil {
+		return ""
+	}
+	var hints []string
+	for _, svc := range payload.Services {
+		if svc != nil && svc.DeployArtifact != nil && svc.Result == eve.DeployArtifactResultFailed {
+			hints = append(hints, fmt.Sprintf("`@evebot logs %s in %s %s`", svc.ServiceName, payload.Namespace.Alias, payload.EnvironmentName))
+		}
 	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return "the logs of the failed services: " + strings.Join(hints, ", ") + " (or `show pods for ...`)"
+}
 
-	render.Respond(w, r, nil)
+// invalidateDeployed drops the cached services and namespaces once a deployment is done (the deployed versions changed)
+func (c EveController) invalidateDeployed(payload eve.NSDeploymentPlan) {
+	if payload.Status == eve.DeploymentPlanStatusPending {
+		return
+	}
+	if cache, ok := c.svc.EveAPI.(interfaces.EveAPICache); ok {
+		cache.Invalidate(resources.ServiceName, resources.NamespaceName)
+	}
 }
@@ -7,30 +7,66 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/commands/handlers"
+	"github.com/unanet/eve-bot/internal/botcommander/confirm"
 	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
 	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/eve-bot/internal/tracing"
 	"github.com/unanet/go/pkg/errors"
 	"github.com/unanet/go/pkg/log"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+var statCommandsResolved = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commands_resolved_total",
+		Help: "The total number of resolved commands by command name and validity (valid, help or invalid)",
+	}, []string{"command", "validity"})
+
+// countResolved counts the resolved command (the unknown commands share the "unknown" name)
+func countResolved(cmd commands.EvebotCommand, valid bool) {
+	name := cmd.Info().CommandName
+	if len(name) == 0 {
+		name = "unknown"
+	}
+	validity := "valid"
+	switch {
+	case valid:
+	case cmd.Info().IsHelpRequest || cmd.Info().IsRootCmd:
+		validity = "help"
+	default:
+		validity = "invalid"
+	}
+	statCommandsResolved.WithLabelValues(name, validity).Inc()
+}
+
 // SlackController for slack routes
 type SlackController struct {
 	svc *service.Provider
 	exe interfaces.CommandExecutor
+	bg  *background
 }
 
 // NewSlackController creates a new slack controller (route handler)
-func NewSlackController(svc *service.Provider, exe interfaces.CommandExecutor) *SlackController {
+// the commands run in the background (the shutdown waits for them)
+func NewSlackController(svc *service.Provider, exe interfaces.CommandExecutor, bg *background) *SlackController {
 	return &SlackController{
 		svc: svc,
 		exe: exe,
+		bg:  bg,
 	}
 }
 
@@ -41,7 +77,15 @@ func (c SlackController) Setup(r chi.Router) {
 }
 
 func (c SlackController) slackInteractiveHandler(w http.ResponseWriter, r *http.Request) {
-	if err := handleSlackInteraction(r); err != nil {
+	body, err := validateSlackRequest(r, c.svc.Cfg.SlackSigningSecret)
+	if err != nil {
+		render.Respond(w, r, errors.Wrap(err))
+		return
+	}
+	ctx, span := tracing.Start(context.Background(), "slack.interaction")
+	defer span.End()
+	if err := c.handleSlackInteraction(ctx, body); err != nil {
+		tracing.Error(span, err)
 		render.Respond(w, r, errors.Wrap(err))
 		return
 	}
@@ -83,11 +127,16 @@ func (c SlackController) slackEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	innerEvent := slackAPIEvent.InnerEvent
+
+	// the commands outlive the request (the root span of the command trace)
+	ctx, span := tracing.Start(context.Background(), "slack.event", attribute.String("slack.event", innerEvent.Type))
+	defer span.End()
 	switch ev := innerEvent.Data.(type) {
 	case *slack.FileSharedEvent:
 		log.Logger.Info("File Uploaded", zap.Any("event", ev))
+		c.handleSlackFileSharedEvent(ctx, ev)
 	case *slackevents.AppMentionEvent:
-		c.handleSlackAppMentionEvent(context.TODO(), ev)
+		c.handleSlackAppMentionEvent(ctx, ev, eventHasFiles(body))
 	default:
 		log.Logger.Info("slack innerEvent", zap.Any("event", innerEvent))
 		render.Respond(w, r, errors.Wrap(unknownSlackEventError(innerEvent)))
@@ -148,34 +197,133 @@ func validateSlackRequest(req *http.Request, signingSecret string) ([]byte, erro
 }
 
 // handleSlackInteraction handles the interactive callbacks (buttons, dropdowns, etc.)
-func handleSlackInteraction(req *http.Request) error {
+func (c SlackController) handleSlackInteraction(ctx context.Context, body []byte) error {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return errors.RestError{Code: http.StatusBadRequest, Message: "failed to parse interactive slack message", OriginalError: err}
+	}
 	var payload slack.InteractionCallback
-	err := json.Unmarshal([]byte(req.FormValue("payload")), &payload)
+	err = json.Unmarshal([]byte(form.Get("payload")), &payload)
 	if err != nil {
 		return errors.RestError{Code: http.StatusBadRequest, Message: "failed to parse interactive slack message payload", OriginalError: err}
 	}
-	log.Logger.Info(fmt.Sprintf("Message button pressed by user %s with value %s", payload.User.Name, payload.Value))
+	for _, action := range payload.ActionCallback.BlockActions {
+		switch action.ActionID {
+		case chatmodels.ConfirmActionID, chatmodels.CancelActionID:
+			c.handleConfirmation(ctx, payload, action)
+		default:
+			log.Logger.Info(fmt.Sprintf("Message button pressed by user %s with value %s", payload.User.Name, action.Value))
+		}
+	}
 	return nil
 }
 
-func (c SlackController) handleSlackAppMentionEvent(ctx context.Context, ev *slackevents.AppMentionEvent) {
+// handleConfirmation handles the Confirm/Cancel buttons of a pending confirmation
+func (c SlackController) handleConfirmation(ctx context.Context, payload slack.InteractionCallback, action *slack.BlockAction) {
+	channel, ts := payload.Container.ChannelID, payload.Container.ThreadTs
+	req, err := c.svc.Confirmations.Take(ctx, action.Value, payload.User.ID)
+	if err != nil {
+		if goerror.Is(err, confirm.ErrExpired) || goerror.Is(err, confirm.ErrWrongUser) {
+			c.svc.ChatService.UserNotificationThread(ctx, err.Error(), payload.User.ID, channel, ts)
+			return
+		}
+		c.svc.ChatService.ErrorNotificationThread(ctx, payload.User.ID, channel, ts, err)
+		return
+	}
+	if action.ActionID == chatmodels.CancelActionID {
+		c.svc.ChatService.UserNotificationThread(ctx, "cancelled", req.User, req.Channel, req.Timestamp)
+		return
+	}
+	c.bg.track(func() { handlers.ApplyConfirmation(ctx, c.svc, req) })
+}
+
+// handleSlackFileSharedEvent handles the files shared with a `@evebot set metadata ...` message (metadata file upload)
+func (c SlackController) handleSlackFileSharedEvent(ctx context.Context, ev *slack.FileSharedEvent) {
+	fileID := ev.FileID
+	if len(fileID) == 0 {
+		fileID = ev.File.ID
+	}
+	file, err := c.svc.ChatService.GetFile(ctx, fileID)
+	if err != nil {
+		log.Logger.Error("failed to get the shared file", zap.String("file", fileID), zap.Error(err))
+		return
+	}
+	// the files that aren't shared with a mention are none of our business
+	if len(file.Channel) == 0 || !strings.HasPrefix(strings.TrimSpace(file.Comment), "<@") {
+		return
+	}
+
+	cmd := c.svc.CommandResolver.Resolve(ctx, file.Comment, file.Channel, file.User)
+	if !commands.IsMetadataUpload(cmd) {
+		return
+	}
+	cmd = commands.NewFileCommand(cmd, *file)
+	ackMsg, cont := cmd.AckMsg()
+	countResolved(cmd, cont)
+	if !c.authorize(ctx, cmd, file.ThreadTimestamp) {
+		return
+	}
+
+	timeStamp := c.svc.ChatService.PostMessageThread(ctx, ackMsg, cmd.Info().Channel, file.ThreadTimestamp)
+	if cont {
+		c.bg.track(func() { c.exe.Execute(ctx, cmd, timeStamp) })
+	}
+}
+
+// eventHasFiles checks if the event message has files attached (the slackevents types don't include the files)
+func eventHasFiles(body []byte) bool {
+	var msg struct {
+		Event struct {
+			Files []json.RawMessage `json:"files"`
+		} `json:"event"`
+	}
+	return json.Unmarshal(body, &msg) == nil && len(msg.Event.Files) > 0
+}
+
+func (c SlackController) handleSlackAppMentionEvent(ctx context.Context, ev *slackevents.AppMentionEvent, hasFiles bool) {
 	// Resolve the input and return an EvebotCommand object
-	cmd := c.svc.CommandResolver.Resolve(ev.Text, ev.Channel, ev.User)
+	cmd := c.svc.CommandResolver.Resolve(ctx, ev.Text, ev.Channel, ev.User)
+
+	// the metadata file upload is handled by the file shared event
+	if hasFiles && commands.IsMetadataUpload(cmd) {
+		return
+	}
+
+	// Hydrate the Acknowledgement Message and whether we should continue...
+	ackMsg, cont := cmd.AckMsg()
+	countResolved(cmd, cont)
+
+	if !c.authorize(ctx, cmd, ev.ThreadTimeStamp) {
+		return
+	}
+
+	// Send the AckMsg and get the Timestamp back, so we can thread it later on...
+	timeStamp := c.svc.ChatService.PostMessageThread(ctx, ackMsg, cmd.Info().Channel, ev.ThreadTimeStamp)
+	// If the AckMessage needs to continue (no errors)...
+	if cont {
+		// Asynchronous CommandExecutor call
+		// which maps an EveBotCommand to a CommandHandler
+		c.bg.track(func() { c.exe.Execute(ctx, cmd, timeStamp) })
+	}
+}
 
+// authorize checks that the chat user is logged in and authorized to run the command
+// the user is notified (in the thread) when the command isn't authorized
+func (c SlackController) authorize(ctx context.Context, cmd commands.EvebotCommand, threadTS string) bool {
 	chatUser, err := c.svc.ChatService.GetUser(ctx, cmd.Info().User)
 	if err != nil {
-		c.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ev.ThreadTimeStamp, err)
-		return
+		c.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, threadTS, err)
+		return false
 	}
 
 	userEntry, err := c.svc.ReadUser(chatUser.FullyQualifiedName())
 	if err != nil {
 		if !goerror.Is(err, errors.ErrNotFound) {
-			c.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ev.ThreadTimeStamp, err)
+			c.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, threadTS, err)
 		}
 		c.svc.ChatService.PostPrivateMessage(ctx, c.svc.AuthCodeURL(chatUser.FullyQualifiedName()), cmd.Info().User)
-		_ = c.svc.ChatService.PostMessageThread(ctx, "You need to login. Please Check your Private DM from `evebot` for an auth link", cmd.Info().Channel, ev.ThreadTimeStamp)
-		return
+		_ = c.svc.ChatService.PostMessageThread(ctx, "You need to login. Please Check your Private DM from `evebot` for an auth link", cmd.Info().Channel, threadTS)
+		return false
 	}
 
 	// TODO: Add a "Request Access" process here when user is not authorized to perform action
@@ -183,25 +331,15 @@ func (c SlackController) handleSlackAppMentionEvent(ctx context.Context, ev *sla
 	// Doubly Bonus Points: Add ability for eve to assign a user to a role
 	// @evebot assign user@domain.tld to eve-deploy-prod role
 	if !c.svc.IsAuthorized(cmd, userEntry) {
-		_ = c.svc.ChatService.PostMessageThread(ctx, "You are not authorized to perform this action\nPlease message `@devops` with an access request if needed.", cmd.Info().Channel, ev.ThreadTimeStamp)
-		return
+		_ = c.svc.ChatService.PostMessageThread(ctx, "You are not authorized to perform this action\nPlease message `@devops` with an access request if needed.", cmd.Info().Channel, threadTS)
+		return false
 	}
 
 	// SlackMaintenanceEnabled is like a "feature flag"
 	// set to true, and we are in Maintenance Mode
 	if c.svc.Cfg.SlackMaintenanceEnabled && !userEntry.IsAdmin {
-		_ = c.svc.ChatService.PostMessageThread(ctx, ":construction: Sorry, but we are currently in maintenance mode!", cmd.Info().Channel, ev.ThreadTimeStamp)
-		return
-	}
-
-	// Hydrate the Acknowledgement Message and whether we should continue...
-	ackMsg, cont := cmd.AckMsg()
-	// Send the AckMsg and get the Timestamp back, so we can thread it later on...
-	timeStamp := c.svc.ChatService.PostMessageThread(ctx, ackMsg, cmd.Info().Channel, ev.ThreadTimeStamp)
-	// If the AckMessage needs to continue (no errors)...
-	if cont {
-		// Asynchronous CommandExecutor call
-		// which maps an EveBotCommand to a CommandHandler
-		go c.exe.Execute(context.TODO(), cmd, timeStamp)
+		_ = c.svc.ChatService.PostMessageThread(ctx, ":construction: Sorry, but we are currently in maintenance mode!", cmd.Info().Channel, threadTS)
+		return false
 	}
+	return true
 }
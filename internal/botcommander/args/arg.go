@@ -39,10 +39,28 @@ func ResolveArgumentKV(argKV []string) Arg {
 			return nil
 		}
 		return Force(b)
+	case RevealName:
+		b, err := strconv.ParseBool(strings.ToLower(argKV[1]))
+		if err != nil {
+			return nil
+		}
+		return Reveal(b)
 	case ServicesName:
 		return NewServicesArg(strings.Split(argKV[1], ","))
 	case DatabasesName:
 		return NewDatabasesArg(strings.Split(argKV[1], ","))
+	case StackingName:
+		return NewStackingArg(argKV[1])
+	case KeysName:
+		return NewKeysArg(strings.Split(argKV[1], ","))
+	case ServiceFilterName:
+		return NewServiceFilterArg(argKV[1])
+	case PageName:
+		return NewPageArg(argKV[1])
+	case TailName:
+		return NewTailArg(argKV[1])
+	case LogsSinceName:
+		return NewLogsSinceArg(argKV[1])
 	default:
 		return nil
 	}
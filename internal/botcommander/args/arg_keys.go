@@ -0,0 +1,48 @@
+package args
+
+import "strings"
+
+/*
+	ARGUMENT: Keys
+*/
+
+const (
+	// KeysName is the Keys argument name
+	KeysName = "keys"
+	// KeysDescription is the Keys argument description
+	KeysDescription = "comma separated list of metadata keys"
+)
+
+// Keys is the Keys argument type
+type Keys []string
+
+// Name is the name of the Keys argument
+func (k Keys) Name() string {
+	return KeysName
+}
+
+// Description is the description of the Keys argument
+func (k Keys) Description() string {
+	return KeysDescription
+}
+
+// Value is the value of the Keys argument
+func (k Keys) Value() interface{} {
+	return []string(k)
+}
+
+// DefaultKeysArg is the default Keys argument
+func DefaultKeysArg() Keys {
+	return Keys{}
+}
+
+// NewKeysArg is the instantiation method that creates a new Keys argument
+func NewKeysArg(input []string) Keys {
+	var keys = Keys{}
+	for _, v := range input {
+		if key := strings.TrimSpace(v); len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
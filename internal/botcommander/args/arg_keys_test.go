@@ -0,0 +1,39 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewKeysArg(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  interface{}
+	}{
+		{
+			name:  "happy path",
+			input: []string{"a", "b"},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "empty keys",
+			input: []string{"a", "", " b "},
+			want:  []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewKeysArg(tt.input).Value(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewKeysArg().Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveArgumentKV_Keys(t *testing.T) {
+	got := ResolveArgumentKV([]string{"keys", "a,b"})
+	if got == nil || got.Name() != KeysName || !reflect.DeepEqual(got.Value(), []string{"a", "b"}) {
+		t.Errorf("ResolveArgumentKV() = %v", got)
+	}
+}
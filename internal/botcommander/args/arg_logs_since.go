@@ -0,0 +1,46 @@
+package args
+
+import "time"
+
+/*
+	ARGUMENT: LogsSince
+*/
+
+const (
+	// LogsSinceName is the key/id for the logs since argument
+	LogsSinceName = "since"
+	// LogsSinceDescription is the description of the LogsSince argument
+	LogsSinceDescription = "only the log lines newer than the duration (i.e. 10m or 1h)"
+)
+
+// LogsSince is the LogsSince argument duration type
+type LogsSince time.Duration
+
+// Name is the name of the LogsSince argument
+func (a LogsSince) Name() string {
+	return LogsSinceName
+}
+
+// Value is the value of the LogsSince argument
+func (a LogsSince) Value() interface{} {
+	return time.Duration(a)
+}
+
+// Description is the description of the LogsSince argument
+func (a LogsSince) Description() string {
+	return LogsSinceDescription
+}
+
+// DefaultLogsSinceArg is the default LogsSince argument (0 doesn't limit the age of the log lines)
+func DefaultLogsSinceArg() LogsSince {
+	return 0
+}
+
+// NewLogsSinceArg is the instantiation method that creates a new LogsSince argument (nil when the input isn't a positive duration)
+func NewLogsSinceArg(input string) Arg {
+	d, err := time.ParseDuration(input)
+	if err != nil || d <= 0 {
+		return nil
+	}
+	return LogsSince(d)
+}
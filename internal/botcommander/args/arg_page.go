@@ -0,0 +1,46 @@
+package args
+
+import "strconv"
+
+/*
+	ARGUMENT: Page
+*/
+
+const (
+	// PageName is the key/id for the page argument
+	PageName = "page"
+	// PageDescription is the description of the Page argument
+	PageDescription = "the page of the results (the first page has the most recent results)"
+)
+
+// Page is the Page argument int type
+type Page int
+
+// Name is the name of the Page argument
+func (a Page) Name() string {
+	return PageName
+}
+
+// Value is the value of the Page argument
+func (a Page) Value() interface{} {
+	return int(a)
+}
+
+// Description is the description of the Page argument
+func (a Page) Description() string {
+	return PageDescription
+}
+
+// DefaultPageArg is the default Page argument
+func DefaultPageArg() Page {
+	return 1
+}
+
+// NewPageArg is the instantiation method that creates a new Page argument (nil when the input isn't a positive int)
+func NewPageArg(input string) Arg {
+	page, err := strconv.Atoi(input)
+	if err != nil || page < 1 {
+		return nil
+	}
+	return Page(page)
+}
@@ -0,0 +1,51 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPageArg(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{
+			name:  "happy path",
+			input: "2",
+			want:  2,
+		},
+		{
+			name:  "not a number",
+			input: "next",
+		},
+		{
+			name:  "zero",
+			input: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveArgumentKV([]string{PageName, tt.input})
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("ResolveArgumentKV() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Name() != PageName || !reflect.DeepEqual(got.Value(), tt.want) {
+				t.Errorf("ResolveArgumentKV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewServiceFilterArg(t *testing.T) {
+	if got := ResolveArgumentKV([]string{"SERVICE", "api"}); got == nil || got.Name() != ServiceFilterName || got.Value() != "api" {
+		t.Errorf("ResolveArgumentKV() = %v, want the api service", got)
+	}
+	if got := ResolveArgumentKV([]string{ServiceFilterName, ""}); got != nil {
+		t.Errorf("ResolveArgumentKV() = %v, want nil", got)
+	}
+}
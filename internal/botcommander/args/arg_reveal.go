@@ -0,0 +1,35 @@
+package args
+
+/*
+	ARGUMENT: Reveal
+*/
+
+const (
+	// RevealName is the key/id for the reveal argument
+	RevealName = "reveal"
+	// RevealDescription is the description of the Reveal argument
+	RevealDescription = "sends the unmasked (secret) values in a private message"
+)
+
+// Reveal is the Reveal argument bool type
+type Reveal bool
+
+// Name is the name of the Reveal argument
+func (a Reveal) Name() string {
+	return RevealName
+}
+
+// Value is the value of the Reveal argument
+func (a Reveal) Value() interface{} {
+	return bool(a)
+}
+
+// Description is the description of the Reveal argument
+func (a Reveal) Description() string {
+	return RevealDescription
+}
+
+// DefaultRevealArg is the default Reveal argument
+func DefaultRevealArg() Reveal {
+	return false
+}
@@ -0,0 +1,39 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReveal(t *testing.T) {
+	tests := []struct {
+		name  string
+		argKV []string
+		want  interface{}
+	}{
+		{
+			name:  "happy path - true reveal",
+			argKV: []string{"reveal", "true"},
+			want:  true,
+		},
+		{
+			name:  "happy path - false reveal",
+			argKV: []string{"REVEAL", "False"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveArgumentKV(tt.argKV)
+			if got == nil || got.Name() != RevealName || got.Description() != RevealDescription {
+				t.Fatalf("ResolveArgumentKV() = %v", got)
+			}
+			if !reflect.DeepEqual(got.Value(), tt.want) {
+				t.Errorf("Reveal.Value() = %v, want %v", got.Value(), tt.want)
+			}
+		})
+	}
+	if got := ResolveArgumentKV([]string{"reveal", "yes"}); got != nil {
+		t.Errorf("ResolveArgumentKV() = %v, want nil", got)
+	}
+}
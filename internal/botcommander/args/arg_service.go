@@ -0,0 +1,46 @@
+package args
+
+import "strings"
+
+/*
+	ARGUMENT: Service (filter)
+*/
+
+const (
+	// ServiceFilterName is the key/id for the service (filter) argument
+	ServiceFilterName = "service"
+	// ServiceFilterDescription is the description of the ServiceFilter argument
+	ServiceFilterDescription = "only the results of the service (or artifact)"
+)
+
+// ServiceFilter is the service (filter) argument string type
+type ServiceFilter string
+
+// Name is the name of the ServiceFilter argument
+func (a ServiceFilter) Name() string {
+	return ServiceFilterName
+}
+
+// Value is the value of the ServiceFilter argument
+func (a ServiceFilter) Value() interface{} {
+	return string(a)
+}
+
+// Description is the description of the ServiceFilter argument
+func (a ServiceFilter) Description() string {
+	return ServiceFilterDescription
+}
+
+// DefaultServiceFilterArg is the default ServiceFilter argument (no filter)
+func DefaultServiceFilterArg() ServiceFilter {
+	return ""
+}
+
+// NewServiceFilterArg is the instantiation method that creates a new ServiceFilter argument (nil when the input is empty)
+func NewServiceFilterArg(input string) Arg {
+	input = strings.TrimSpace(input)
+	if len(input) == 0 {
+		return nil
+	}
+	return ServiceFilter(input)
+}
@@ -0,0 +1,41 @@
+package args
+
+import "strconv"
+
+/*
+	ARGUMENT: Stacking
+*/
+
+const (
+	// StackingName is the key/id for the stacking (order) argument
+	StackingName = "stacking"
+	// StackingDescription is the description of the Stacking argument
+	StackingDescription = "the metadata stacking order (the higher stacking orders override the lower ones)"
+)
+
+// Stacking is the Stacking (order) argument int type
+type Stacking int
+
+// Name is the name of the Stacking argument
+func (a Stacking) Name() string {
+	return StackingName
+}
+
+// Value is the value of the Stacking argument
+func (a Stacking) Value() interface{} {
+	return int(a)
+}
+
+// Description is the description of the Stacking argument
+func (a Stacking) Description() string {
+	return StackingDescription
+}
+
+// NewStackingArg is the instantiation method that creates a new Stacking argument (nil when the input isn't a positive int)
+func NewStackingArg(input string) Arg {
+	order, err := strconv.Atoi(input)
+	if err != nil || order < 1 {
+		return nil
+	}
+	return Stacking(order)
+}
@@ -0,0 +1,42 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewStackingArg(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{
+			name:  "happy path",
+			input: "500",
+			want:  500,
+		},
+		{
+			name:  "not a number",
+			input: "high",
+		},
+		{
+			name:  "zero",
+			input: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveArgumentKV([]string{StackingName, tt.input})
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("ResolveArgumentKV() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Name() != StackingName || !reflect.DeepEqual(got.Value(), tt.want) {
+				t.Errorf("ResolveArgumentKV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package args
+
+import "strconv"
+
+/*
+	ARGUMENT: Tail
+*/
+
+const (
+	// TailName is the key/id for the tail argument
+	TailName = "tail"
+	// TailDescription is the description of the Tail argument
+	TailDescription = "the number of the most recent log lines of each pod"
+)
+
+// Tail is the Tail argument int type
+type Tail int
+
+// Name is the name of the Tail argument
+func (a Tail) Name() string {
+	return TailName
+}
+
+// Value is the value of the Tail argument
+func (a Tail) Value() interface{} {
+	return int(a)
+}
+
+// Description is the description of the Tail argument
+func (a Tail) Description() string {
+	return TailDescription
+}
+
+// DefaultTailArg is the default Tail argument
+func DefaultTailArg() Tail {
+	return 100
+}
+
+// NewTailArg is the instantiation method that creates a new Tail argument (nil when the input isn't a positive int)
+func NewTailArg(input string) Arg {
+	tail, err := strconv.Atoi(input)
+	if err != nil || tail < 1 {
+		return nil
+	}
+	return Tail(tail)
+}
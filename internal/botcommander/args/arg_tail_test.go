@@ -0,0 +1,29 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewTailArg(t *testing.T) {
+	if got := ResolveArgumentKV([]string{TailName, "50"}); got == nil || got.Name() != TailName || !reflect.DeepEqual(got.Value(), 50) {
+		t.Errorf("ResolveArgumentKV() = %v, want 50", got)
+	}
+	for _, invalid := range []string{"all", "0", "-10"} {
+		if got := ResolveArgumentKV([]string{TailName, invalid}); got != nil {
+			t.Errorf("ResolveArgumentKV(%s) = %v, want nil", invalid, got)
+		}
+	}
+}
+
+func TestNewLogsSinceArg(t *testing.T) {
+	if got := ResolveArgumentKV([]string{LogsSinceName, "10m"}); got == nil || got.Name() != LogsSinceName || !reflect.DeepEqual(got.Value(), 10*time.Minute) {
+		t.Errorf("ResolveArgumentKV() = %v, want 10m", got)
+	}
+	for _, invalid := range []string{"10", "yesterday", "-1h"} {
+		if got := ResolveArgumentKV([]string{LogsSinceName, invalid}); got != nil {
+			t.Errorf("ResolveArgumentKV(%s) = %v, want nil", invalid, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+)
+
+// resourceOpt is the options key for the requested resource (show/set/delete)
+const resourceOpt = "resource"
+
+const (
+	// SinceOpt is the options key of the since clause (a time.Duration)
+	SinceOpt = "since"
+	// DefaultSince is the duration when the since clause isn't in the input
+	DefaultSince = 7 * 24 * time.Hour
+)
+
+// the options keys of the source location (copy/diff ... from {{ namespace }} {{ environment }})
+// the target location uses the regular namespace/environment keys
+const (
+	SourceNamespaceOpt   = "sourceNamespace"
+	SourceEnvironmentOpt = "sourceEnvironment"
+)
+
+// the grammar clauses shared by the commands
+var (
+	// in {{ namespace }} {{ environment }} (filled in with the channel defaults, see ApplyChannelDefaults)
+	inNamespaceClause = grammar.Defaulted(grammar.Clause("in", grammar.Param(params.NamespaceName), grammar.Param(params.EnvironmentName)))
+	// for {{ service }}
+	forServiceClause = grammar.Clause("for", grammar.Param(params.ServiceName))
+	// from {{ namespace }} {{ environment }} (the source location)
+	fromNamespaceClause = grammar.Clause("from", paramAs(params.NamespaceName, SourceNamespaceOpt), paramAs(params.EnvironmentName, SourceEnvironmentOpt))
+	// to {{ namespace }} {{ environment }} (the target location)
+	toNamespaceClause = grammar.Clause("to", grammar.Param(params.NamespaceName), grammar.Param(params.EnvironmentName))
+	// since {{ duration }} (i.e. 7d, 12h or 30m)
+	sinceClause = grammar.Optional(grammar.Clause("since", grammar.ParamFunc("{{ duration }}", func(value string, opts grammar.Options) error {
+		d, err := ParseSince(value)
+		if err != nil {
+			return err
+		}
+		opts[SinceOpt] = d
+		return nil
+	}))).Or(SinceOpt, DefaultSince)
+	// across {{ environment }} (every namespace in the environment)
+	acrossEnvironmentClause = grammar.Clause("across", grammar.Param(params.EnvironmentName))
+	// the owner of the metadata (a service, a namespace or an environment):
+	// for {{ service }} in {{ namespace }} {{ environment }}
+	// in {{ namespace }} {{ environment }}
+	// across {{ environment }}
+	metadataScopeClause = grammar.OneOf(
+		grammar.AnyOrder(grammar.Optional(forServiceClause), inNamespaceClause),
+		acrossEnvironmentClause,
+	)
+	// {{ key=value }} (the values with spaces are quoted, key:=value is a typed value)
+	// stacking={{ order }} is the stacking order argument (not a metadata key)
+	metadataRemainder = grammar.Remainder("{{ key=value }}", func(keyvals []string, opts grammar.Options) error {
+		var values []string
+		for _, kv := range keyvals {
+			argKV := strings.SplitN(kv, "=", 2)
+			if len(argKV) != 2 || !strings.EqualFold(argKV[0], args.StackingName) {
+				values = append(values, kv)
+				continue
+			}
+			arg := args.ResolveArgumentKV(argKV)
+			if arg == nil {
+				return fmt.Errorf("invalid arg `%s`, the stacking order must be a positive number", kv)
+			}
+			opts[arg.Name()] = arg.Value()
+		}
+		metadata, err := hydrateMetadataMap(values)
+		if err != nil {
+			return err
+		}
+		opts[params.MetadataName] = metadata
+		return nil
+	})
+)
+
+// ParseSince parses the duration of a since clause, a time.Duration or a number of days (i.e. 7d)
+func ParseSince(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days := strings.TrimSuffix(strings.ToLower(value), "d"); days != strings.ToLower(value) {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration `%s`, use i.e. 7d, 12h or 30m", value)
+	}
+	return d, nil
+}
+
+// paramAs matches a single value labeled as {{ label }} and stores it with the options key
+func paramAs(label, key string) grammar.Rule {
+	return grammar.ParamFunc("{{ "+label+" }}", func(value string, opts grammar.Options) error {
+		opts[key] = value
+		return nil
+	})
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
+)
+
+type aliasCmd struct {
+	baseCommand
+}
+
+const (
+	// AliasCmdName is the ID/Key for the AliasCmd
+	AliasCmdName = "alias"
+
+	// AliasActionSet creates/replaces an alias
+	AliasActionSet = "set"
+	// AliasActionList lists the aliases
+	AliasActionList = "list"
+	// AliasActionDelete deletes an alias
+	AliasActionDelete = "delete"
+
+	// AliasScopeChannel is the keyword used to scope an alias to the channel (instead of the user)
+	AliasScopeChannel = "channel"
+
+	// AliasActionOpt is the options key for the requested alias action
+	AliasActionOpt = "action"
+	// AliasChannelOpt is the options key set when the alias is scoped to the channel
+	AliasChannelOpt = "channel"
+	// AliasCommandOpt is the options key for the alias command template
+	AliasCommandOpt = "command"
+)
+
+var (
+	aliasNameMatcher = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+	aliasCmdGrammar = grammar.New(AliasCmdName,
+		grammar.OneOf(
+			// alias set {{ name }} = {{ command template }}
+			// alias set channel {{ name }} = {{ command template }}
+			grammar.Seq(
+				grammar.Keyword(AliasActionSet).As(AliasActionOpt),
+				grammar.Optional(grammar.Flag(AliasChannelOpt, AliasScopeChannel)).Or(AliasChannelOpt, false),
+				grammar.Remainder("{{ name }} = {{ command template }}", splitAliasTemplate),
+			),
+			// alias list
+			grammar.Keyword(AliasActionList).As(AliasActionOpt),
+			// alias delete {{ name }}
+			// alias delete channel {{ name }}
+			grammar.Seq(
+				grammar.Keyword(AliasActionDelete).As(AliasActionOpt),
+				grammar.Optional(grammar.Flag(AliasChannelOpt, AliasScopeChannel)).Or(AliasChannelOpt, false),
+				grammar.ParamFunc("{{ name }}", func(name string, opts grammar.Options) error {
+					opts[params.AliasName] = name
+					return nil
+				}),
+			),
+		),
+	)
+
+	aliasCmdHelpSummary = help.Summary("The `alias` command is used to create shortcuts for commands you run often. " +
+		"Positional placeholders (`$1`, `$2`) are filled in order and named placeholders (`$env`) are filled with `env=value`")
+	aliasCmdHelpUsage   = aliasCmdGrammar.Usage()
+	aliasCmdHelpExample = help.Examples{
+		"alias set shipit = deploy current in $1 services=$svc dryrun=true",
+		"shipit una-int svc=api,billing",
+		"alias set channel showsvc = show services in current $env",
+		"showsvc env=una-int",
+		"alias list",
+		"alias delete shipit",
+	}
+)
+
+// NewAliasCommand creates a New AliasCmd that implements the EvebotCommand interface
+func NewAliasCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := aliasCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   AliasCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, AliasCmdName),
+		},
+		parameters: params.Params{params.DefaultAlias()},
+		opts:       make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// NewAliasErrorCommand creates an AliasCmd that only reports an alias expansion error back to the user
+func NewAliasErrorCommand(cmdFields []string, channel, user string, err error) EvebotCommand {
+	return aliasCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:        user,
+			Channel:     channel,
+			CommandName: AliasCmdName,
+		},
+		errs:   []error{err},
+		opts:   make(CommandOptions),
+		bounds: InputLengthBounds{Min: 0, Max: -1},
+	}}
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd aliasCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(aliasCmdHelpSummary.String()),
+		help.UsageOpt(aliasCmdHelpUsage.String()),
+		help.ExamplesOpt(aliasCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd aliasCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd aliasCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *aliasCmd) resolveDynamicOptions() {
+	cmd.parseInput(aliasCmdGrammar)
+	if len(cmd.errs) > 0 || cmd.opts[AliasActionOpt] == AliasActionList {
+		return
+	}
+
+	name := ExtractStringOpt(params.AliasName, cmd.opts)
+	if err := validateAliasName(name); err != nil {
+		cmd.errs = append(cmd.errs, err)
+		return
+	}
+	if cmd.opts[AliasActionOpt] != AliasActionSet {
+		return
+	}
+
+	template := tokenizer.Split(ExtractStringOpt(AliasCommandOpt, cmd.opts))
+	if len(template) == 0 {
+		cmd.errs = append(cmd.errs, fmt.Errorf("alias `%s` requires a command template", name))
+		return
+	}
+	if template[0] == AliasCmdName {
+		cmd.errs = append(cmd.errs, fmt.Errorf("alias `%s` can't expand to an `alias` command", name))
+	}
+}
+
+// splitAliasTemplate splits the `{{ name }} = {{ command template }}` fields (the `=` can be attached to the name or the template)
+// the template is joined back with the tokenizer, so that its quoted values are kept
+func splitAliasTemplate(fields []string, opts grammar.Options) error {
+	for i, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := fields[:i]
+		if len(parts[0]) > 0 {
+			name = append(name[:i:i], parts[0])
+		}
+		if len(name) != 1 {
+			return fmt.Errorf("invalid alias name: %v", name)
+		}
+		var template []string
+		if len(parts[1]) > 0 {
+			template = append(template, parts[1])
+		}
+		opts[params.AliasName] = name[0]
+		opts[AliasCommandOpt] = tokenizer.Join(append(template, fields[i+1:]...))
+		return nil
+	}
+	return fmt.Errorf("invalid alias set, expected `{{ name }} = {{ command template }}`: %v", fields)
+}
+
+// validateAliasName makes sure the alias name is valid and doesn't shadow a built-in command
+func validateAliasName(name string) error {
+	if !aliasNameMatcher.MatchString(name) {
+		return fmt.Errorf("invalid alias name: %s", name)
+	}
+	if NewFactory().Items()[name] != nil {
+		return fmt.Errorf("alias name `%s` is reserved for the `%s` command", name, name)
+	}
+	return nil
+}
+
+type expandedCmd struct {
+	EvebotCommand
+	expansion string
+}
+
+// NewExpandedCommand wraps a command that was resolved from an alias,
+// so that the acknowledgement message tells the user what the alias expanded to
+func NewExpandedCommand(cmd EvebotCommand, expansion []string) EvebotCommand {
+	return expandedCmd{
+		EvebotCommand: cmd,
+		expansion:     strings.Join(expansion, " "),
+	}
+}
+
+// AckMsg satisfies the EveBotCommand Interface and prefixes the expanded alias to the acknowledgement message
+func (cmd expandedCmd) AckMsg() (string, bool) {
+	msg, cont := cmd.EvebotCommand.AckMsg()
+	return fmt.Sprintf("_expanded to:_ `@evebot %s`\n%s", cmd.expansion, msg), cont
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
+)
+
+func Test_Alias_resolveDynamicOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CommandOptions
+		wantErr bool
+	}{
+		{
+			name:  "set user alias",
+			input: "alias set shipit = deploy current in $1 dryrun=true",
+			want: CommandOptions{
+				"action":  "set",
+				"alias":   "shipit",
+				"channel": false,
+				"command": "deploy current in $1 dryrun=true",
+			},
+		},
+		{
+			name:  "set channel alias without spaces around equals",
+			input: "alias set channel showsvc =show services in current $env",
+			want: CommandOptions{
+				"action":  "set",
+				"alias":   "showsvc",
+				"channel": true,
+				"command": "show services in current $env",
+			},
+		},
+		{
+			name:  "set alias with a quoted value",
+			input: `alias set freeze=set metadata in current $env msg="deploy freeze"`,
+			want: CommandOptions{
+				"action":  "set",
+				"alias":   "freeze",
+				"channel": false,
+				"command": `set metadata in current $env msg="deploy freeze"`,
+			},
+		},
+		{
+			name:  "list aliases",
+			input: "alias list",
+			want: CommandOptions{
+				"action": "list",
+			},
+		},
+		{
+			name:  "delete channel alias",
+			input: "alias delete channel showsvc",
+			want: CommandOptions{
+				"action":  "delete",
+				"alias":   "showsvc",
+				"channel": true,
+			},
+		},
+		{
+			name:    "set alias without template",
+			input:   "alias set shipit =",
+			wantErr: true,
+		},
+		{
+			name:    "set alias shadowing a built-in command",
+			input:   "alias set deploy = deploy current in int",
+			wantErr: true,
+		},
+		{
+			name:    "set alias to an alias command",
+			input:   "alias set oops = alias list",
+			wantErr: true,
+		},
+		{
+			name:    "invalid action",
+			input:   "alias rename shipit",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewAliasCommand(tokenizer.Split(tt.input), "channel", "user").(aliasCmd)
+			if (len(cmd.errs) > 0) != tt.wantErr {
+				t.Fatalf("errs = %v, wantErr %v", cmd.errs, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(cmd.Options(), tt.want) {
+				t.Errorf("got = %v\nwant %v", cmd.Options(), tt.want)
+			}
+		})
+	}
+}
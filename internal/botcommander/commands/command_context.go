@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+)
+
+type contextCmd struct {
+	baseCommand
+}
+
+const (
+	// ContextCmdName is the ID/Key for the ContextCmd
+	ContextCmdName = "context"
+
+	// ContextActionSet sets the channel defaults
+	ContextActionSet = "set"
+	// ContextActionShow shows the channel defaults
+	ContextActionShow = "show"
+	// ContextActionClear removes the channel defaults
+	ContextActionClear = "clear"
+
+	// ContextActionOpt is the options key for the requested context action
+	ContextActionOpt = "action"
+)
+
+var (
+	contextCmdGrammar = grammar.New(ContextCmdName,
+		grammar.OneOf(
+			// context set namespace={{ namespace }} environment={{ environment }}
+			grammar.Seq(
+				grammar.Keyword(ContextActionSet).As(ContextActionOpt),
+				grammar.Remainder("namespace={{ namespace }} environment={{ environment }}", resolveContextArgs),
+			),
+			// context show
+			grammar.Keyword(ContextActionShow).As(ContextActionOpt),
+			// context clear
+			grammar.Keyword(ContextActionClear).As(ContextActionOpt),
+		),
+	)
+	contextCmdHelpSummary = help.Summary("The `context` command is used to set the default *namespace* and *environment* for a channel. " +
+		"Commands in the channel that leave out `in {{ namespace }} {{ environment }}` use the defaults")
+	contextCmdHelpUsage   = contextCmdGrammar.Usage()
+	contextCmdHelpExample = help.Examples{
+		"context set namespace=current environment=int",
+		"context set environment=qa",
+		"context show",
+		"deploy services=api",
+		"show services",
+		"context clear",
+	}
+)
+
+// NewContextCommand creates a New ContextCmd that implements the EvebotCommand interface
+func NewContextCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := contextCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   ContextCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, ContextCmdName),
+		},
+		parameters: params.Params{params.DefaultNamespace(), params.DefaultEnvironment()},
+		opts:       make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd contextCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(contextCmdHelpSummary.String()),
+		help.UsageOpt(contextCmdHelpUsage.String()),
+		help.ArgsOpt(cmd.parameters.String()),
+		help.ExamplesOpt(contextCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd contextCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd contextCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *contextCmd) resolveDynamicOptions() {
+	cmd.parseInput(contextCmdGrammar)
+}
+
+// resolveContextArgs resolves the `namespace={{ namespace }} environment={{ environment }}` args
+func resolveContextArgs(kvs []string, opts grammar.Options) error {
+	for _, s := range kvs {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || len(kv[1]) == 0 {
+			return fmt.Errorf("invalid context arg: %v", s)
+		}
+		switch strings.ToLower(kv[0]) {
+		case params.NamespaceName:
+			opts[params.NamespaceName] = kv[1]
+		case params.EnvironmentName:
+			opts[params.EnvironmentName] = kv[1]
+		default:
+			return fmt.Errorf("invalid context arg: %v", s)
+		}
+	}
+	return nil
+}
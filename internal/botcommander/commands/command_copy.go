@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+)
+
+type copyCmd struct {
+	baseCommand
+}
+
+const (
+	// CopyCmdName id/key
+	CopyCmdName = "copy"
+)
+
+var (
+	// copy metadata for {{ service }} from {{ namespace }} {{ environment }} to {{ namespace }} {{ environment }} [keys=a,b]
+	copyCmdGrammar = grammar.New(CopyCmdName,
+		grammar.Keyword(resources.MetadataName).As(resourceOpt),
+		grammar.AnyOrder(forServiceClause, fromNamespaceClause, toNamespaceClause),
+		grammar.Args(args.DefaultKeysArg()),
+	)
+	copyCmdHelpSummary = help.Summary("The `copy` command is used to copy the service metadata to another namespace")
+	copyCmdHelpUsage   = copyCmdGrammar.Usage()
+	copyCmdHelpExample = help.Examples{
+		"copy metadata for api from current int to current qa",
+		"copy metadata for api from current int to current qa keys=db_url,log_level",
+	}
+)
+
+// NewCopyCommand creates a New CopyCmd that implements the EvebotCommand interface
+func NewCopyCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := copyCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   CopyCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, CopyCmdName),
+		},
+		arguments: args.Args{args.DefaultKeysArg()},
+		opts:      make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd copyCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(copyCmdHelpSummary.String()),
+		help.UsageOpt(copyCmdHelpUsage.String()),
+		help.ArgsOpt(cmd.arguments.String()),
+		help.ExamplesOpt(copyCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd copyCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd copyCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *copyCmd) resolveDynamicOptions() {
+	cmd.parseInput(copyCmdGrammar)
+}
@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"fmt"
-
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
@@ -18,13 +17,32 @@ const (
 )
 
 var (
+	deleteCmdGrammar = grammar.New(DeleteCmdName,
+		grammar.OneOf(
+			// delete metadata for {{ service }} in {{ namespace }} {{ environment }} {{ key }}
+			// delete metadata in {{ namespace }} {{ environment }} {{ key }}
+			// delete metadata across {{ environment }} {{ key }}
+			grammar.Seq(
+				grammar.Keyword(resources.MetadataName).As(resourceOpt),
+				metadataScopeClause,
+				grammar.Remainder("{{ key }}", func(keys []string, opts grammar.Options) error {
+					opts[params.MetadataName] = keys
+					return nil
+				}),
+			),
+			// delete version for {{ service }} in {{ namespace }} {{ environment }}
+			grammar.Seq(
+				grammar.Keyword(resources.VersionName).As(resourceOpt),
+				grammar.AnyOrder(forServiceClause, inNamespaceClause),
+			),
+		),
+	)
 	deleteCmdHelpSummary = help.Summary("The `delete` command is used to delete resource values (metadata, pinned versions)")
-	deleteCmdHelpUsage   = help.Usage{
-		"delete {{ resources }} for {{ service }} in {{ namespace }} {{ environment }}",
-	}
+	deleteCmdHelpUsage   = deleteCmdGrammar.Usage()
 	deleteCmdHelpExample = help.Examples{
 		"delete metadata for api in current int key",
 		"delete metadata for api in current int key key2 key3 keyN",
+		"delete metadata across int key",
 		"delete version for api in current int",
 	}
 )
@@ -39,8 +57,7 @@ func NewDeleteCommand(cmdFields []string, channel, user string) EvebotCommand {
 			CommandName:   DeleteCmdName,
 			IsHelpRequest: isHelpCmd(cmdFields, DeleteCmdName),
 		},
-		opts:   make(CommandOptions),
-		bounds: InputLengthBounds{Min: 7, Max: -1},
+		opts: make(CommandOptions),
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -66,38 +83,5 @@ func (cmd deleteCmd) Info() ChatInfo {
 }
 
 func (cmd *deleteCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	cmd.initializeResource()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	switch cmd.opts["resource"] {
-	case resources.MetadataName:
-		// delete metadata for unaneta in current una-int key key2 key3
-		// delete metadata for {{ service }} in {{ namespace }} {{ environment }} key key2 key3
-		if !cmd.ValidInputLength() {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid delete metadata: %v", cmd.input))
-			return
-		}
-
-		cmd.opts[params.ServiceName] = cmd.input[3]
-		cmd.opts[params.NamespaceName] = cmd.input[5]
-		cmd.opts[params.EnvironmentName] = cmd.input[6]
-		cmd.opts[params.MetadataName] = cmd.input[7:]
-		return
-	case resources.VersionName:
-		// delete version for unaneta in current una-int
-		if !cmd.ValidInputLength() {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid delete version: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.ServiceName] = cmd.input[3]
-		cmd.opts[params.NamespaceName] = cmd.input[5]
-		cmd.opts[params.EnvironmentName] = cmd.input[6]
-		return
-	default:
-		cmd.errs = append(cmd.errs, fmt.Errorf("invalid resource supplied: %v", cmd.opts["resource"]))
-		return
-	}
+	cmd.parseInput(deleteCmdGrammar)
 }
@@ -1,10 +1,8 @@
 package commands
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 )
@@ -19,13 +17,16 @@ const (
 )
 
 var (
+	// deploy {{ namespace }} in {{ environment }} {{ arg=value }}
+	deployCmdGrammar = grammar.New(DeployCmdName,
+		grammar.Defaulted(grammar.Seq(
+			grammar.Param(params.NamespaceName),
+			grammar.Clause("in", grammar.Param(params.EnvironmentName)),
+		)),
+		grammar.Args(args.DefaultDryrunArg(), args.DefaultForceArg(), args.DefaultServicesArg()),
+	)
 	deployCmdHelpSummary = help.Summary("The `deploy` command is used to deploy services to a specific *namespace* and *environment*")
-	deployCmdHelpUsage   = help.Usage{
-		"deploy {{ namespace }} in {{ environment }}",
-		"deploy {{ namespace }} in {{ environment }} services={{ service_name:service_version }}",
-		"deploy {{ namespace }} in {{ environment }} services={{ service_name:service_version,service_name:service_version }} dryrun={{ true }}",
-		"deploy {{ namespace }} in {{ environment }} services={{ service_name:service_version,service_name:service_version }} dryrun={{ true }} force={{ true }}",
-	}
+	deployCmdHelpUsage   = deployCmdGrammar.Usage()
 	deployCmdHelpExample = help.Examples{
 		"deploy current in int",
 		"deploy current in int services=api dryrun=true",
@@ -47,7 +48,6 @@ func NewDeployCommand(cmdFields []string, channel, user string) EvebotCommand {
 		arguments:  args.Args{args.DefaultDryrunArg(), args.DefaultForceArg(), args.DefaultServicesArg()},
 		parameters: params.Params{params.DefaultNamespace(), params.DefaultEnvironment()},
 		opts:       make(CommandOptions),
-		bounds:     InputLengthBounds{Min: 4, Max: 7},
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -74,22 +74,5 @@ func (cmd deployCmd) Info() ChatInfo {
 }
 
 func (cmd *deployCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	cmd.opts[params.NamespaceName] = cmd.input[1]
-	cmd.opts[params.EnvironmentName] = cmd.input[3]
-
-	for _, s := range cmd.input[3:] {
-		if strings.Contains(s, "=") {
-			argKV := strings.Split(s, "=")
-			if suppliedArg := args.ResolveArgumentKV(argKV); suppliedArg != nil {
-				cmd.opts[suppliedArg.Name()] = suppliedArg.Value()
-			} else {
-				cmd.errs = append(cmd.errs, fmt.Errorf("invalid additional arg: %v", argKV))
-			}
-		}
-	}
+	cmd.parseInput(deployCmdGrammar)
 }
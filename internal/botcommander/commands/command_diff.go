@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+)
+
+type diffCmd struct {
+	baseCommand
+}
+
+const (
+	// DiffCmdName id/key
+	DiffCmdName = "diff"
+)
+
+var (
+	// diff metadata for {{ service }} from {{ namespace }} {{ environment }} to {{ namespace }} {{ environment }}
+	diffCmdGrammar = grammar.New(DiffCmdName,
+		grammar.Keyword(resources.MetadataName).As(resourceOpt),
+		grammar.AnyOrder(forServiceClause, fromNamespaceClause, toNamespaceClause),
+	)
+	diffCmdHelpSummary = help.Summary("The `diff` command is used to compare the service metadata of two namespaces key by key")
+	diffCmdHelpUsage   = diffCmdGrammar.Usage()
+	diffCmdHelpExample = help.Examples{
+		"diff metadata for api from current int to current qa",
+	}
+)
+
+// NewDiffCommand creates a New DiffCmd that implements the EvebotCommand interface
+func NewDiffCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := diffCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   DiffCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, DiffCmdName),
+		},
+		opts: make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd diffCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(diffCmdHelpSummary.String()),
+		help.UsageOpt(diffCmdHelpUsage.String()),
+		help.ExamplesOpt(diffCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd diffCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd diffCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *diffCmd) resolveDynamicOptions() {
+	cmd.parseInput(diffCmdGrammar)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+)
+
+type exportCmd struct {
+	baseCommand
+}
+
+const (
+	// ExportCmdName id/key
+	ExportCmdName = "export"
+	// ExportFormatOpt is the options key of the export file format (json/yaml)
+	ExportFormatOpt = "format"
+)
+
+var (
+	// export metadata for {{ service }} in {{ namespace }} {{ environment }} [as json|yaml]
+	exportCmdGrammar = grammar.New(ExportCmdName,
+		grammar.Keyword(resources.MetadataName).As(resourceOpt),
+		grammar.AnyOrder(
+			forServiceClause,
+			inNamespaceClause,
+			grammar.Optional(grammar.Clause("as", grammar.OneOf(
+				grammar.Keyword(metadata.JSONFormat).As(ExportFormatOpt),
+				grammar.Keyword(metadata.YAMLFormat).As(ExportFormatOpt),
+			))).Or(ExportFormatOpt, metadata.JSONFormat),
+		),
+	)
+	exportCmdHelpSummary = help.Summary("The `export` command is used to export the service metadata as a file (json or yaml)")
+	exportCmdHelpUsage   = exportCmdGrammar.Usage()
+	exportCmdHelpExample = help.Examples{
+		"export metadata for api in current int",
+		"export metadata for api in current int as yaml",
+	}
+)
+
+// NewExportCommand creates a New ExportCmd that implements the EvebotCommand interface
+func NewExportCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := exportCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   ExportCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, ExportCmdName),
+		},
+		opts: make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd exportCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(exportCmdHelpSummary.String()),
+		help.UsageOpt(exportCmdHelpUsage.String()),
+		help.ExamplesOpt(exportCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd exportCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd exportCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *exportCmd) resolveDynamicOptions() {
+	cmd.parseInput(exportCmdGrammar)
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
+)
+
+// FileOpt is the options key of the file shared with the command (chatmodels.File)
+const FileOpt = "file"
+
+// fileCmd is a command with a shared file (i.e. a metadata file upload)
+type fileCmd struct {
+	EvebotCommand
+	file chatmodels.File
+}
+
+// NewFileCommand attaches the shared file to the command options
+func NewFileCommand(cmd EvebotCommand, file chatmodels.File) EvebotCommand {
+	return fileCmd{
+		EvebotCommand: cmd,
+		file:          file,
+	}
+}
+
+// Options satisfies the EveBotCommand Interface and adds the file to the command options
+func (cmd fileCmd) Options() CommandOptions {
+	opts := make(CommandOptions)
+	for k, v := range cmd.EvebotCommand.Options() {
+		opts[k] = v
+	}
+	opts[FileOpt] = cmd.file
+	return opts
+}
+
+// IsMetadataUpload checks if the command sets the metadata from a file
+// i.e. `set metadata for {{ service }} in {{ namespace }} {{ environment }}` without any key=value
+func IsMetadataUpload(cmd EvebotCommand) bool {
+	if cmd.Info().CommandName != SetCmdName || cmd.Info().IsHelpRequest {
+		return false
+	}
+	if cmd.Options()[resourceOpt] != resources.MetadataName {
+		return false
+	}
+	_, ok := cmd.Options()[params.MetadataName]
+	return !ok
+}
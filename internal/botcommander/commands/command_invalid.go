@@ -10,11 +10,12 @@ import (
 
 type invalidCmd struct {
 	baseCommand
+	suggestion string
 }
 
 // NewInvalidCommand creates a New InvalidCmd that implements the EvebotCommand interface
 func NewInvalidCommand(cmdFields []string, channel, user string) EvebotCommand {
-	cmd := invalidCmd{baseCommand{
+	cmd := invalidCmd{baseCommand: baseCommand{
 		input: cmdFields,
 		info: ChatInfo{
 			User:        user,
@@ -29,9 +30,20 @@ func NewInvalidCommand(cmdFields []string, channel, user string) EvebotCommand {
 	return cmd
 }
 
+// NewUnknownCommand creates an InvalidCmd that suggests the closest known command
+// i.e. unknown command `deplyo` — did you mean `deploy`?
+func NewUnknownCommand(cmdFields []string, channel, user, suggestion string) EvebotCommand {
+	cmd := NewInvalidCommand(cmdFields, channel, user).(invalidCmd)
+	cmd.suggestion = suggestion
+	return cmd
+}
+
 // AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
 func (cmd invalidCmd) AckMsg() (string, bool) {
 	summary := help.Summary(fmt.Sprintf("I don't know how to execute the `%s` command.\n\nTry running: ```@evebot help```\n", cmd.input)).String()
+	if len(cmd.suggestion) > 0 {
+		summary = help.Summary(fmt.Sprintf("unknown command `%s` — did you mean `%s`?\n\nTry running: ```@evebot %s help```\n", cmd.input[0], cmd.suggestion, cmd.suggestion)).String()
+	}
 	return cmd.BaseAckMsg(help.New(
 		help.HeaderOpt(summary),
 		help.CommandsOpt(NewFactory().NonHelpCmds()),
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+)
+
+type logsCmd struct {
+	baseCommand
+}
+
+const (
+	// LogsCmdName id/key
+	LogsCmdName = "logs"
+)
+
+var (
+	// logs {{ service }} in {{ namespace }} {{ environment }} [tail=100] [since=10m]
+	logsCmdGrammar = grammar.New(LogsCmdName,
+		grammar.Param(params.ServiceName),
+		inNamespaceClause,
+		grammar.Args(args.DefaultTailArg(), args.DefaultLogsSinceArg()),
+	)
+	logsCmdHelpSummary = help.Summary("The `logs` command is used to get the logs of the pods of a service (uploaded as a file, the secrets are masked)")
+	logsCmdHelpUsage   = logsCmdGrammar.Usage()
+	logsCmdHelpExample = help.Examples{
+		"logs api in current int",
+		"logs billing in current int tail=500 since=10m",
+	}
+)
+
+// NewLogsCommand creates a New LogsCmd that implements the EvebotCommand interface
+func NewLogsCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := logsCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   LogsCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, LogsCmdName),
+		},
+		arguments: args.Args{args.DefaultTailArg(), args.DefaultLogsSinceArg()},
+		opts:      make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd logsCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(logsCmdHelpSummary.String()),
+		help.UsageOpt(logsCmdHelpUsage.String()),
+		help.ArgsOpt(cmd.arguments.String()),
+		help.ExamplesOpt(logsCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd logsCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd logsCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *logsCmd) resolveDynamicOptions() {
+	cmd.parseInput(logsCmdGrammar)
+}
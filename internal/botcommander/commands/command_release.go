@@ -1,9 +1,9 @@
 package commands
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 )
@@ -15,20 +15,42 @@ type releaseCmd struct {
 const (
 	// ReleaseCmdName is the ID/Key for the ReleaseCmd
 	ReleaseCmdName = "release"
+
+	// releaseCommandTypeOpt is the options key for the release type (artifact or namespace)
+	releaseCommandTypeOpt = "commandType"
 )
 
 var (
-	releaseNamespaceInputLengthBounds = InputLengthBounds{Min: 6, Max: 8}
-	releaseCmdHelpSummary = help.Summary("The `release` command is used to release artifacts or namespaces from/to feeds")
-	releaseCmdHelpUsage   = help.Usage{
-		// Artifact
-		"release artifact {{ artifact }}:{{ optional_version }} from {{ required_feed }}",
-		"release artifact {{ artifact }}:{{ optional_version }} from {{ required_feed }} to {{ optional_feed }}\n",
+	releaseCmdGrammar = grammar.New(ReleaseCmdName,
+		grammar.OneOf(
+			// release artifact {{ artifact }}:{{ optional_version }} from {{ required_feed }} to {{ optional_feed }}
+			grammar.Seq(
+				grammar.Keyword("artifact").As(releaseCommandTypeOpt),
+				grammar.ParamFunc("{{ artifact }}:{{ optional_version }}", resolveReleaseArtifact),
+				releaseFromFeedClause,
+				releaseToFeedClause,
+			),
+			// release namespace {{ namespace }} {{ environment }} from {{ required_feed }} to {{ optional_feed }}
+			grammar.Seq(
+				grammar.Keyword("namespace").As(releaseCommandTypeOpt),
+				grammar.Param(params.NamespaceName),
+				grammar.Param(params.EnvironmentName),
+				releaseFromFeedClause,
+				releaseToFeedClause,
+			),
+		),
+	)
+	releaseFromFeedClause = grammar.Clause("from", grammar.ParamFunc("{{ required_feed }}", func(feed string, opts grammar.Options) error {
+		opts[params.FromFeedName] = feed
+		return nil
+	}))
+	releaseToFeedClause = grammar.Optional(grammar.Clause("to", grammar.ParamFunc("{{ optional_feed }}", func(feed string, opts grammar.Options) error {
+		opts[params.ToFeedName] = feed
+		return nil
+	}))).Or(params.ToFeedName, "")
 
-		// Namespace
-		"release namespace {{ namespace }} {{ environment }} from {{ required_feed }}",
-		"release namespace {{ namespace }} {{ environment }} from {{ required_feed }} to {{ optional_feed }}",
-	}
+	releaseCmdHelpSummary = help.Summary("The `release` command is used to release artifacts or namespaces from/to feeds")
+	releaseCmdHelpUsage   = releaseCmdGrammar.Usage()
 	releaseCmdHelpExample = help.Examples{
 		// Artifact
 		"release artifact api from int",
@@ -52,8 +74,7 @@ func NewReleaseCommand(cmdFields []string, channel, user string) EvebotCommand {
 			CommandName:   ReleaseCmdName,
 			IsHelpRequest: isHelpCmd(cmdFields, ReleaseCmdName),
 		},
-		opts:   make(CommandOptions),
-		bounds: InputLengthBounds{Min: 5, Max: 7},
+		opts: make(CommandOptions),
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -79,66 +100,18 @@ func (cmd releaseCmd) Info() ChatInfo {
 }
 
 func (cmd *releaseCmd) resolveDynamicOptions() {
-
-	// Since we combine two ways to handle the same command, we need a little bit of a "hack" to handle
-	// the two different lengths
-	if len(cmd.input) > 1 && cmd.input[1] == "namespace" {
-		cmd.bounds = releaseNamespaceInputLengthBounds
-	}
-
-	cmd.verifyInput()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	var toBounds int
-
-	switch cmd.input[1] {
-		case "artifact":
-			cmd.opts["commandType"] = "artifact"
-
-			cmd.resolveDynamicOptionsForArtifact()
-			toBounds = 6
-		case "namespace":
-			cmd.opts["commandType"] = "namespace"
-
-			cmd.resolveDynamicOptionsForNamespace()
-			toBounds = 7
-		default:
-			cmd.errs = append(cmd.errs, fmt.Errorf(
-					"unable to determine release type ( artifact or namespace ). " +
-							"This has recently changed, please double check with the following examples:\n\n%s",
-							releaseCmdHelpUsage,
-						),
-					)
-	}
-
-	switch len(cmd.input) {
-	case cmd.bounds.Min:
-		cmd.opts[params.ToFeedName] = ""
-		return
-	case cmd.bounds.Max:
-		cmd.opts[params.ToFeedName] = cmd.input[toBounds]
-		return
-	}
+	cmd.parseInput(releaseCmdGrammar)
 }
 
-func (cmd *releaseCmd) resolveDynamicOptionsForArtifact() {
-	if strings.Contains(cmd.input[2], ":") {
-		artifactKV := strings.Split(cmd.input[2], ":")
-		cmd.opts[params.ArtifactName] = artifactKV[0]
-		cmd.opts[params.ArtifactVersionName] = artifactKV[1]
+// resolveReleaseArtifact resolves the `{{ artifact }}:{{ optional_version }}` param
+func resolveReleaseArtifact(artifact string, opts grammar.Options) error {
+	if strings.Contains(artifact, ":") {
+		artifactKV := strings.Split(artifact, ":")
+		opts[params.ArtifactName] = artifactKV[0]
+		opts[params.ArtifactVersionName] = artifactKV[1]
 	} else {
-		cmd.opts[params.ArtifactName] = cmd.input[2]
-		cmd.opts[params.ArtifactVersionName] = ""
+		opts[params.ArtifactName] = artifact
+		opts[params.ArtifactVersionName] = ""
 	}
-
-	cmd.opts[params.FromFeedName] = cmd.input[4]
+	return nil
 }
-
-func (cmd *releaseCmd) resolveDynamicOptionsForNamespace() {
-	cmd.opts[params.NamespaceName] = cmd.input[2]
-	cmd.opts[params.EnvironmentName] = cmd.input[3]
-
-	cmd.opts[params.FromFeedName] = cmd.input[5]
-}
\ No newline at end of file
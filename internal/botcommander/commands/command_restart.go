@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 )
@@ -14,8 +15,10 @@ const (
 )
 
 var (
+	// restart {{ service }} in {{ namespace }} {{ environment }}
+	restartCmdGrammar     = grammar.New(RestartCmdName, grammar.Param(params.ServiceName), inNamespaceClause)
 	restartCmdHelpSummary = help.Summary("The `restart` command is used to restart a service in a namespace")
-	restartCmdHelpUsage   = help.Usage{"restart {{ service }} in {{ namespace }} {{ environment }}"}
+	restartCmdHelpUsage   = restartCmdGrammar.Usage()
 	restartCmdHelpExample = help.Examples{"restart api in current int"}
 )
 
@@ -29,8 +32,7 @@ func NewRestartCommand(cmdFields []string, channel, user string) EvebotCommand {
 			CommandName:   RestartCmdName,
 			IsHelpRequest: isHelpCmd(cmdFields, RestartCmdName),
 		},
-		opts:   make(CommandOptions),
-		bounds: InputLengthBounds{Min: 5, Max: 5},
+		opts: make(CommandOptions),
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -56,12 +58,5 @@ func (cmd restartCmd) Info() ChatInfo {
 }
 
 func (cmd *restartCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	cmd.opts[params.ServiceName] = cmd.input[1]
-	cmd.opts[params.NamespaceName] = cmd.input[3]
-	cmd.opts[params.EnvironmentName] = cmd.input[4]
+	cmd.parseInput(restartCmdGrammar)
 }
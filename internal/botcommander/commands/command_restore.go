@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+)
+
+type restoreCmd struct {
+	baseCommand
+}
+
+const (
+	// RestoreCmdName id/key
+	RestoreCmdName = "restore"
+	// RevisionOpt is the options key of the metadata revision (int)
+	RevisionOpt = "revision"
+)
+
+var (
+	// restore metadata for {{ service }} in {{ namespace }} {{ environment }} to {{ revision }}
+	// restore metadata in {{ namespace }} {{ environment }} to {{ revision }}
+	// restore metadata across {{ environment }} to {{ revision }}
+	restoreCmdGrammar = grammar.New(RestoreCmdName,
+		grammar.Keyword(resources.MetadataName).As(resourceOpt),
+		grammar.AnyOrder(
+			metadataScopeClause,
+			grammar.Clause("to", grammar.ParamFunc("{{ revision }}", func(value string, opts grammar.Options) error {
+				revision, err := strconv.Atoi(value)
+				if err != nil || revision < 1 {
+					return fmt.Errorf("invalid revision `%s`, expected a revision number (see `show metadata history`)", value)
+				}
+				opts[RevisionOpt] = revision
+				return nil
+			})),
+		),
+	)
+	restoreCmdHelpSummary = help.Summary("The `restore` command is used to restore the service, namespace or environment metadata to a previous revision")
+	restoreCmdHelpUsage   = restoreCmdGrammar.Usage()
+	restoreCmdHelpExample = help.Examples{
+		"show metadata history for api in current int",
+		"restore metadata for api in current int to 3",
+		"restore metadata across int to 2",
+	}
+)
+
+// NewRestoreCommand creates a New RestoreCmd that implements the EvebotCommand interface
+func NewRestoreCommand(cmdFields []string, channel, user string) EvebotCommand {
+	cmd := restoreCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   RestoreCmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, RestoreCmdName),
+		},
+		opts: make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions()
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd restoreCmd) AckMsg() (string, bool) {
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(restoreCmdHelpSummary.String()),
+		help.UsageOpt(restoreCmdHelpUsage.String()),
+		help.ExamplesOpt(restoreCmdHelpExample.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd restoreCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd restoreCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *restoreCmd) resolveDynamicOptions() {
+	cmd.parseInput(restoreCmdGrammar)
+}
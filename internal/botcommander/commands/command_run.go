@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 )
@@ -18,13 +19,22 @@ var (
 		"The `run` command is used to run a job in a namespace",
 	)
 
-	runCmdHelpUsage = help.Usage{
-		"run {{ job:version }} in {{ namespace }} {{ environment }} {{key=value}}",
-	}
+	// run {{ job:version }} in {{ namespace }} {{ environment }} {{ key=value }}
+	runCmdGrammar = grammar.New(RunCmdName,
+		grammar.ParamFunc("{{ job:version }}", func(job string, opts grammar.Options) error {
+			opts[params.JobName] = job
+			return nil
+		}),
+		inNamespaceClause,
+		grammar.Optional(metadataRemainder),
+	)
+
+	runCmdHelpUsage = runCmdGrammar.Usage()
 
 	runCmdHelpExample = help.Examples{
 		"run migration in current int key=value key2=value2 keyN=valN",
 		"run cool-job:1.2 in current int key=value key2=value2 keyN=valN",
+		"run migration in current int dryrun:=true batch:=500 reason='two words'",
 	}
 )
 
@@ -40,7 +50,6 @@ func NewRunCommand(cmdFields []string, channel, user string) EvebotCommand {
 		},
 		parameters: params.Params{params.DefaultJob(), params.DefaultNamespace(), params.DefaultEnvironment()},
 		opts:       make(CommandOptions),
-		bounds:     InputLengthBounds{Min: 5, Max: -1},
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -66,15 +75,5 @@ func (cmd runCmd) Info() ChatInfo {
 }
 
 func (cmd *runCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	// run {{ job }} in {{ namespace }} {{ environment }}
-	// run create-customer in current una-int name=username domain=something.something.somewhere
-	cmd.opts[params.JobName] = cmd.input[1]
-	cmd.opts[params.NamespaceName] = cmd.input[3]
-	cmd.opts[params.EnvironmentName] = cmd.input[4]
-	cmd.opts[params.MetadataName] = hydrateMetadataMap(cmd.input[5:])
+	cmd.parseInput(runCmdGrammar)
 }
@@ -1,8 +1,7 @@
 package commands
 
 import (
-	"fmt"
-
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
@@ -18,14 +17,37 @@ const (
 )
 
 var (
-	setCmdHelpSummary = help.Summary("The `set` command is used to set resource values (metadata and version)")
-	setCmdHelpUsage   = help.Usage{
-		"set {{ resources }} for {{ service }} in {{ namespace }} {{ environment }} {{key=value}}",
-		"set {{ resources }} in {{ namespace }} {{ environment }} to {{value}}",
-	}
+	setCmdGrammar = grammar.New(SetCmdName,
+		grammar.OneOf(
+			// set metadata for {{ service }} in {{ namespace }} {{ environment }} {{ key=value }} [stacking=500]
+			// set metadata for {{ service }} in {{ namespace }} {{ environment }} (with an attached metadata file)
+			// set metadata in {{ namespace }} {{ environment }} {{ key=value }}
+			// set metadata across {{ environment }} {{ key=value }}
+			grammar.Seq(
+				grammar.Keyword(resources.MetadataName).As(resourceOpt),
+				metadataScopeClause,
+				grammar.Optional(metadataRemainder),
+			),
+			// set version for {{ service }} in {{ namespace }} {{ environment }} to {{ version }}
+			// set version in {{ namespace }} {{ environment }} to {{ version }}
+			grammar.Seq(
+				grammar.Keyword(resources.VersionName).As(resourceOpt),
+				grammar.AnyOrder(
+					grammar.Optional(forServiceClause),
+					inNamespaceClause,
+					grammar.Clause("to", grammar.Param(params.VersionName)),
+				),
+			),
+		),
+	)
+	setCmdHelpSummary = help.Summary("The `set` command is used to set resource values (metadata and version), the services inherit the namespace and environment metadata through the eve stacking (service 400, namespace 300, environment 200) unless a higher stacking order overrides it")
+	setCmdHelpUsage   = setCmdGrammar.Usage()
 	setCmdHelpExample = help.Examples{
 		"set metadata for api in current int key=value",
 		"set metadata for billing in current int key=value key2=value2 keyN=valueN",
+		"set metadata for api in current int motd=\"two words\" replicas:=3 enabled:=true limits:=@json{\"cpu\": \"500m\"}",
+		"set metadata in current int key=value",
+		"set metadata across int key=value stacking=200",
 		"set version for api in current int to 1.3",
 		"set version in current int to 2.0",
 	}
@@ -41,8 +63,7 @@ func NewSetCommand(cmdFields []string, channel, user string) EvebotCommand {
 			CommandName:   SetCmdName,
 			IsHelpRequest: isHelpCmd(cmdFields, SetCmdName),
 		},
-		opts:   make(CommandOptions),
-		bounds: InputLengthBounds{Min: 7, Max: -1},
+		opts: make(CommandOptions),
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -68,45 +89,5 @@ func (cmd setCmd) Info() ChatInfo {
 }
 
 func (cmd *setCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	cmd.initializeResource()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	switch cmd.opts["resource"] {
-	case resources.MetadataName:
-		// set metadata for unaneta in current una-int key=value
-		// set metadata for {{ service }} in {{ namespace }} {{ environment }} key=value key=value key=value
-		if len(cmd.input) < 8 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid set metadata: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.ServiceName] = cmd.input[3]
-		cmd.opts[params.NamespaceName] = cmd.input[5]
-		cmd.opts[params.EnvironmentName] = cmd.input[6]
-		cmd.opts[params.MetadataName] = hydrateMetadataMap(cmd.input[7:])
-		return
-	case resources.VersionName:
-		switch len(cmd.input) {
-		// set version for unaneta in current una-int to 20.2
-		case 9:
-			cmd.opts[params.ServiceName] = cmd.input[3]
-			cmd.opts[params.NamespaceName] = cmd.input[5]
-			cmd.opts[params.EnvironmentName] = cmd.input[6]
-			cmd.opts[params.VersionName] = cmd.input[8]
-		// set version in current una-int to 20.2
-		case 7:
-			cmd.opts[params.NamespaceName] = cmd.input[3]
-			cmd.opts[params.EnvironmentName] = cmd.input[4]
-			cmd.opts[params.VersionName] = cmd.input[6]
-		default:
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid set version: %v", cmd.input))
-			return
-		}
-
-	default:
-		cmd.errs = append(cmd.errs, fmt.Errorf("invalid resource supplied: %v", cmd.opts["resource"]))
-		return
-	}
+	cmd.parseInput(setCmdGrammar)
 }
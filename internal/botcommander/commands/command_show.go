@@ -1,8 +1,8 @@
 package commands
 
 import (
-	"fmt"
-
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/help"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
@@ -15,23 +15,89 @@ type showCmd struct {
 const (
 	// ShowCmdName id/key
 	ShowCmdName = "show"
+	// ShowHistoryOpt is the options key of the metadata history (show metadata history ...)
+	ShowHistoryOpt = "history"
+	// ShowEffectiveOpt is the options key of the effective service metadata (show effective metadata ...)
+	ShowEffectiveOpt = "effective"
+	// ShowDeploymentOpt is the resource of a single deployment (show deployment {{ deployment }})
+	ShowDeploymentOpt = "deployment"
 )
 
 var (
-	showCmdHelpSummary = help.Summary("The `show` command is used to show resources (environments,namespaces,services,metadata,jobs)")
-	showCmdHelpUsage   = help.Usage{
-		"show {{ resources }}",
-		"show namespaces in {{ environment }}",
-		"show services in {{ namespace }} {{ environment }}",
-		"show metadata for {{ service }} in {{ namespace }} {{ environment }}",
-		"show jobs in {{ namespace }} {{ environment }}",
-	}
+	showCmdGrammar = grammar.New(ShowCmdName,
+		grammar.OneOf(
+			// show environments
+			grammar.Keyword(resources.EnvironmentName).As(resourceOpt),
+			// show namespaces in {{ environment }}
+			grammar.Seq(
+				grammar.Keyword(resources.NamespaceName).As(resourceOpt),
+				grammar.Defaulted(grammar.Clause("in", grammar.Param(params.EnvironmentName))),
+			),
+			// show services in {{ namespace }} {{ environment }}
+			grammar.Seq(
+				grammar.Keyword(resources.ServiceName).As(resourceOpt),
+				inNamespaceClause,
+			),
+			// show metadata [history] for {{ service }} in {{ namespace }} {{ environment }} [reveal=true]
+			// show metadata [history] in {{ namespace }} {{ environment }} [reveal=true]
+			// show metadata [history] across {{ environment }} [reveal=true]
+			grammar.Seq(
+				grammar.Keyword(resources.MetadataName).As(resourceOpt),
+				grammar.Optional(grammar.Flag(ShowHistoryOpt, "history")),
+				metadataScopeClause,
+				grammar.Args(args.DefaultRevealArg()),
+			),
+			// show effective metadata for {{ service }} in {{ namespace }} {{ environment }} [reveal=true]
+			grammar.Seq(
+				grammar.Flag(ShowEffectiveOpt, "effective"),
+				grammar.Keyword(resources.MetadataName).As(resourceOpt),
+				grammar.AnyOrder(forServiceClause, inNamespaceClause),
+				grammar.Args(args.DefaultRevealArg()),
+			),
+			// show jobs in {{ namespace }} {{ environment }}
+			grammar.Seq(
+				grammar.Keyword("jobs", resources.JobName).As(resourceOpt),
+				inNamespaceClause,
+			),
+			// show deployments in {{ namespace }} {{ environment }} [since {{ duration }}] [service=api] [page=2]
+			grammar.Seq(
+				grammar.Keyword(resources.DeploymentName).As(resourceOpt),
+				inNamespaceClause,
+				sinceClause,
+				grammar.Args(args.DefaultServiceFilterArg(), args.DefaultPageArg()),
+			),
+			// show deployment {{ deployment }}
+			grammar.Seq(
+				grammar.Keyword(ShowDeploymentOpt).As(resourceOpt),
+				grammar.Param(params.DeploymentName),
+			),
+			// show subscriptions
+			grammar.Keyword(resources.SubscriptionName).As(resourceOpt),
+			// show pods for {{ service }} in {{ namespace }} {{ environment }}
+			grammar.Seq(
+				grammar.Keyword(resources.PodName).As(resourceOpt),
+				grammar.AnyOrder(forServiceClause, inNamespaceClause),
+			),
+		),
+	)
+	showCmdHelpSummary = help.Summary("The `show` command is used to show resources (environments,namespaces,services,metadata,jobs,deployments,subscriptions,pods), the namespace and environment metadata is inherited by their services through the eve stacking")
+	showCmdHelpUsage   = showCmdGrammar.Usage()
 	showCmdHelpExample = help.Examples{
 		"show environments",
 		"show namespaces in int",
 		"show services in current int",
 		"show metadata for billing in current int",
+		"show metadata for billing in current int reveal=true",
+		"show metadata history for billing in current int",
+		"show metadata in current int",
+		"show metadata across int",
+		"show effective metadata for billing in current int",
 		"show jobs in current int",
+		"show deployments in current int",
+		"show deployments in current int since 30d service=billing page=2",
+		"show deployment 4f1c2a9e0b7d4e3f8a6b5c4d3e2f1a0b",
+		"show subscriptions",
+		"show pods for billing in current int",
 	}
 )
 
@@ -45,8 +111,8 @@ func NewShowCommand(cmdFields []string, channel, user string) EvebotCommand {
 			CommandName:   ShowCmdName,
 			IsHelpRequest: isHelpCmd(cmdFields, ShowCmdName),
 		},
-		opts:   make(CommandOptions),
-		bounds: InputLengthBounds{Min: 2, Max: 7},
+		arguments: args.Args{args.DefaultRevealArg(), args.DefaultServiceFilterArg(), args.DefaultPageArg()},
+		opts:      make(CommandOptions),
 	}}
 	cmd.resolveDynamicOptions()
 	return cmd
@@ -57,6 +123,7 @@ func (cmd showCmd) AckMsg() (string, bool) {
 	return cmd.BaseAckMsg(help.New(
 		help.HeaderOpt(showCmdHelpSummary.String()),
 		help.UsageOpt(showCmdHelpUsage.String()),
+		help.ArgsOpt(cmd.arguments.String()),
 		help.ExamplesOpt(showCmdHelpExample.String()),
 	).String())
 }
@@ -72,59 +139,5 @@ func (cmd showCmd) Info() ChatInfo {
 }
 
 func (cmd *showCmd) resolveDynamicOptions() {
-	cmd.verifyInput()
-	cmd.initializeResource()
-	if len(cmd.errs) > 0 {
-		return
-	}
-
-	switch cmd.opts["resource"] {
-	case resources.JobName, "jobs":
-		// show jobs in {{namespace}} {{environment}}
-		if len(cmd.input) != 5 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid show jobs: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.NamespaceName] = cmd.input[3]
-		cmd.opts[params.EnvironmentName] = cmd.input[4]
-		return
-	case resources.EnvironmentName:
-		// show environments
-		if len(cmd.input) != 2 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid show environment: %v", cmd.input))
-			return
-		}
-		//...doesn't have any additional requirements
-		return
-	case resources.NamespaceName:
-		// show namespaces in {{environment}}
-		if len(cmd.input) != 4 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid show namespace: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.EnvironmentName] = cmd.input[3]
-		return
-	case resources.ServiceName:
-		// show services in {{namespace}} {{environment}}
-		if len(cmd.input) != 5 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid show service: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.NamespaceName] = cmd.input[3]
-		cmd.opts[params.EnvironmentName] = cmd.input[4]
-		return
-	case resources.MetadataName:
-		// show metadata for {{ service }} in {{ namespace }} {{ environment }}
-		if len(cmd.input) != 7 {
-			cmd.errs = append(cmd.errs, fmt.Errorf("invalid show metadata: %v", cmd.input))
-			return
-		}
-		cmd.opts[params.ServiceName] = cmd.input[3]
-		cmd.opts[params.NamespaceName] = cmd.input[5]
-		cmd.opts[params.EnvironmentName] = cmd.input[6]
-		return
-	default:
-		cmd.errs = append(cmd.errs, fmt.Errorf("invalid resource supplied: %v", cmd.opts["resource"]))
-		return
-	}
+	cmd.parseInput(showCmdGrammar)
 }
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+)
+
+type subscribeCmd struct {
+	baseCommand
+}
+
+const (
+	// SubscribeCmdName id/key
+	SubscribeCmdName = "subscribe"
+	// UnsubscribeCmdName id/key
+	UnsubscribeCmdName = "unsubscribe"
+
+	// SubscribeChannelOpt is the options key set when the notifications are sent to the channel (instead of a private message)
+	SubscribeChannelOpt = "channel"
+)
+
+// subscriptionGrammar is the subscription of both the subscribe and the unsubscribe command
+// {{ keyword }} [channel] to deployments [in {{ namespace }} {{ environment }} | across {{ environment }}] [service=api]
+func subscriptionGrammar(cmdName, keyword string) grammar.Grammar {
+	return grammar.New(cmdName,
+		grammar.Optional(grammar.Flag(SubscribeChannelOpt, "channel")).Or(SubscribeChannelOpt, false),
+		grammar.Keyword(keyword),
+		grammar.Keyword(resources.DeploymentName).As(resourceOpt),
+		grammar.Optional(grammar.OneOf(inNamespaceClause, acrossEnvironmentClause)).OrDefault(),
+		grammar.Args(args.DefaultServiceFilterArg()),
+	)
+}
+
+var (
+	subscribeCmdGrammar   = subscriptionGrammar(SubscribeCmdName, "to")
+	subscribeCmdHelpUsage = subscribeCmdGrammar.Usage()
+
+	subscribeCmdHelpSummary = help.Summary("The `subscribe` command is used to be notified (in a private message or in the channel) " +
+		"when a deployment completes in a namespace, an environment or for a service, whoever ran it")
+	subscribeCmdHelpExample = help.Examples{
+		"subscribe to deployments in current prod",
+		"subscribe to deployments service=billing",
+		"subscribe channel to deployments across prod service=billing",
+		"show subscriptions",
+	}
+
+	unsubscribeCmdGrammar   = subscriptionGrammar(UnsubscribeCmdName, "from")
+	unsubscribeCmdHelpUsage = unsubscribeCmdGrammar.Usage()
+
+	unsubscribeCmdHelpSummary = help.Summary("The `unsubscribe` command is used to delete a subscription (see `show subscriptions`)")
+	unsubscribeCmdHelpExample = help.Examples{
+		"unsubscribe from deployments in current prod",
+		"unsubscribe channel from deployments across prod service=billing",
+	}
+)
+
+// NewSubscribeCommand creates a New SubscribeCmd that implements the EvebotCommand interface
+func NewSubscribeCommand(cmdFields []string, channel, user string) EvebotCommand {
+	return newSubscriptionCommand(cmdFields, channel, user, SubscribeCmdName, subscribeCmdGrammar)
+}
+
+// NewUnsubscribeCommand creates a New UnsubscribeCmd (a SubscribeCmd) that implements the EvebotCommand interface
+func NewUnsubscribeCommand(cmdFields []string, channel, user string) EvebotCommand {
+	return newSubscriptionCommand(cmdFields, channel, user, UnsubscribeCmdName, unsubscribeCmdGrammar)
+}
+
+func newSubscriptionCommand(cmdFields []string, channel, user, cmdName string, g grammar.Grammar) EvebotCommand {
+	cmd := subscribeCmd{baseCommand{
+		input: cmdFields,
+		info: ChatInfo{
+			User:          user,
+			Channel:       channel,
+			CommandName:   cmdName,
+			IsHelpRequest: isHelpCmd(cmdFields, cmdName),
+		},
+		arguments: args.Args{args.DefaultServiceFilterArg()},
+		opts:      make(CommandOptions),
+	}}
+	cmd.resolveDynamicOptions(g)
+	return cmd
+}
+
+// AckMsg satisfies the EveBotCommand Interface and returns the acknowledgement message
+func (cmd subscribeCmd) AckMsg() (string, bool) {
+	summary, usage, examples := subscribeCmdHelpSummary, subscribeCmdHelpUsage, subscribeCmdHelpExample
+	if cmd.info.CommandName == UnsubscribeCmdName {
+		summary, usage, examples = unsubscribeCmdHelpSummary, unsubscribeCmdHelpUsage, unsubscribeCmdHelpExample
+	}
+	return cmd.BaseAckMsg(help.New(
+		help.HeaderOpt(summary.String()),
+		help.UsageOpt(usage.String()),
+		help.ArgsOpt(cmd.arguments.String()),
+		help.ExamplesOpt(examples.String()),
+	).String())
+}
+
+// Options satisfies the EveBotCommand Interface and returns the dynamic options
+func (cmd subscribeCmd) Options() CommandOptions {
+	return cmd.opts
+}
+
+// Info satisfies the EveBotCommand Interface and returns the Chat Info
+func (cmd subscribeCmd) Info() ChatInfo {
+	return cmd.info
+}
+
+func (cmd *subscribeCmd) resolveDynamicOptions(g grammar.Grammar) {
+	cmd.parseInput(g)
+	if len(cmd.errs) > 0 {
+		return
+	}
+	// every deployment would be too noisy
+	if len(ExtractStringOpt(params.EnvironmentName, cmd.opts)) == 0 && len(ExtractStringOpt(args.ServiceFilterName, cmd.opts)) == 0 {
+		cmd.errs = append(cmd.errs, errors.New("a subscription requires a location (`in {{ namespace }} {{ environment }}` or `across {{ environment }}`) or a service (`service=api`)"))
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
-	"github.com/unanet/eve-bot/internal/botcommander/resources"
 	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
 )
 
@@ -54,27 +54,14 @@ type baseCommand struct {
 	opts       CommandOptions // when we resolve the arguments and parameters we hydrate this map for fast lookup
 }
 
-func (bc *baseCommand) verifyInput() {
-	if !bc.ValidInputLength() {
-		bc.errs = append(bc.errs, fmt.Errorf("invalid input length: %v", bc.input))
-	}
-}
-
-func (bc *baseCommand) initializeResource() {
-	if len(bc.input) < 2 {
-		bc.errs = append(bc.errs, fmt.Errorf("invalid input: %v", bc.input))
-		return
-	}
-	if ok := resources.FullResourceMap[bc.input[1]]; ok {
-		bc.opts["resource"] = bc.input[1]
-	} else {
-		bc.errs = append(bc.errs, fmt.Errorf("invalid requested resource: %v", bc.input))
-		return
+// parseInput parses the input with the command grammar and hydrates the options
+func (bc *baseCommand) parseInput(g grammar.Grammar) {
+	opts, err := g.Parse(bc.input)
+	for k, v := range opts {
+		bc.opts[k] = v
 	}
-
-	if bc.opts["resource"] == nil {
-		bc.errs = append(bc.errs, fmt.Errorf("invalid resource: %v", bc.input))
-		return
+	if err != nil {
+		bc.errs = append(bc.errs, err)
 	}
 }
 
@@ -0,0 +1,451 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
+)
+
+func Test_Grammar_resolveDynamicOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CommandOptions
+		wantErr string
+	}{
+		// deploy
+		{
+			name:  "deploy",
+			input: "deploy current in int",
+			want:  CommandOptions{"namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "deploy with args",
+			input: "deploy current in int services=api:1.0,billing dryrun=true force=true",
+			want: CommandOptions{
+				"namespace":   "current",
+				"environment": "int",
+				"services":    args.NewServicesArg([]string{"api:1.0", "billing"}).Value(),
+				"dryrun":      true,
+				"force":       true,
+			},
+		},
+		{
+			name:    "deploy without in",
+			input:   "deploy current int",
+			wantErr: "expected `in` at position 3 but got `int`",
+		},
+		{
+			name:    "deploy with an unknown arg",
+			input:   "deploy current in int databases=foo",
+			wantErr: "expected {{ arg=value }} at position 5 but got `databases=foo`",
+		},
+
+		// show
+		{
+			name:  "show environments",
+			input: "show environments",
+			want:  CommandOptions{"resource": "environments"},
+		},
+		{
+			name:  "show namespaces",
+			input: "show namespaces in int",
+			want:  CommandOptions{"resource": "namespaces", "environment": "int"},
+		},
+		{
+			name:  "show services",
+			input: "show services in current int",
+			want:  CommandOptions{"resource": "services", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "show jobs",
+			input: "show jobs in current int",
+			want:  CommandOptions{"resource": "jobs", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "show metadata",
+			input: "show metadata for api in current int",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "show metadata reveal",
+			input: "show metadata for api in current int reveal=true",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int", "reveal": true},
+		},
+		{
+			name:  "show metadata with the clauses swapped",
+			input: "show metadata in current int for api",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:    "show unknown resource",
+			input:   "show widgets in current int",
+			wantErr: "expected one of `environments`, `namespaces`, `services`, `metadata`, `effective`, `jobs`, `deployments`, `deployment`, `subscriptions`, `pods` at position 2 but got `widgets`",
+		},
+		{
+			name:  "show namespace metadata",
+			input: "show metadata in current int",
+			want:  CommandOptions{"resource": "metadata", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "show environment metadata",
+			input: "show metadata across int",
+			want:  CommandOptions{"resource": "metadata", "environment": "int"},
+		},
+		{
+			name:    "show metadata without a scope",
+			input:   "show metadata api",
+			wantErr: "expected one of `for`, `across` at position 3 but got `api`",
+		},
+		{
+			name:  "show effective metadata",
+			input: "show effective metadata for api in current int",
+			want:  CommandOptions{"resource": "metadata", "effective": true, "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:    "show effective metadata without a service",
+			input:   "show effective metadata in current int",
+			wantErr: "expected `for` at position 7",
+		},
+		{
+			name:    "show services without an environment",
+			input:   "show services in current",
+			wantErr: "expected {{ environment }} at position 5",
+		},
+		{
+			name:  "show deployments",
+			input: "show deployments in current int",
+			want:  CommandOptions{"resource": "deployments", "namespace": "current", "environment": "int", "since": DefaultSince},
+		},
+		{
+			name:  "show deployments with a filter",
+			input: "show deployments in current int since 30d service=api page=2",
+			want: CommandOptions{
+				"resource":    "deployments",
+				"namespace":   "current",
+				"environment": "int",
+				"since":       30 * 24 * time.Hour,
+				"service":     "api",
+				"page":        2,
+			},
+		},
+		{
+			name:    "show deployments with an invalid duration",
+			input:   "show deployments in current int since forever",
+			wantErr: "invalid duration `forever`, use i.e. 7d, 12h or 30m",
+		},
+		{
+			name:  "show deployment",
+			input: "show deployment 4f1c2a9e",
+			want:  CommandOptions{"resource": "deployment", "deployment": "4f1c2a9e"},
+		},
+
+		// set
+		{
+			name:  "set metadata",
+			input: `set metadata for api in current int key=value key2="value 2"`,
+			want: CommandOptions{
+				"resource":    "metadata",
+				"service":     "api",
+				"namespace":   "current",
+				"environment": "int",
+				"metadata":    params.MetadataMap{"key": "value", "key2": "value 2"},
+			},
+		},
+		{
+			name:  "set service version",
+			input: "set version for api in current int to 1.3",
+			want:  CommandOptions{"resource": "version", "service": "api", "namespace": "current", "environment": "int", "version": "1.3"},
+		},
+		{
+			name:  "set namespace version",
+			input: "set version in current int to 2.0",
+			want:  CommandOptions{"resource": "version", "namespace": "current", "environment": "int", "version": "2.0"},
+		},
+		{
+			name:    "set metadata with an unquoted value",
+			input:   "set metadata for api in current int key=value key2= value 2",
+			wantErr: "unexpected `value`, `2`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
+		},
+		{
+			name:  "set environment metadata with a stacking order",
+			input: "set metadata across int key=value stacking=250",
+			want: CommandOptions{
+				"resource":    "metadata",
+				"environment": "int",
+				"stacking":    250,
+				"metadata":    params.MetadataMap{"key": "value"},
+			},
+		},
+		{
+			name:    "set metadata with an invalid stacking order",
+			input:   "set metadata in current int key=value stacking=high",
+			wantErr: "invalid arg `stacking=high`, the stacking order must be a positive number",
+		},
+		{
+			name:  "set metadata without values (file upload)",
+			input: "set metadata for api in current int",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:    "set version without a value",
+			input:   "set version in current int",
+			wantErr: "expected `to` at position 6",
+		},
+
+		// delete
+		{
+			name:  "delete metadata",
+			input: "delete metadata for api in current int key key2",
+			want: CommandOptions{
+				"resource":    "metadata",
+				"service":     "api",
+				"namespace":   "current",
+				"environment": "int",
+				"metadata":    []string{"key", "key2"},
+			},
+		},
+		{
+			name:  "delete version",
+			input: "delete version for api in current int",
+			want:  CommandOptions{"resource": "version", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:    "delete version with trailing input",
+			input:   "delete version for api in current int now",
+			wantErr: "unexpected `now` at position 8",
+		},
+
+		// restart
+		{
+			name:  "restart",
+			input: "restart api in current int",
+			want:  CommandOptions{"service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:    "restart without a namespace",
+			input:   "restart api",
+			wantErr: "expected `in` at position 3",
+		},
+
+		// run
+		{
+			name:  "run",
+			input: "run migration in current int",
+			want:  CommandOptions{"job": "migration", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "run with metadata",
+			input: "run cool-job:1.2 in current int key=value",
+			want: CommandOptions{
+				"job":         "cool-job:1.2",
+				"namespace":   "current",
+				"environment": "int",
+				"metadata":    params.MetadataMap{"key": "value"},
+			},
+		},
+
+		// context
+		{
+			name:  "context set",
+			input: "context set namespace=current environment=int",
+			want:  CommandOptions{"action": "set", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "context show",
+			input: "context show",
+			want:  CommandOptions{"action": "show"},
+		},
+		{
+			name:    "context set without args",
+			input:   "context set",
+			wantErr: "expected namespace={{ namespace }} environment={{ environment }} at position 3",
+		},
+		{
+			name:    "context set with an unknown arg",
+			input:   "context set cluster=foo",
+			wantErr: "invalid context arg: cluster=foo",
+		},
+
+		// export
+		{
+			name:  "export metadata",
+			input: "export metadata for api in current int",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int", "format": "json"},
+		},
+		{
+			name:  "export metadata as yaml",
+			input: "export metadata as yaml for api in current int",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int", "format": "yaml"},
+		},
+		{
+			name:    "export metadata as xml",
+			input:   "export metadata for api in current int as xml",
+			wantErr: "expected one of `json`, `yaml` at position 9 but got `xml`",
+		},
+
+		// copy
+		{
+			name:  "copy metadata",
+			input: "copy metadata for api from current int to current qa",
+			want: CommandOptions{
+				"resource":          "metadata",
+				"service":           "api",
+				"sourceNamespace":   "current",
+				"sourceEnvironment": "int",
+				"namespace":         "current",
+				"environment":       "qa",
+			},
+		},
+		{
+			name:  "copy metadata keys",
+			input: "copy metadata from current int to current qa for api keys=a,b",
+			want: CommandOptions{
+				"resource":          "metadata",
+				"service":           "api",
+				"sourceNamespace":   "current",
+				"sourceEnvironment": "int",
+				"namespace":         "current",
+				"environment":       "qa",
+				"keys":              []string{"a", "b"},
+			},
+		},
+		{
+			name:    "copy metadata without a target",
+			input:   "copy metadata for api from current int",
+			wantErr: "expected `to` at position 8",
+		},
+
+		// restore
+		{
+			name:  "show metadata history",
+			input: "show metadata history for api in current int",
+			want:  CommandOptions{"resource": "metadata", "history": true, "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "restore metadata",
+			input: "restore metadata for api in current int to 3",
+			want:  CommandOptions{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int", "revision": 3},
+		},
+		{
+			name:  "restore environment metadata",
+			input: "restore metadata to 2 across int",
+			want:  CommandOptions{"resource": "metadata", "environment": "int", "revision": 2},
+		},
+		{
+			name:    "restore metadata invalid revision",
+			input:   "restore metadata for api in current int to latest",
+			wantErr: "invalid revision `latest`, expected a revision number (see `show metadata history`)",
+		},
+		{
+			name:  "show pods",
+			input: "show pods for api in current int",
+			want:  CommandOptions{"resource": "pods", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		// logs
+		{
+			name:  "logs",
+			input: "logs api in current int",
+			want:  CommandOptions{"service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "logs with args",
+			input: "logs api in current int tail=500 since=10m",
+			want:  CommandOptions{"service": "api", "namespace": "current", "environment": "int", "tail": 500, "since": 10 * time.Minute},
+		},
+		// subscribe
+		{
+			name:  "subscribe to a namespace",
+			input: "subscribe to deployments in current prod",
+			want:  CommandOptions{"channel": false, "resource": "deployments", "namespace": "current", "environment": "prod"},
+		},
+		{
+			name:  "subscribe the channel to a service",
+			input: "subscribe channel to deployments across prod service=billing",
+			want:  CommandOptions{"channel": true, "resource": "deployments", "environment": "prod", "service": "billing"},
+		},
+		{
+			name:    "subscribe to every deployment",
+			input:   "subscribe to deployments",
+			wantErr: "a subscription requires a location (`in {{ namespace }} {{ environment }}` or `across {{ environment }}`) or a service (`service=api`)",
+		},
+		{
+			name:  "unsubscribe from a service",
+			input: "unsubscribe from deployments service=billing",
+			want:  CommandOptions{"channel": false, "resource": "deployments", "service": "billing"},
+		},
+		{
+			name:  "show subscriptions",
+			input: "show subscriptions",
+			want:  CommandOptions{"resource": "subscriptions"},
+		},
+		// diff
+		{
+			name:  "diff metadata",
+			input: "diff metadata for api from current int to latest int",
+			want: CommandOptions{
+				"resource":          "metadata",
+				"service":           "api",
+				"sourceNamespace":   "current",
+				"sourceEnvironment": "int",
+				"namespace":         "latest",
+				"environment":       "int",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the resolver splits the input with the tokenizer (the quoted values are a field)
+			fields := tokenizer.Split(tt.input)
+			cmd := NewFactory().Items()[fields[0]](fields, "channel", "user")
+			var errs []error
+			switch c := cmd.(type) {
+			case deployCmd:
+				errs = c.errs
+			case showCmd:
+				errs = c.errs
+			case setCmd:
+				errs = c.errs
+			case deleteCmd:
+				errs = c.errs
+			case restartCmd:
+				errs = c.errs
+			case runCmd:
+				errs = c.errs
+			case contextCmd:
+				errs = c.errs
+			case exportCmd:
+				errs = c.errs
+			case copyCmd:
+				errs = c.errs
+			case diffCmd:
+				errs = c.errs
+			case restoreCmd:
+				errs = c.errs
+			case subscribeCmd:
+				errs = c.errs
+			case logsCmd:
+				errs = c.errs
+			default:
+				t.Fatalf("unexpected command type: %T", cmd)
+			}
+
+			if len(tt.wantErr) > 0 {
+				if len(errs) != 1 || errs[0].Error() != tt.wantErr {
+					t.Fatalf("errs = %v, wantErr %v", errs, tt.wantErr)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errs = %v", errs)
+			}
+			if !reflect.DeepEqual(cmd.Options(), tt.want) {
+				t.Errorf("got = %v\nwant %v", cmd.Options(), tt.want)
+			}
+		})
+	}
+}
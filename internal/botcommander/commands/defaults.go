@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"github.com/unanet/eve-bot/internal/botcommander/grammar"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+)
+
+// channelDefaultsGrammars are the grammars of the commands with a location (namespace and environment) filled in
+// with the channel defaults when the user left it out
+var channelDefaultsGrammars = map[string]grammar.Grammar{
+	DeleteCmdName:      deleteCmdGrammar,
+	DeployCmdName:      deployCmdGrammar,
+	ExportCmdName:      exportCmdGrammar,
+	LogsCmdName:        logsCmdGrammar,
+	RestartCmdName:     restartCmdGrammar,
+	RunCmdName:         runCmdGrammar,
+	SetCmdName:         setCmdGrammar,
+	ShowCmdName:        showCmdGrammar,
+	SubscribeCmdName:   subscribeCmdGrammar,
+	UnsubscribeCmdName: unsubscribeCmdGrammar,
+}
+
+// ApplyChannelDefaults fills in the location clauses the user left out of a command (i.e. `in {{ namespace }} {{ environment }}`)
+// with the channel defaults, the clauses are the ones declared as defaulted in the command grammar
+// the input fields are returned untouched when the command already has its location (or doesn't take one),
+// when a default is missing or when the completed command doesn't parse
+func ApplyChannelDefaults(cmdFields []string, namespace, environment string) []string {
+	g, ok := channelDefaultsGrammar(cmdFields)
+	if !ok {
+		return cmdFields
+	}
+	defaults := grammar.Options{}
+	if len(namespace) > 0 {
+		defaults[params.NamespaceName] = namespace
+	}
+	if len(environment) > 0 {
+		defaults[params.EnvironmentName] = environment
+	}
+	if len(defaults) == 0 {
+		return cmdFields
+	}
+	completed, err := g.Complete(cmdFields, defaults)
+	if err != nil {
+		return cmdFields
+	}
+	return completed
+}
+
+// NeedsChannelDefaults checks if the user left a location clause out of the command,
+// so that the channel context is only read when ApplyChannelDefaults can fill it in
+func NeedsChannelDefaults(cmdFields []string) bool {
+	g, ok := channelDefaultsGrammar(cmdFields)
+	if !ok {
+		return false
+	}
+	completed, err := g.Complete(cmdFields, grammar.Options{params.NamespaceName: "-", params.EnvironmentName: "-"})
+	return err == nil && len(completed) != len(cmdFields)
+}
+
+// channelDefaultsGrammar returns the grammar of the command when it takes the channel defaults (and isn't a help request)
+func channelDefaultsGrammar(cmdFields []string) (grammar.Grammar, bool) {
+	if len(cmdFields) == 0 || isHelpCmd(cmdFields, cmdFields[0]) {
+		return grammar.Grammar{}, false
+	}
+	g, ok := channelDefaultsGrammars[cmdFields[0]]
+	return g, ok
+}
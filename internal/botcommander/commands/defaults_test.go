@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyChannelDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		namespace   string
+		environment string
+		want        string
+	}{
+		{
+			name:        "deploy without location",
+			input:       "deploy services=api dryrun=true",
+			namespace:   "current",
+			environment: "int",
+			want:        "deploy current in int services=api dryrun=true",
+		},
+		{
+			name:        "deploy with location is untouched",
+			input:       "deploy latest in qa services=api",
+			namespace:   "current",
+			environment: "int",
+			want:        "deploy latest in qa services=api",
+		},
+		{
+			name:        "deploy help is untouched",
+			input:       "deploy help",
+			namespace:   "current",
+			environment: "int",
+			want:        "deploy help",
+		},
+		{
+			name:        "show services",
+			input:       "show services",
+			namespace:   "current",
+			environment: "int",
+			want:        "show services in current int",
+		},
+		{
+			name:        "show metadata",
+			input:       "show metadata for api",
+			namespace:   "current",
+			environment: "int",
+			want:        "show metadata for api in current int",
+		},
+		{
+			name:        "show namespaces only needs the environment",
+			input:       "show namespaces",
+			environment: "int",
+			want:        "show namespaces in int",
+		},
+		{
+			name:        "show services without a namespace context",
+			input:       "show services",
+			environment: "int",
+			want:        "show services",
+		},
+		{
+			name:        "show environments is untouched",
+			input:       "show environments",
+			namespace:   "current",
+			environment: "int",
+			want:        "show environments",
+		},
+		{
+			name:        "set metadata",
+			input:       "set metadata for api key=value",
+			namespace:   "current",
+			environment: "int",
+			want:        "set metadata for api in current int key=value",
+		},
+		{
+			name:        "set namespace version",
+			input:       "set version to 2.0",
+			namespace:   "current",
+			environment: "int",
+			want:        "set version to 2.0 in current int",
+		},
+		{
+			name:        "set service version",
+			input:       "set version for api to 2.0",
+			namespace:   "current",
+			environment: "int",
+			want:        "set version for api to 2.0 in current int",
+		},
+		{
+			name:        "delete metadata",
+			input:       "delete metadata for api key key2",
+			namespace:   "current",
+			environment: "int",
+			want:        "delete metadata for api in current int key key2",
+		},
+		{
+			name:        "restart",
+			input:       "restart api",
+			namespace:   "current",
+			environment: "int",
+			want:        "restart api in current int",
+		},
+		{
+			name:        "run",
+			input:       "run migration key=value",
+			namespace:   "current",
+			environment: "int",
+			want:        "run migration in current int key=value",
+		},
+		{
+			name:        "logs",
+			input:       "logs api tail=50",
+			namespace:   "current",
+			environment: "int",
+			want:        "logs api in current int tail=50",
+		},
+		{
+			name:        "show pods",
+			input:       "show pods for api",
+			namespace:   "current",
+			environment: "int",
+			want:        "show pods for api in current int",
+		},
+		{
+			name:        "show deployments",
+			input:       "show deployments since 30d service=api",
+			namespace:   "current",
+			environment: "int",
+			want:        "show deployments in current int since 30d service=api",
+		},
+		{
+			name:        "show effective metadata",
+			input:       "show effective metadata for api",
+			namespace:   "current",
+			environment: "int",
+			want:        "show effective metadata for api in current int",
+		},
+		{
+			name:        "show metadata reveal",
+			input:       "show metadata for api reveal=true",
+			namespace:   "current",
+			environment: "int",
+			want:        "show metadata for api in current int reveal=true",
+		},
+		{
+			name:        "show namespace metadata",
+			input:       "show metadata history",
+			namespace:   "current",
+			environment: "int",
+			want:        "show metadata history in current int",
+		},
+		{
+			name:        "show metadata across is untouched",
+			input:       "show metadata across qa",
+			namespace:   "current",
+			environment: "int",
+			want:        "show metadata across qa",
+		},
+		{
+			name:        "subscribe",
+			input:       "subscribe channel to deployments service=api",
+			namespace:   "current",
+			environment: "int",
+			want:        "subscribe channel to deployments in current int service=api",
+		},
+		{
+			name:        "logs of a service named in",
+			input:       "logs in tail=50",
+			namespace:   "current",
+			environment: "int",
+			want:        "logs in in current int tail=50",
+		},
+		{
+			name:        "invalid command is untouched",
+			input:       "restart api now",
+			namespace:   "current",
+			environment: "int",
+			want:        "restart api now",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyChannelDefaults(strings.Fields(tt.input), tt.namespace, tt.environment)
+			if !reflect.DeepEqual(got, strings.Fields(tt.want)) {
+				t.Errorf("ApplyChannelDefaults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 
 var errInvalidAPIResp = errors.New("invalid api response")
 
+// errKubernetesDisabled is the message of the pods and logs commands without any configured cluster
+var errKubernetesDisabled = errors.New("the kubernetes clusters aren't configured, ask `@devops` for the pods and logs")
+
 // CommandHandler is the interface that Handles EvebotCommands
 type CommandHandler interface {
 	Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string)
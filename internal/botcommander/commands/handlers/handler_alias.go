@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"sort"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// AliasHandler is the handler for the AliasCmd
+type AliasHandler struct {
+	svc *service.Provider
+}
+
+// NewAliasHandler creates an AliasHandler
+func NewAliasHandler(svc *service.Provider) CommandHandler {
+	return AliasHandler{svc: svc}
+}
+
+// Handle handles the AliasCmd
+func (h AliasHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	switch cmd.Options()[commands.AliasActionOpt] {
+	case commands.AliasActionSet:
+		h.setAlias(ctx, cmd, &timestamp)
+	case commands.AliasActionList:
+		h.listAliases(ctx, cmd, &timestamp)
+	case commands.AliasActionDelete:
+		h.deleteAlias(ctx, cmd, &timestamp)
+	default:
+		h.svc.ChatService.UserNotificationThread(ctx, "invalid alias command", cmd.Info().User, cmd.Info().Channel, timestamp)
+	}
+}
+
+func (h AliasHandler) setAlias(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	scope, owner := aliasOwner(cmd)
+	alias := datastore.Alias{
+		Owner:     owner,
+		Name:      commands.ExtractStringOpt(params.AliasName, cmd.Options()),
+		Command:   commands.ExtractStringOpt(commands.AliasCommandOpt, cmd.Options()),
+		Scope:     scope,
+		CreatedBy: cmd.Info().User,
+	}
+	if err := h.svc.AliasStore.SaveAlias(ctx, alias); err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("failed to save alias"))
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("%s alias %s = %s", scope, alias.Name, alias.Command), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func (h AliasHandler) listAliases(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	userAliases, err := h.svc.AliasStore.ListAliases(ctx, datastore.AliasOwner(datastore.AliasScopeUser, cmd.Info().User))
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	channelAliases, err := h.svc.AliasStore.ListAliases(ctx, datastore.AliasOwner(datastore.AliasScopeChannel, cmd.Info().Channel))
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	if len(userAliases) == 0 && len(channelAliases) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, "no aliases", cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, aliasesMsg("Your aliases", userAliases)+aliasesMsg("Channel aliases", channelAliases), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func (h AliasHandler) deleteAlias(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	scope, owner := aliasOwner(cmd)
+	name := commands.ExtractStringOpt(params.AliasName, cmd.Options())
+	if err := h.svc.AliasStore.DeleteAlias(ctx, owner, name); err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no %s alias found for: %s", scope, name), cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("%s alias %s deleted", scope, name), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func aliasOwner(cmd commands.EvebotCommand) (datastore.AliasScope, string) {
+	if commands.ExtractBoolOpt(commands.AliasChannelOpt, cmd.Options()) {
+		return datastore.AliasScopeChannel, datastore.AliasOwner(datastore.AliasScopeChannel, cmd.Info().Channel)
+	}
+	return datastore.AliasScopeUser, datastore.AliasOwner(datastore.AliasScopeUser, cmd.Info().User)
+}
+
+func aliasesMsg(header string, aliases []datastore.Alias) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	msg := "*" + header + ":*\n```"
+	for _, a := range aliases {
+		msg += a.Name + " = " + a.Command + "\n"
+	}
+	return msg + "```\n"
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/suggest"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// ContextHandler is the handler for the ContextCmd
+type ContextHandler struct {
+	svc *service.Provider
+}
+
+// NewContextHandler creates a ContextHandler
+func NewContextHandler(svc *service.Provider) CommandHandler {
+	return ContextHandler{svc: svc}
+}
+
+// Handle handles the ContextCmd
+func (h ContextHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	switch cmd.Options()[commands.ContextActionOpt] {
+	case commands.ContextActionSet:
+		h.setContext(ctx, cmd, &timestamp)
+	case commands.ContextActionShow:
+		h.showContext(ctx, cmd, &timestamp)
+	case commands.ContextActionClear:
+		h.clearContext(ctx, cmd, &timestamp)
+	default:
+		h.svc.ChatService.UserNotificationThread(ctx, "invalid context command", cmd.Info().User, cmd.Info().Channel, timestamp)
+	}
+}
+
+func (h ContextHandler) setContext(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	cc, err := h.readContext(ctx, cmd.Info().Channel)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+
+	// a partial set (namespace or environment) keeps the other existing value
+	if env := commands.ExtractStringOpt(params.EnvironmentName, cmd.Options()); len(env) > 0 {
+		cc.Environment = env
+	}
+	if ns := commands.ExtractStringOpt(params.NamespaceName, cmd.Options()); len(ns) > 0 {
+		cc.Namespace = ns
+	}
+	if len(cc.Environment) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, "a namespace context requires an environment, use: `context set namespace={{ namespace }} environment={{ environment }}`", cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	// validate the environment (and namespace) so the defaults don't break every command in the channel
+	namespaces, err := h.svc.EveAPI.GetNamespacesByEnvironment(ctx, cc.Environment)
+	if err != nil {
+		if resourceNotFoundError(err) {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("invalid environment: %s", cc.Environment), cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	if len(namespaces) == 0 {
+		if msg, unknown := unknownEnvironment(ctx, h.svc, cc.Environment); unknown {
+			h.svc.ChatService.UserNotificationThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+	}
+	if len(cc.Namespace) > 0 {
+		var valid bool
+		var aliases []string
+		for _, ns := range namespaces {
+			if strings.EqualFold(ns.Alias, cc.Namespace) {
+				valid = true
+				break
+			}
+			aliases = append(aliases, ns.Alias)
+		}
+		if !valid {
+			h.svc.ChatService.UserNotificationThread(ctx, suggest.Unknown("namespace", cc.Namespace, aliases), cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+	}
+
+	if channelInfo, err := h.svc.ChatService.GetChannelInfo(ctx, cmd.Info().Channel); err == nil {
+		cc.ChannelName = channelInfo.Name
+	} else {
+		log.Logger.Warn("failed to get channel info", zap.String("channel", cmd.Info().Channel), zap.Error(err))
+	}
+	cc.ChannelID = cmd.Info().Channel
+	cc.UpdatedBy = cmd.Info().User
+
+	if err = h.svc.ContextStore.SaveChannelContext(ctx, *cc); err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("failed to save channel context"))
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx, channelContextMsg(cc), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func (h ContextHandler) showContext(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	cc, err := h.svc.ContextStore.ReadChannelContext(ctx, cmd.Info().Channel)
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			h.svc.ChatService.UserNotificationThread(ctx, "no channel context", cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, channelContextMsg(cc), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func (h ContextHandler) clearContext(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	if err := h.svc.ContextStore.DeleteChannelContext(ctx, cmd.Info().Channel); err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx, "channel context cleared", cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// readContext returns the existing channel context (or an empty one when it isn't set yet)
+func (h ContextHandler) readContext(ctx context.Context, channel string) (*datastore.ChannelContext, error) {
+	cc, err := h.svc.ContextStore.ReadChannelContext(ctx, channel)
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			return &datastore.ChannelContext{}, nil
+		}
+		return nil, err
+	}
+	return cc, nil
+}
+
+func channelContextMsg(cc *datastore.ChannelContext) string {
+	ns := cc.Namespace
+	if len(ns) == 0 {
+		ns = "_not set_"
+	}
+	return fmt.Sprintf("*Channel context:*\nnamespace: %s\nenvironment: %s", ns, cc.Environment)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/service"
+)
+
+// CopyHandler is the handler for the CopyCmd
+type CopyHandler struct {
+	svc *service.Provider
+}
+
+// NewCopyHandler creates a CopyHandler
+func NewCopyHandler(svc *service.Provider) CommandHandler {
+	return CopyHandler{svc: svc}
+}
+
+// Handle handles the CopyCmd
+func (h CopyHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	source, target, err := resolveSourceTarget(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+	if source.key() == target.key() {
+		h.svc.ChatService.UserNotificationThread(ctx, "the source and the target metadata are the same", cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	field, err := readServiceMetadata(ctx, h.svc, *source)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+	if field == nil {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", source.key()), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	if keys, ok := cmd.Options()[args.KeysName].([]string); ok && len(keys) > 0 {
+		var missing []string
+		if field, missing = metadata.Select(field, keys); len(missing) > 0 {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("%s doesn't have the metadata keys: %s", source.key(), strings.Join(missing, ", ")), cmd.Info().User, cmd.Info().Channel, timestamp)
+			return
+		}
+	}
+
+	confirmServiceMetadata(ctx, h.svc, cmd, timestamp, *target, field, source.String())
+}
+
+// resolveSourceTarget resolves the source (from) and target (to) service locations of the command
+func resolveSourceTarget(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand) (*metadataScope, *metadataScope, error) {
+	svcName := commands.ExtractStringOpt(params.ServiceName, cmd.Options())
+	source, err := resolveServiceScope(ctx, provider,
+		commands.ExtractStringOpt(commands.SourceNamespaceOpt, cmd.Options()),
+		commands.ExtractStringOpt(commands.SourceEnvironmentOpt, cmd.Options()),
+		svcName,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := resolveServiceScope(ctx, provider,
+		commands.ExtractStringOpt(params.NamespaceName, cmd.Options()),
+		commands.ExtractStringOpt(params.EnvironmentName, cmd.Options()),
+		svcName,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, target, nil
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
-	"github.com/unanet/eve-bot/internal/eveapi"
 	"github.com/unanet/eve/pkg/eve"
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
@@ -43,12 +42,13 @@ func (h DeleteHandler) deleteMetadata(ctx context.Context, cmd commands.EvebotCo
 		return
 	}
 
-	ns, svc := resolveServiceNamespace(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, ts)
-	if svc == nil || ns == nil {
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
 
-	mdKey := metaDataServiceKey(svc.Name, ns.Name)
+	mdKey := loc.key()
 	mdItem, err := h.svc.EveAPI.GetMetadata(ctx, mdKey)
 	if err != nil {
 		if resourceNotFoundError(err) {
@@ -59,7 +59,15 @@ func (h DeleteHandler) deleteMetadata(ctx context.Context, cmd commands.EvebotCo
 		return
 	}
 
+	if err = snapshotMetadata(ctx, h.svc, mdItem, "delete", cmd.Info().User); err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+
 	var md eve.Metadata
+	var deleted []string
+	// the keys deleted before a failure are published too
+	defer func() { publishMetadataChanged(ctx, h.svc, mdKey, "delete", cmd.Info().User, deleted) }()
 	for _, m := range opts[params.MetadataName].([]string) {
 		if isValidMetadata(m) {
 			md, err = h.svc.EveAPI.DeleteMetadataKey(ctx, mdItem.ID, m)
@@ -71,16 +79,17 @@ func (h DeleteHandler) deleteMetadata(ctx context.Context, cmd commands.EvebotCo
 				h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
 				return
 			}
+			deleted = append(deleted, strings.SplitN(m, "=", 2)[0])
 		} else {
 			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("invalid metadata key: %s", m), cmd.Info().User, cmd.Info().Channel, *ts)
 		}
 	}
 
-	h.svc.ChatService.ShowResultsMessageThread(ctx, eveapi.ChatMessage(md), cmd.Info().User, cmd.Info().Channel, *ts)
+	h.svc.ChatService.ShowResultsMessageThread(ctx, h.svc.Masker.ChatMessage(md), cmd.Info().User, cmd.Info().Channel, *ts)
 }
 
 func (h DeleteHandler) deleteVersion(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
-	ns, svc := resolveServiceNamespace(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, ts)
+	ns, svc := resolveServiceNamespace(ctx, h.svc, cmd, ts)
 	if svc == nil || ns == nil {
 		return
 	}
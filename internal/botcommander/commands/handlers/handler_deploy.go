@@ -31,7 +31,7 @@ func (h DeployHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, t
 
 	cmdAPIOpts := cmd.Options()
 
-	deployHandler(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, timestamp, eve.DeploymentPlanOptions{
+	deployHandler(ctx, h.svc, cmd, timestamp, eve.DeploymentPlanOptions{
 		Artifacts:        commands.ExtractArtifactsDefinition(args.ServicesName, cmdAPIOpts),
 		ForceDeploy:      commands.ExtractBoolOpt(args.ForceDeployName, cmdAPIOpts),
 		User:             chatUser.Name,
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/service"
+)
+
+// DiffHandler is the handler for the DiffCmd
+type DiffHandler struct {
+	svc *service.Provider
+}
+
+// NewDiffHandler creates a DiffHandler
+func NewDiffHandler(svc *service.Provider) CommandHandler {
+	return DiffHandler{svc: svc}
+}
+
+// Handle handles the DiffCmd
+func (h DiffHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	source, target, err := resolveSourceTarget(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	sourceField, err := readServiceMetadata(ctx, h.svc, *source)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+	targetField, err := readServiceMetadata(ctx, h.svc, *target)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+
+	// `-` is only in the source, `+` is only in the target and `~` is different
+	msg := fmt.Sprintf("*%s* → *%s*\n```%s```", source, target, maskChanges(h.svc.Masker, metadata.Diff(sourceField, targetField)))
+	h.svc.ChatService.ShowResultsMessageThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, timestamp)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/service"
+)
+
+// ExportHandler is the handler for the ExportCmd
+type ExportHandler struct {
+	svc *service.Provider
+}
+
+// NewExportHandler creates a ExportHandler
+func NewExportHandler(svc *service.Provider) CommandHandler {
+	return ExportHandler{svc: svc}
+}
+
+// Handle handles the ExportCmd
+func (h ExportHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	ns, svc := resolveServiceNamespace(ctx, h.svc, cmd, &timestamp)
+	if svc == nil || ns == nil {
+		return
+	}
+	loc := metadataScope{ns: *ns, svc: *svc}
+
+	field, err := readServiceMetadata(ctx, h.svc, loc)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+	if field == nil {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", loc.key()), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	format := commands.ExtractStringOpt(commands.ExportFormatOpt, cmd.Options())
+	// the file is shared in the channel, the secrets are masked (and skipped when the file is used to set the metadata)
+	content, err := metadata.Marshal(h.svc.Masker.Mask(field), format)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+	filename := fmt.Sprintf("%s-%s-metadata.%s", svc.Name, ns.Name, format)
+	h.svc.ChatService.UploadFileThread(ctx, filename, content, fmt.Sprintf("Here is the %s metadata...", loc.key()), cmd.Info().User, cmd.Info().Channel, timestamp)
+}
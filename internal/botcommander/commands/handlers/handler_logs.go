@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/kube"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// LogsHandler is the handler for the LogsCmd
+type LogsHandler struct {
+	svc *service.Provider
+}
+
+// NewLogsHandler creates a LogsHandler
+func NewLogsHandler(svc *service.Provider) CommandHandler {
+	return LogsHandler{svc: svc}
+}
+
+// Handle handles the LogsCmd, the logs of the pods are uploaded as a file in the thread (the secrets are masked)
+func (h LogsHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	if h.svc.Kubernetes == nil {
+		h.svc.ChatService.UserNotificationThread(ctx, errKubernetesDisabled.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+	ns, svc := resolveServiceNamespace(ctx, h.svc, cmd, &timestamp)
+	if svc == nil || ns == nil {
+		return
+	}
+
+	tail, ok := cmd.Options()[args.TailName].(int)
+	if !ok {
+		tail = int(args.DefaultTailArg())
+	}
+	since, _ := cmd.Options()[args.LogsSinceName].(time.Duration)
+	logs, err := h.svc.Kubernetes.Logs(ctx, *ns, svc.Name, kube.LogOptions{Tail: tail, Since: since})
+	if err != nil {
+		notifyKubernetesError(ctx, h.svc, cmd, timestamp, *ns, err)
+		return
+	}
+	if len(logs) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no pods for %s in %s", svc.Name, ns.Alias), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s.log", svc.Name, ns.Alias, ns.EnvironmentName)
+	h.svc.ChatService.UploadFileThread(ctx, filename, []byte(h.svc.Masker.MaskText(string(logs))),
+		fmt.Sprintf("Here are the logs of %s in %s (%s), the secrets are masked...", svc.Name, ns.Alias, ns.EnvironmentName),
+		cmd.Info().User, cmd.Info().Channel, timestamp)
+}
+
+// notifyKubernetesError notifies the user of a kubernetes failure (the unknown clusters aren't errors)
+func notifyKubernetesError(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand, ts string, ns eve.Namespace, err error) {
+	if goerrors.Is(err, kube.ErrUnknownCluster) {
+		provider.ChatService.UserNotificationThread(ctx, fmt.Sprintf("the cluster of %s (%s) isn't configured", ns.Alias, ns.EnvironmentName), cmd.Info().User, cmd.Info().Channel, ts)
+		return
+	}
+	provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ts, err)
+}
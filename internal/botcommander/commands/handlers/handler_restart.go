@@ -23,7 +23,7 @@ func NewRestartHandler(svc *service.Provider) CommandHandler {
 
 // Handle handles the RestartCmd
 func (h RestartHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
-	ns, svc := resolveServiceNamespace(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, &timestamp)
+	ns, svc := resolveServiceNamespace(ctx, h.svc, cmd, &timestamp)
 	if ns == nil || svc == nil {
 		h.svc.ChatService.UserNotificationThread(ctx, "failed to resolve the restart command service and namespace params", cmd.Info().User, cmd.Info().Channel, timestamp)
 		return
@@ -40,7 +40,7 @@ func (h RestartHandler) Handle(ctx context.Context, cmd commands.EvebotCommand,
 		return
 	}
 
-	deployHandler(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, timestamp, eve.DeploymentPlanOptions{
+	deployHandler(ctx, h.svc, cmd, timestamp, eve.DeploymentPlanOptions{
 		Artifacts: eve.ArtifactDefinitions{
 			&eve.ArtifactDefinition{
 				Name:             commands.ExtractStringOpt(params.ServiceName, cmd.Options()),
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// RestoreHandler is the handler for the RestoreCmd
+type RestoreHandler struct {
+	svc *service.Provider
+}
+
+// NewRestoreHandler creates a RestoreHandler
+func NewRestoreHandler(svc *service.Provider) CommandHandler {
+	return RestoreHandler{svc: svc}
+}
+
+// Handle handles the RestoreCmd
+func (h RestoreHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	revision, ok := cmd.Options()[commands.RevisionOpt].(int)
+	if !ok {
+		h.svc.ChatService.UserNotificationThread(ctx, "missing metadata revision", cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	rev, err := h.svc.MetadataHistory.ReadMetadataRevision(ctx, loc.key(), revision)
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("revision %d not found for: %s", revision, loc.key()), cmd.Info().User, cmd.Info().Channel, timestamp)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+
+	current, err := readServiceMetadata(ctx, h.svc, loc)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+
+	pending := loc.pending()
+	pending.Revision = rev
+	confirmMetadataChanges(ctx, h.svc, cmd, timestamp, loc, metadata.Diff(current, rev.Value), fmt.Sprintf("revision %d", revision), pending)
+}
@@ -47,7 +47,7 @@ func (h RunHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, time
 		aDefs = append(aDefs, aDef)
 	}
 
-	deployHandler(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, timestamp, eve.DeploymentPlanOptions{
+	deployHandler(ctx, h.svc, cmd, timestamp, eve.DeploymentPlanOptions{
 		Artifacts:        aDefs,
 		ForceDeploy:      true,
 		User:             chatUser.Name,
@@ -3,13 +3,17 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/unanet/eve-bot/internal/service"
 
+	"github.com/unanet/eve-bot/internal/botcommander/args"
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
 	"github.com/unanet/eve-bot/internal/eveapi"
 	"github.com/unanet/eve/pkg/eve"
 )
@@ -19,7 +23,7 @@ type SetHandler struct {
 	svc *service.Provider
 }
 
-// We use this stacking order as the default for all user (eve-bot) metadata
+// We use this stacking order as the default for the user (eve-bot) service metadata
 const stackingOrder = 400
 
 // NewSetHandler creates a SetHandler
@@ -29,7 +33,13 @@ func NewSetHandler(svc *service.Provider) CommandHandler {
 
 // Handle handles the SetCmd
 func (h SetHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
-	ns, err := resolveNamespace(ctx, h.svc.EveAPI, cmd)
+	// the metadata is set at the service, namespace or environment level (see resolveMetadataScope)
+	if cmd.Options()["resource"] == resources.MetadataName {
+		h.setMetadata(ctx, cmd, &timestamp)
+		return
+	}
+
+	ns, err := resolveNamespace(ctx, h.svc, cmd)
 	if err != nil {
 		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
 		return
@@ -59,53 +69,86 @@ func (h SetHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, time
 			return
 		}
 
-		switch cmd.Options()["resource"] {
-		case resources.MetadataName:
-			h.setSvcMetadata(ctx, cmd, &timestamp, svc)
-			return
-		case resources.VersionName:
+		if cmd.Options()["resource"] == resources.VersionName {
 			h.setSvcVersion(ctx, cmd, &timestamp, svc)
-			return
 		}
+		return
 	}
 
 	// setting the resource at the namespace level
 	switch cmd.Options()["resource"] {
-	case resources.MetadataName:
-		h.svc.ChatService.UserNotificationThread(ctx, "cannot set namespace metadata", cmd.Info().User, cmd.Info().Channel, timestamp)
 	case resources.VersionName:
 		h.setNamespaceVersion(ctx, cmd, &timestamp, ns)
 	}
 }
 
-func (h SetHandler) setSvcMetadata(ctx context.Context, cmd commands.EvebotCommand, ts *string, svc eve.Service) {
-	nv, err := resolveNamespace(ctx, h.svc.EveAPI, cmd)
+func (h SetHandler) setMetadata(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
 	if err != nil {
 		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
 
-	md, err := h.svc.EveAPI.UpsertMergeMetadata(ctx, eve.Metadata{
-		Description: metaDataServiceKey(svc.Name, nv.Name),
-		Value:       commands.ExtractMetadataField(cmd.Options()),
-	})
+	if file, ok := cmd.Options()[commands.FileOpt].(chatmodels.File); ok {
+		h.confirmSvcMetadataFile(ctx, cmd, ts, loc, file)
+		return
+	}
+
+	field := commands.ExtractMetadataField(cmd.Options())
+	if len(field) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("missing metadata, use `key=value` or attach a metadata file (%s)", strings.Join(metadata.SupportedExtensions, ", ")), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	stacking, _ := cmd.Options()[args.StackingName].(int)
+	md, err := upsertServiceMetadata(ctx, h.svc, cmd.Info().User, loc, field, stacking)
 	if err != nil {
-		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("failed to save metadata"))
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
 		return
 	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, h.svc.Masker.ChatMessage(md), cmd.Info().User, cmd.Info().Channel, *ts)
+}
 
-	_, err = h.svc.EveAPI.UpsertMetadataServiceMap(ctx, eve.MetadataServiceMap{
-		Description:   md.Description,
-		MetadataID:    md.ID,
-		ServiceID:     svc.ID,
-		StackingOrder: stackingOrder,
-	})
+// confirmSvcMetadataFile parses the metadata file and asks the user to confirm the changes before saving the metadata
+func (h SetHandler) confirmSvcMetadataFile(ctx context.Context, cmd commands.EvebotCommand, ts *string, loc metadataScope, file chatmodels.File) {
+	limits := metadata.Limits{MaxBytes: h.svc.Cfg.MetadataFileMaxBytes, MaxKeys: h.svc.Cfg.MetadataFileMaxKeys}
+	if !metadata.IsSupported(file.Name) {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("unsupported metadata file `%s`, expected one of: %s", file.Name, strings.Join(metadata.SupportedExtensions, ", ")), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+	if limits.MaxBytes > 0 && int64(file.Size) > limits.MaxBytes {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("metadata file `%s` is too large (%d bytes), the limit is %d bytes", file.Name, file.Size, limits.MaxBytes), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	content, err := h.svc.ChatService.DownloadFile(ctx, file.DownloadURL, limits.MaxBytes)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("failed to download the metadata file `%s`: %w", file.Name, err))
+		return
+	}
+	field, err := metadata.ParseFile(file.Name, content, limits)
 	if err != nil {
-		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("failed to save metadata"))
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	// the exported (masked) secrets are kept as they are
+	var masked []string
+	for k, v := range field {
+		if eveapi.IsMasked(v) {
+			masked = append(masked, k)
+			delete(field, k)
+		}
+	}
+	if len(masked) > 0 {
+		sort.Strings(masked)
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("skipping the masked metadata keys: %s", strings.Join(masked, ", ")), cmd.Info().User, cmd.Info().Channel, *ts)
+	}
+	if len(field) == 0 {
 		return
 	}
 
-	h.svc.ChatService.ShowResultsMessageThread(ctx, eveapi.ChatMessage(md), cmd.Info().User, cmd.Info().Channel, *ts)
+	confirmServiceMetadata(ctx, h.svc, cmd, *ts, loc, field, fmt.Sprintf("`%s`", file.Name))
 }
 
 func (h SetHandler) setSvcVersion(ctx context.Context, cmd commands.EvebotCommand, ts *string, svc eve.Service) {
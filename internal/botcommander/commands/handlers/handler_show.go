@@ -2,17 +2,30 @@ package handlers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"time"
 
 	"github.com/unanet/eve-bot/internal/service"
 
+	"github.com/unanet/eve-bot/internal/botcommander/args"
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/deployments"
 	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/kube"
+	"github.com/unanet/eve-bot/internal/subscriptions"
 	"github.com/unanet/go/pkg/errors"
 )
 
+// metadataHistoryLimit is the number of revisions shown by `show metadata history`
+const metadataHistoryLimit = 10
+
+// deploymentHistoryPageSize is the number of deployments per page of `show deployments`
+const deploymentHistoryPageSize = 10
+
 // ShowHandler is the handler for the ShowCmd
 type ShowHandler struct {
 	svc *service.Provider
@@ -34,7 +47,23 @@ func (h ShowHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, tim
 		h.showNamespaces(ctx, cmd, &timestamp)
 	case resources.ServiceName:
 		h.showServices(ctx, cmd, &timestamp)
+	case resources.DeploymentName:
+		h.showDeployments(ctx, cmd, &timestamp)
+	case commands.ShowDeploymentOpt:
+		h.showDeployment(ctx, cmd, &timestamp)
+	case resources.SubscriptionName:
+		h.showSubscriptions(ctx, cmd, &timestamp)
+	case resources.PodName:
+		h.showPods(ctx, cmd, &timestamp)
 	case resources.MetadataName:
+		if history, ok := cmd.Options()[commands.ShowHistoryOpt].(bool); ok && history {
+			h.showMetadataHistory(ctx, cmd, &timestamp)
+			return
+		}
+		if effective, ok := cmd.Options()[commands.ShowEffectiveOpt].(bool); ok && effective {
+			h.showEffectiveMetadata(ctx, cmd, &timestamp)
+			return
+		}
 		h.showMetadata(ctx, cmd, &timestamp)
 	default:
 		h.svc.ChatService.UserNotificationThread(ctx, "invalid show command", cmd.Info().User, cmd.Info().Channel, timestamp)
@@ -68,7 +97,11 @@ func (h ShowHandler) showNamespaces(ctx context.Context, cmd commands.EvebotComm
 		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
 		return
 	}
-	if ns == nil {
+	if len(ns) == 0 {
+		if msg, unknown := unknownEnvironment(ctx, h.svc, cmd.Options()[params.EnvironmentName].(string)); unknown {
+			h.svc.ChatService.UserNotificationThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
 		h.svc.ChatService.UserNotificationThread(ctx, "no namespaces", cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
@@ -76,9 +109,9 @@ func (h ShowHandler) showNamespaces(ctx context.Context, cmd commands.EvebotComm
 }
 
 func (h ShowHandler) showJobs(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
-	ns, err := resolveNamespace(ctx, h.svc.EveAPI, cmd)
+	ns, err := resolveNamespace(ctx, h.svc, cmd)
 	if err != nil {
-		h.svc.ChatService.UserNotificationThread(ctx, "invalid environment namespace request", cmd.Info().User, cmd.Info().Channel, *ts)
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
 	nsJobs, err := h.svc.EveAPI.GetNamespaceJobs(ctx, &ns)
@@ -97,7 +130,7 @@ func (h ShowHandler) showJobs(ctx context.Context, cmd commands.EvebotCommand, t
 }
 
 func (h ShowHandler) showServices(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
-	nv, err := resolveNamespace(ctx, h.svc.EveAPI, cmd)
+	nv, err := resolveNamespace(ctx, h.svc, cmd)
 	if err != nil {
 		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
@@ -127,19 +160,217 @@ func resourceNotFoundError(err error) bool {
 	return false
 }
 
+// showMetadata shows the metadata of the scope, a service shows every metadata layer (eve-bot or not)
+// that applies to it and the effective (merged) metadata
 func (h ShowHandler) showMetadata(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
-	ns, svc := resolveServiceNamespace(ctx, h.svc.EveAPI, h.svc.ChatService, cmd, ts)
-	if svc == nil || ns == nil {
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	var msg, unmasked string
+	if loc.isService() {
+		layers, err := serviceMetadataLayers(ctx, h.svc, loc.svc)
+		if err != nil {
+			h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+			return
+		}
+		if len(layers) == 0 {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", loc), cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		msg, unmasked = metadataLayersMsg(layers, h.svc.Masker.ChatMessage), metadataLayersMsg(layers, eveapi.UnmaskedChatMessage)
+	} else {
+		md, err := h.svc.EveAPI.GetMetadata(ctx, loc.key())
+		if err != nil {
+			if resourceNotFoundError(err) {
+				h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", loc.key()), cmd.Info().User, cmd.Info().Channel, *ts)
+				return
+			}
+			h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+			return
+		}
+		note := loc.inheritance()
+		msg = fmt.Sprintf("%s\n%s", h.svc.Masker.ChatMessage(md), note)
+		unmasked = fmt.Sprintf("%s\n%s", eveapi.UnmaskedChatMessage(md), note)
+	}
+
+	h.svc.ChatService.ShowResultsMessageThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, *ts)
+
+	// the reveal is authorized with its own role (see service.requestedRole)
+	if reveal, ok := cmd.Options()[args.RevealName].(bool); ok && reveal {
+		h.svc.ChatService.PostPrivateMessage(ctx, fmt.Sprintf("*%s*\n%s", loc, unmasked), cmd.Info().User)
+		h.svc.ChatService.UserNotificationThread(ctx, "the unmasked metadata was sent to you in a private message", cmd.Info().User, cmd.Info().Channel, *ts)
+	}
+}
+
+// showEffectiveMetadata shows the metadata the service receives (every layer merged by stacking order)
+// with the layer that set each value
+func (h ShowHandler) showEffectiveMetadata(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+	if !loc.isService() {
+		h.svc.ChatService.UserNotificationThread(ctx, "the effective metadata requires a service (`for {{ service }}`)", cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	layers, err := serviceMetadataLayers(ctx, h.svc, loc.svc)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	if len(layers) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", loc), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
 
-	mdKey := metaDataServiceKey(svc.Name, ns.Name)
+	header := fmt.Sprintf("*%s* effective metadata (`key: value ← metadata (stacking order)`, the highest stacking order wins):\n", loc)
+	h.svc.ChatService.ShowResultsMessageThread(ctx, header+effectiveMetadataMsg(h.svc.Masker, layers, false), cmd.Info().User, cmd.Info().Channel, *ts)
+
+	// the reveal is authorized with its own role (see service.requestedRole)
+	if reveal, ok := cmd.Options()[args.RevealName].(bool); ok && reveal {
+		h.svc.ChatService.PostPrivateMessage(ctx, header+effectiveMetadataMsg(h.svc.Masker, layers, true), cmd.Info().User)
+		h.svc.ChatService.UserNotificationThread(ctx, "the unmasked metadata was sent to you in a private message", cmd.Info().User, cmd.Info().Channel, *ts)
+	}
+}
 
-	metadata, err := h.svc.EveAPI.GetMetadata(ctx, mdKey)
+func (h ShowHandler) showMetadataHistory(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	loc, err := resolveMetadataScope(ctx, h.svc, cmd)
 	if err != nil {
-		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata found for: %s", mdKey), cmd.Info().User, cmd.Info().Channel, *ts)
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return
 	}
 
-	h.svc.ChatService.ShowResultsMessageThread(ctx, eveapi.ChatMessage(metadata), cmd.Info().User, cmd.Info().Channel, *ts)
+	revs, err := h.svc.MetadataHistory.ListMetadataRevisions(ctx, loc.key(), metadataHistoryLimit)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	if len(revs) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no metadata history found for: %s", loc.key()), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	current, err := readServiceMetadata(ctx, h.svc, loc)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	msg := fmt.Sprintf("*%s* revisions (the metadata before each change, use `restore metadata ... to {{ revision }}`):\n%s", loc.key(), metadataHistoryMsg(h.svc.Masker, revs, current))
+	h.svc.ChatService.ShowResultsMessageThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// showDeployments shows the deployment history of the namespace (the most recent first, a page at a time)
+func (h ShowHandler) showDeployments(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	ns, err := resolveNamespace(ctx, h.svc, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	// the deployments are recorded with the requested environment (its name or its alias)
+	env, err := lookupEnvironment(ctx, h.svc, ns.EnvironmentName)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+
+	since, ok := cmd.Options()[commands.SinceOpt].(time.Duration)
+	if !ok {
+		since = commands.DefaultSince
+	}
+	page, ok := cmd.Options()[args.PageName].(int)
+	if !ok {
+		page = int(args.DefaultPageArg())
+	}
+	filter := datastore.DeploymentFilter{
+		Since:        time.Now().Add(-since),
+		Namespace:    ns.Alias,
+		Environments: []string{env.Name, env.Alias, commands.ExtractStringOpt(params.EnvironmentName, cmd.Options())},
+		Service:      commands.ExtractStringOpt(args.ServiceFilterName, cmd.Options()),
+	}
+	history, err := h.svc.Deployments.ListDeployments(ctx, filter)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	if len(history) == 0 {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no deployments found for: %s since %s", ns.Alias, since), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+
+	pages := (len(history) + deploymentHistoryPageSize - 1) / deploymentHistoryPageSize
+	if page > pages {
+		h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("there are only %d pages of deployments", pages), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+	end := page * deploymentHistoryPageSize
+	if end > len(history) {
+		end = len(history)
+	}
+	msg := fmt.Sprintf("*%s* deployments since %s (page %d of %d, use `show deployment {{ id }}` for the details):\n%s",
+		ns.Alias, filter.Since.UTC().Format("2006-01-02 15:04"), page, pages, deployments.ListMessage(history[(page-1)*deploymentHistoryPageSize:end]))
+	h.svc.ChatService.ShowResultsMessageThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// showDeployment shows the deployment request and the callback of each namespace deployment plan
+func (h ShowHandler) showDeployment(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	id := commands.ExtractStringOpt(params.DeploymentName, cmd.Options())
+	deployment, err := h.svc.Deployments.ReadDeployment(ctx, id)
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			h.svc.ChatService.UserNotificationThread(ctx, fmt.Sprintf("no deployment found for: %s", id), cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, deployments.Message(deployment), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// showSubscriptions shows the deployment subscriptions of the user
+func (h ShowHandler) showSubscriptions(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	subs, err := h.svc.Subscriptions.ListSubscriptions(ctx, cmd.Info().User)
+	if err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, subscriptions.ListMessage(subs), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// showPods shows the status of the pods of the service
+func (h ShowHandler) showPods(ctx context.Context, cmd commands.EvebotCommand, ts *string) {
+	if h.svc.Kubernetes == nil {
+		h.svc.ChatService.UserNotificationThread(ctx, errKubernetesDisabled.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		return
+	}
+	ns, svc := resolveServiceNamespace(ctx, h.svc, cmd, ts)
+	if svc == nil || ns == nil {
+		return
+	}
+	pods, err := h.svc.Kubernetes.Pods(ctx, *ns, svc.Name)
+	if err != nil {
+		notifyKubernetesError(ctx, h.svc, cmd, *ts, *ns, err)
+		return
+	}
+	h.svc.ChatService.ShowResultsMessageThread(ctx, podsMsg(pods), cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+func podsMsg(v []kube.Pod) string {
+	if len(v) == 0 {
+		return "no pods"
+	}
+	msg := ""
+	for _, p := range v {
+		status := p.Phase
+		if len(p.Reason) > 0 {
+			status = p.Reason
+		}
+		msg += fmt.Sprintf("`%s` *%s* ready %s, %d restarts, age %s ( _%s_ )\n", p.Name, status, p.Ready, p.Restarts, p.Age, p.Version)
+	}
+	return msg
 }
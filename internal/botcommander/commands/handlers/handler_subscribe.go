@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/eve-bot/internal/subscriptions"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// SubscribeHandler is the handler for the SubscribeCmd (and the UnsubscribeCmd)
+type SubscribeHandler struct {
+	svc *service.Provider
+}
+
+// NewSubscribeHandler creates a SubscribeHandler
+func NewSubscribeHandler(svc *service.Provider) CommandHandler {
+	return SubscribeHandler{svc: svc}
+}
+
+// Handle handles the SubscribeCmd and the UnsubscribeCmd
+func (h SubscribeHandler) Handle(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	sub, err := h.subscription(ctx, cmd)
+	if err != nil {
+		h.svc.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
+		return
+	}
+
+	if cmd.Info().CommandName == commands.UnsubscribeCmdName {
+		h.unsubscribe(ctx, cmd, sub, &timestamp)
+		return
+	}
+	if err := h.svc.Subscriptions.SaveSubscription(ctx, sub); err != nil {
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx,
+		fmt.Sprintf("subscribed to the deployments %s (%s)", subscriptions.Scope(sub), subscriptions.Delivery(sub)),
+		cmd.Info().User, cmd.Info().Channel, timestamp)
+}
+
+func (h SubscribeHandler) unsubscribe(ctx context.Context, cmd commands.EvebotCommand, sub datastore.Subscription, ts *string) {
+	if err := h.svc.Subscriptions.DeleteSubscription(ctx, sub.Owner, sub.Key); err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			h.svc.ChatService.UserNotificationThread(ctx,
+				fmt.Sprintf("no subscription to the deployments %s (%s), see `show subscriptions`", subscriptions.Scope(sub), subscriptions.Delivery(sub)),
+				cmd.Info().User, cmd.Info().Channel, *ts)
+			return
+		}
+		h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		return
+	}
+	h.svc.ChatService.UserNotificationThread(ctx,
+		fmt.Sprintf("unsubscribed from the deployments %s (%s)", subscriptions.Scope(sub), subscriptions.Delivery(sub)),
+		cmd.Info().User, cmd.Info().Channel, *ts)
+}
+
+// subscription resolves the namespace alias and the environment name of the command (the deployment results have the canonical names)
+func (h SubscribeHandler) subscription(ctx context.Context, cmd commands.EvebotCommand) (datastore.Subscription, error) {
+	sub := datastore.Subscription{
+		Owner:   cmd.Info().User,
+		Service: commands.ExtractStringOpt(args.ServiceFilterName, cmd.Options()),
+	}
+	if channel, ok := cmd.Options()[commands.SubscribeChannelOpt].(bool); ok && channel {
+		sub.Channel = cmd.Info().Channel
+	}
+
+	environment := commands.ExtractStringOpt(params.EnvironmentName, cmd.Options())
+	namespace := commands.ExtractStringOpt(params.NamespaceName, cmd.Options())
+	switch {
+	case len(namespace) > 0:
+		ns, err := lookupNamespace(ctx, h.svc, namespace, environment)
+		if err != nil {
+			return sub, err
+		}
+		sub.Namespace, sub.Environment = ns.Alias, ns.EnvironmentName
+	case len(environment) > 0:
+		env, err := lookupEnvironment(ctx, h.svc, environment)
+		if err != nil {
+			return sub, err
+		}
+		sub.Environment = env.Name
+	}
+	sub.Key = datastore.SubscriptionKey(sub.Channel, sub.Namespace, sub.Environment, sub.Service)
+	return sub, nil
+}
@@ -2,33 +2,69 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/botcommander/suggest"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
 
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
 	"github.com/unanet/eve/pkg/eve"
 )
 
+// deployHandler records the deployment (the callbacks are correlated by its id) and calls the eve-api
 func deployHandler(
 	ctx context.Context,
-	eveAPIClient interfaces.EveAPI,
-	chatSvc interfaces.ChatProvider,
+	provider *service.Provider,
 	cmd commands.EvebotCommand,
 	timestamp string,
 	deployOpts eve.DeploymentPlanOptions) {
 
-	resp, err := eveAPIClient.Deploy(ctx, deployOpts, cmd.Info().User, cmd.Info().Channel, timestamp)
+	chatSvc := provider.ChatService
+	deployment := datastore.Deployment{
+		ID:      datastore.NewDeploymentID(),
+		Command: cmd.Info().CommandName,
+		Plan:    deployOpts,
+		User:    cmd.Info().User,
+		Channel: cmd.Info().Channel,
+		TS:      timestamp,
+	}
+	if deadline := provider.Cfg.DeploymentDeadline(deployOpts.Type); deadline > 0 {
+		deployment.Deadline = time.Now().Add(deadline)
+	}
+	if err := provider.Deployments.SaveDeployment(ctx, deployment); err != nil {
+		chatSvc.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, err)
+		return
+	}
+
+	resp, err := provider.EveAPI.Deploy(ctx, deployOpts, deployment.ID)
 	if err != nil && len(err.Error()) > 0 {
+		// there won't be any callback
+		deployment.Status = eve.DeploymentPlanStatusErrors
+		_ = provider.Deployments.SaveDeployment(ctx, deployment)
 		chatSvc.DeploymentNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, timestamp)
 		return
 	}
 	if resp == nil {
+		deployment.Status = eve.DeploymentPlanStatusErrors
+		_ = provider.Deployments.SaveDeployment(ctx, deployment)
 		chatSvc.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, errInvalidAPIResp)
 		return
 	}
+	// the plan ids are written even when the eve-api didn't return any (the callbacks before it keep the deployment in the sweep index)
+	planIDs := make([]string, 0, len(resp.DeploymentIDs))
+	for _, id := range resp.DeploymentIDs {
+		planIDs = append(planIDs, id.String())
+	}
+	if err := provider.Deployments.UpdateDeploymentPlanIDs(ctx, deployment.ID, planIDs); err != nil {
+		log.Logger.Error("failed to track the deployment plans", zap.Error(err), zap.String("deployment", deployment.ID))
+	}
 	if len(resp.Messages) > 0 {
 		chatSvc.UserNotificationThread(ctx, strings.Join(resp.Messages, ","), cmd.Info().User, cmd.Info().Channel, timestamp)
 	}
@@ -36,8 +72,7 @@ func deployHandler(
 
 func resolveServiceNamespace(
 	ctx context.Context,
-	eveAPIClient interfaces.EveAPI,
-	chatSvc interfaces.ChatProvider,
+	provider *service.Provider,
 	cmd commands.EvebotCommand, ts *string) (*eve.Namespace, *eve.Service) {
 
 	var ns eve.Namespace
@@ -45,34 +80,36 @@ func resolveServiceNamespace(
 	var svcs []eve.Service
 	var err error
 
-	ns, err = resolveNamespace(ctx, eveAPIClient, cmd)
+	ns, err = resolveNamespace(ctx, provider, cmd)
 	if err != nil {
-		chatSvc.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
+		provider.ChatService.UserNotificationThread(ctx, err.Error(), cmd.Info().User, cmd.Info().Channel, *ts)
 		return nil, nil
 	}
-	svcs, err = eveAPIClient.GetServicesByNamespace(ctx, ns.Name)
+	svcs, err = provider.EveAPI.GetServicesByNamespace(ctx, ns.Name)
 	if err != nil {
-		chatSvc.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
+		provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, err)
 		return nil, nil
 	}
 	if svcs == nil {
-		chatSvc.UserNotificationThread(ctx, "no services", cmd.Info().User, cmd.Info().Channel, *ts)
+		provider.ChatService.UserNotificationThread(ctx, "no services", cmd.Info().User, cmd.Info().Channel, *ts)
 		return nil, nil
 	}
 	var requestedSvcName string
 	var valid bool
 	if requestedSvcName, valid = cmd.Options()[params.ServiceName].(string); !valid {
-		chatSvc.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("invalid ServiceName Param"))
+		provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, *ts, fmt.Errorf("invalid ServiceName Param"))
 		return nil, nil
 	}
+	var svcNames []string
 	for _, s := range svcs {
 		if strings.EqualFold(s.Name, requestedSvcName) {
 			svc = s
 			break
 		}
+		svcNames = append(svcNames, s.Name)
 	}
 	if svc.ID == 0 {
-		chatSvc.UserNotificationThread(ctx, fmt.Sprintf("invalid requested service: %s", requestedSvcName), cmd.Info().User, cmd.Info().Channel, *ts)
+		provider.ChatService.UserNotificationThread(ctx, suggest.Unknown("service", requestedSvcName, svcNames), cmd.Info().User, cmd.Info().Channel, *ts)
 		return nil, nil
 	}
 	return &ns, &svc
@@ -82,26 +119,96 @@ func metaDataServiceKey(service, namespace string) string {
 	return fmt.Sprintf("eve-bot:%s:%s", service, namespace)
 }
 
-func resolveNamespace(ctx context.Context, api interfaces.EveAPI, cmd commands.EvebotCommand) (eve.Namespace, error) {
-	var nv eve.Namespace
-
+func resolveNamespace(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand) (eve.Namespace, error) {
 	dynamicOpts := cmd.Options()
+	environment := dynamicOpts[params.EnvironmentName].(string)
+	namespace := dynamicOpts[params.NamespaceName].(string)
+	return lookupNamespace(ctx, provider, namespace, environment)
+}
+
+// lookupNamespace returns the namespace (by alias) in the environment
+func lookupNamespace(ctx context.Context, provider *service.Provider, namespace, environment string) (eve.Namespace, error) {
+	var nv eve.Namespace
 
 	// Gotta get the namespaces first, since we are working with the Alias, and not the Name/ID
-	namespaces, err := api.GetNamespacesByEnvironment(ctx, dynamicOpts[params.EnvironmentName].(string))
+	namespaces, err := provider.EveAPI.GetNamespacesByEnvironment(ctx, environment)
 	if err != nil {
 		return nv, err
 	}
 
+	var aliases []string
 	for _, v := range namespaces {
-		if strings.EqualFold(v.Alias, dynamicOpts[params.NamespaceName].(string)) {
+		if strings.EqualFold(v.Alias, namespace) {
 			nv = v
 			break
 		}
+		aliases = append(aliases, v.Alias)
 	}
 
 	if nv.ID == 0 {
-		return nv, fmt.Errorf("invalid namespace")
+		// an environment without any namespaces is most likely a typo
+		if len(namespaces) == 0 {
+			if msg, unknown := unknownEnvironment(ctx, provider, environment); unknown {
+				return nv, errors.New(msg)
+			}
+		}
+		return nv, errors.New(suggest.Unknown("namespace", namespace, aliases))
 	}
 	return nv, nil
 }
+
+// lookupService returns the service (by name) in the namespace
+func lookupService(ctx context.Context, provider *service.Provider, ns eve.Namespace, name string) (eve.Service, error) {
+	svcs, err := provider.EveAPI.GetServicesByNamespace(ctx, ns.Name)
+	if err != nil {
+		return eve.Service{}, err
+	}
+	var svcNames []string
+	for _, s := range svcs {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+		svcNames = append(svcNames, s.Name)
+	}
+	return eve.Service{}, errors.New(suggest.Unknown("service", name, svcNames))
+}
+
+// lookupEnvironment returns the environment (by name or alias)
+func lookupEnvironment(ctx context.Context, provider *service.Provider, environment string) (eve.Environment, error) {
+	envs, err := provider.EveAPI.GetEnvironments(ctx)
+	if err != nil {
+		return eve.Environment{}, err
+	}
+	var names []string
+	for _, e := range envs {
+		if strings.EqualFold(e.Name, environment) || (len(e.Alias) > 0 && strings.EqualFold(e.Alias, environment)) {
+			return e, nil
+		}
+		names = append(names, e.Name)
+	}
+	return eve.Environment{}, errors.New(suggest.Unknown("environment", environment, names))
+}
+
+// unknownEnvironment returns the "unknown environment" message (with the closest suggestion)
+// when the environment doesn't match any environment name/alias
+func unknownEnvironment(ctx context.Context, provider *service.Provider, environment string) (string, bool) {
+	// the environments are cached by the eve-api client (see eveapi.CachedClient)
+	envs, err := provider.EveAPI.GetEnvironments(ctx)
+	if err != nil {
+		log.Logger.Warn("failed to get the environment suggestions", zap.Error(err))
+		return "", false
+	}
+	var candidates []string
+	for _, e := range envs {
+		candidates = append(candidates, e.Name)
+		if len(e.Alias) > 0 {
+			candidates = append(candidates, e.Alias)
+		}
+	}
+	for _, c := range candidates {
+		if strings.EqualFold(c, environment) {
+			return "", false
+		}
+	}
+	return suggest.Unknown("environment", environment, candidates), true
+}
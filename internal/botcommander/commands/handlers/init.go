@@ -16,14 +16,23 @@ type factory struct {
 func NewFactory() Factory {
 	return &factory{
 		Map: map[string]func(svc *service.Provider) CommandHandler{
-			commands.DeployCmdName:  NewDeployHandler,
-			commands.ShowCmdName:    NewShowHandler,
-			commands.SetCmdName:     NewSetHandler,
-			commands.DeleteCmdName:  NewDeleteHandler,
-			commands.ReleaseCmdName: NewReleaseHandler,
-			commands.RestartCmdName: NewRestartHandler,
-			commands.RunCmdName:     NewRunHandler,
-			commands.AuthCmdName:    NewAuthHandler,
+			commands.DeployCmdName:      NewDeployHandler,
+			commands.ShowCmdName:        NewShowHandler,
+			commands.SetCmdName:         NewSetHandler,
+			commands.DeleteCmdName:      NewDeleteHandler,
+			commands.ReleaseCmdName:     NewReleaseHandler,
+			commands.RestartCmdName:     NewRestartHandler,
+			commands.RunCmdName:         NewRunHandler,
+			commands.AuthCmdName:        NewAuthHandler,
+			commands.AliasCmdName:       NewAliasHandler,
+			commands.ContextCmdName:     NewContextHandler,
+			commands.ExportCmdName:      NewExportHandler,
+			commands.CopyCmdName:        NewCopyHandler,
+			commands.DiffCmdName:        NewDiffHandler,
+			commands.RestoreCmdName:     NewRestoreHandler,
+			commands.SubscribeCmdName:   NewSubscribeHandler,
+			commands.UnsubscribeCmdName: NewSubscribeHandler,
+			commands.LogsCmdName:        NewLogsHandler,
 		},
 	}
 }
@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/botcommander/confirm"
+	"github.com/unanet/eve-bot/internal/botcommander/metadata"
+	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/service"
+	"github.com/unanet/eve-bot/internal/webhooks"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// the default stacking orders of the eve-bot metadata (the service metadata overrides the namespace metadata
+// which overrides the environment metadata)
+const (
+	environmentStackingOrder = 200
+	namespaceStackingOrder   = 300
+)
+
+// metadataScope is the owner of an eve-bot metadata record:
+// a service in a namespace, a namespace (svc is the zero value) or an environment (svc and ns are the zero value)
+type metadataScope struct {
+	env eve.Environment
+	ns  eve.Namespace
+	svc eve.Service
+}
+
+// resolveServiceScope resolves the service in the namespace (alias) and environment
+func resolveServiceScope(ctx context.Context, provider *service.Provider, namespace, environment, svcName string) (*metadataScope, error) {
+	ns, err := lookupNamespace(ctx, provider, namespace, environment)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := lookupService(ctx, provider, ns, svcName)
+	if err != nil {
+		return nil, err
+	}
+	return &metadataScope{env: namespaceEnvironment(ns), ns: ns, svc: svc}, nil
+}
+
+// resolveMetadataScope resolves the metadata scope of the command
+// (for {{ service }} in {{ namespace }} {{ environment }}, in {{ namespace }} {{ environment }} or across {{ environment }})
+func resolveMetadataScope(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand) (metadataScope, error) {
+	environment := commands.ExtractStringOpt(params.EnvironmentName, cmd.Options())
+	namespace := commands.ExtractStringOpt(params.NamespaceName, cmd.Options())
+	svcName := commands.ExtractStringOpt(params.ServiceName, cmd.Options())
+
+	if len(namespace) == 0 {
+		env, err := lookupEnvironment(ctx, provider, environment)
+		if err != nil {
+			return metadataScope{}, err
+		}
+		return metadataScope{env: env}, nil
+	}
+	if len(svcName) > 0 {
+		l, err := resolveServiceScope(ctx, provider, namespace, environment, svcName)
+		if err != nil {
+			return metadataScope{}, err
+		}
+		return *l, nil
+	}
+	ns, err := lookupNamespace(ctx, provider, namespace, environment)
+	if err != nil {
+		return metadataScope{}, err
+	}
+	return metadataScope{env: namespaceEnvironment(ns), ns: ns}, nil
+}
+
+func namespaceEnvironment(ns eve.Namespace) eve.Environment {
+	return eve.Environment{ID: ns.EnvironmentID, Name: ns.EnvironmentName}
+}
+
+func (l metadataScope) isService() bool {
+	return l.svc.ID > 0
+}
+
+func (l metadataScope) isNamespace() bool {
+	return l.svc.ID == 0 && l.ns.ID > 0
+}
+
+// key is the eve-bot metadata key of the scope
+func (l metadataScope) key() string {
+	switch {
+	case l.isService():
+		return metaDataServiceKey(l.svc.Name, l.ns.Name)
+	case l.isNamespace():
+		return fmt.Sprintf("eve-bot-namespace:%s", l.ns.Name)
+	default:
+		return fmt.Sprintf("eve-bot-environment:%s", l.env.Name)
+	}
+}
+
+func (l metadataScope) String() string {
+	switch {
+	case l.isService():
+		return fmt.Sprintf("%s in %s %s", l.svc.Name, l.ns.Alias, l.ns.EnvironmentName)
+	case l.isNamespace():
+		return fmt.Sprintf("%s %s", l.ns.Alias, l.ns.EnvironmentName)
+	default:
+		return l.env.Name
+	}
+}
+
+// inheritance notes that the services of a namespace or an environment inherit its metadata through the eve stacking
+// (empty for a service)
+func (l metadataScope) inheritance() string {
+	switch {
+	case l.isService():
+		return ""
+	case l.isNamespace():
+		return fmt.Sprintf("_every service in %s inherits these values (stacking order %d by default), the service metadata (%d) overrides them_",
+			l, namespaceStackingOrder, stackingOrder)
+	default:
+		return fmt.Sprintf("_every service across %s inherits these values (stacking order %d by default), the namespace (%d) and service (%d) metadata override them_",
+			l, environmentStackingOrder, namespaceStackingOrder, stackingOrder)
+	}
+}
+
+// serviceMap maps the metadata to the service, the namespace or the environment
+// (eve applies the namespace/environment maps to every service in the namespace/environment)
+func (l metadataScope) serviceMap(md eve.Metadata, stacking int) eve.MetadataServiceMap {
+	m := eve.MetadataServiceMap{
+		Description:   md.Description,
+		MetadataID:    md.ID,
+		StackingOrder: stacking,
+	}
+	switch {
+	case l.isService():
+		m.ServiceID = l.svc.ID
+	case l.isNamespace():
+		m.NamespaceID = l.ns.ID
+	default:
+		m.EnvironmentID = l.env.ID
+	}
+	return m
+}
+
+// stackingOrder returns the stacking order argument, the stacking order of the existing map
+// or the default stacking order of the scope
+func (l metadataScope) stackingOrder(ctx context.Context, provider *service.Provider, md eve.Metadata, stacking int) int {
+	if stacking > 0 {
+		return stacking
+	}
+	maps, err := provider.EveAPI.GetMetadataServiceMaps(ctx, md.ID)
+	if err == nil {
+		for _, m := range maps {
+			if m.Description == md.Description && m.StackingOrder > 0 {
+				return m.StackingOrder
+			}
+		}
+	}
+	switch {
+	case l.isService():
+		return stackingOrder
+	case l.isNamespace():
+		return namespaceStackingOrder
+	default:
+		return environmentStackingOrder
+	}
+}
+
+// readMetadata returns the eve-bot metadata of the scope (the zero value when the scope doesn't have any)
+func readMetadata(ctx context.Context, provider *service.Provider, l metadataScope) (eve.Metadata, error) {
+	md, err := provider.EveAPI.GetMetadata(ctx, l.key())
+	if err != nil {
+		if resourceNotFoundError(err) {
+			return eve.Metadata{}, nil
+		}
+		return eve.Metadata{}, err
+	}
+	return md, nil
+}
+
+// readServiceMetadata returns the eve-bot metadata value of the scope (nil when the scope doesn't have any)
+func readServiceMetadata(ctx context.Context, provider *service.Provider, l metadataScope) (eve.MetadataField, error) {
+	md, err := readMetadata(ctx, provider, l)
+	if err != nil {
+		return nil, err
+	}
+	return md.Value, nil
+}
+
+// snapshotMetadata saves the metadata as a revision in the history before it is changed by the action
+func snapshotMetadata(ctx context.Context, provider *service.Provider, md eve.Metadata, action, user string) error {
+	if provider.MetadataHistory == nil || md.ID == 0 {
+		return nil
+	}
+	_, err := provider.MetadataHistory.SaveMetadataRevision(ctx, datastore.MetadataRevision{
+		Key:        md.Description,
+		MetadataID: md.ID,
+		Value:      md.Value,
+		Action:     action,
+		CreatedBy:  user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save the metadata history")
+	}
+	return nil
+}
+
+// upsertServiceMetadata snapshots the eve-bot metadata of the scope and merges the metadata value into it
+// (stacking is the stacking order argument, 0 keeps the current stacking order)
+func upsertServiceMetadata(ctx context.Context, provider *service.Provider, user string, l metadataScope, field eve.MetadataField, stacking int) (eve.Metadata, error) {
+	current, err := readMetadata(ctx, provider, l)
+	if err != nil {
+		return current, err
+	}
+	if err = snapshotMetadata(ctx, provider, current, "set", user); err != nil {
+		return current, err
+	}
+	md, err := mergeServiceMetadata(ctx, provider, l, field, stacking)
+	if err != nil {
+		return md, err
+	}
+	keys := make([]string, 0, len(field))
+	for k := range field {
+		keys = append(keys, k)
+	}
+	publishMetadataChanged(ctx, provider, l.key(), "set", user, keys)
+	return md, nil
+}
+
+// mergeServiceMetadata merges the metadata value into the eve-bot metadata of the scope
+// and maps the metadata to the service, the namespace or the environment
+func mergeServiceMetadata(ctx context.Context, provider *service.Provider, l metadataScope, field eve.MetadataField, stacking int) (eve.Metadata, error) {
+	md, err := provider.EveAPI.UpsertMergeMetadata(ctx, eve.Metadata{
+		Description: l.key(),
+		Value:       field,
+	})
+	if err != nil {
+		return md, fmt.Errorf("failed to save metadata")
+	}
+
+	_, err = provider.EveAPI.UpsertMetadataServiceMap(ctx, l.serviceMap(md, l.stackingOrder(ctx, provider, md, stacking)))
+	if err != nil {
+		return md, fmt.Errorf("failed to save metadata")
+	}
+	return md, nil
+}
+
+// restoreServiceMetadata snapshots the eve-bot metadata of the scope and restores the revision value
+// (the changed keys are merged and the keys that aren't in the revision are deleted)
+func restoreServiceMetadata(ctx context.Context, provider *service.Provider, user string, l metadataScope, rev datastore.MetadataRevision) (eve.Metadata, error) {
+	current, err := readMetadata(ctx, provider, l)
+	if err != nil {
+		return current, err
+	}
+	if err = snapshotMetadata(ctx, provider, current, fmt.Sprintf("restore %d", rev.Revision), user); err != nil {
+		return current, err
+	}
+
+	update := make(eve.MetadataField)
+	var removed []string
+	for _, c := range metadata.Diff(current.Value, rev.Value) {
+		if c.Type == metadata.Removed {
+			removed = append(removed, c.Key)
+			continue
+		}
+		update[c.Key] = c.New
+	}
+
+	md := current
+	if len(update) > 0 {
+		if md, err = mergeServiceMetadata(ctx, provider, l, update, 0); err != nil {
+			return md, err
+		}
+	}
+	for _, k := range removed {
+		if md, err = provider.EveAPI.DeleteMetadataKey(ctx, md.ID, k); err != nil {
+			return md, fmt.Errorf("failed to delete metadata key: %s", k)
+		}
+	}
+	keys := removed
+	for k := range update {
+		keys = append(keys, k)
+	}
+	publishMetadataChanged(ctx, provider, l.key(), fmt.Sprintf("restore %d", rev.Revision), user, keys)
+	return md, nil
+}
+
+// publishMetadataChanged publishes the changed keys of the scope metadata (the values may be secrets)
+func publishMetadataChanged(ctx context.Context, provider *service.Provider, key, action, user string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	provider.Webhooks.Publish(ctx, webhooks.EventMetadataChanged, webhooks.MetadataData{Key: key, Action: action, User: user, Keys: keys})
+}
+
+// confirmMetadataAction is the confirm.Request action of the metadata changes (see ApplyConfirmation)
+const confirmMetadataAction = "metadata"
+
+// pendingMetadata is the change of a metadata confirmation (the json payload of the confirm.Request):
+// the value merged into the scope metadata (set metadata with a file, copy metadata) or the revision restored
+type pendingMetadata struct {
+	Environment eve.Environment             `json:"environment"`
+	Namespace   eve.Namespace               `json:"namespace"`
+	Service     eve.Service                 `json:"service"`
+	Value       eve.MetadataField           `json:"value,omitempty"`
+	Stacking    int                         `json:"stacking,omitempty"`
+	Revision    *datastore.MetadataRevision `json:"revision,omitempty"`
+}
+
+func (l metadataScope) pending() pendingMetadata {
+	return pendingMetadata{Environment: l.env, Namespace: l.ns, Service: l.svc}
+}
+
+// apply saves the pending change of the scope metadata
+func (p pendingMetadata) apply(ctx context.Context, provider *service.Provider, user string) (eve.Metadata, error) {
+	l := metadataScope{env: p.Environment, ns: p.Namespace, svc: p.Service}
+	if p.Revision != nil {
+		return restoreServiceMetadata(ctx, provider, user, l, *p.Revision)
+	}
+	return upsertServiceMetadata(ctx, provider, user, l, p.Value, p.Stacking)
+}
+
+// ApplyConfirmation applies the change of a confirmed request (the Confirm button of a pending confirmation)
+func ApplyConfirmation(ctx context.Context, provider *service.Provider, req confirm.Request) {
+	if req.Action != confirmMetadataAction {
+		provider.ChatService.ErrorNotificationThread(ctx, req.User, req.Channel, req.Timestamp, fmt.Errorf("unknown confirmation action `%s`", req.Action))
+		return
+	}
+	var p pendingMetadata
+	if err := json.Unmarshal(req.Payload, &p); err != nil {
+		provider.ChatService.ErrorNotificationThread(ctx, req.User, req.Channel, req.Timestamp, fmt.Errorf("invalid metadata confirmation: %w", err))
+		return
+	}
+	md, err := p.apply(ctx, provider, req.User)
+	if err != nil {
+		provider.ChatService.ErrorNotificationThread(ctx, req.User, req.Channel, req.Timestamp, err)
+		return
+	}
+	provider.ChatService.ShowResultsMessageThread(ctx, provider.Masker.ChatMessage(md), req.User, req.Channel, req.Timestamp)
+}
+
+// confirmServiceMetadata shows the changes of merging the metadata value into the scope metadata
+// and saves the metadata once the user confirms the changes
+func confirmServiceMetadata(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand, ts string, l metadataScope, field eve.MetadataField, source string) {
+	current, err := readServiceMetadata(ctx, provider, l)
+	if err != nil {
+		provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ts, err)
+		return
+	}
+	pending := l.pending()
+	pending.Value = field
+	pending.Stacking, _ = cmd.Options()[args.StackingName].(int)
+	confirmMetadataChanges(ctx, provider, cmd, ts, l, metadata.Diff(current, metadata.Merge(current, field)), source, pending)
+}
+
+// confirmMetadataChanges shows the (masked) changes of the scope metadata
+// and persists the pending change, it is applied once the user confirms it (see ApplyConfirmation)
+func confirmMetadataChanges(ctx context.Context, provider *service.Provider, cmd commands.EvebotCommand, ts string, l metadataScope, changes metadata.Changes, source string, pending pendingMetadata) {
+	if len(changes) == 0 {
+		provider.ChatService.UserNotificationThread(ctx, fmt.Sprintf("%s doesn't change the %s metadata", source, l.key()), cmd.Info().User, cmd.Info().Channel, ts)
+		return
+	}
+
+	payload, err := json.Marshal(pending)
+	if err != nil {
+		provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ts, err)
+		return
+	}
+	id, err := provider.Confirmations.Add(ctx, confirm.Request{
+		User:      cmd.Info().User,
+		Channel:   cmd.Info().Channel,
+		Timestamp: ts,
+		Action:    confirmMetadataAction,
+		Payload:   payload,
+	})
+	if err != nil {
+		provider.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, ts, err)
+		return
+	}
+	msg := fmt.Sprintf("*%s* changes from %s:\n```%s```", l.key(), source, maskChanges(provider.Masker, changes))
+	provider.ChatService.ConfirmationMessageThread(ctx, msg, cmd.Info().User, cmd.Info().Channel, ts, id)
+}
+
+// metadataHistoryMsg formats the revisions (newest first) with the changes that followed each snapshot
+// current is the metadata value after the newest revision
+func metadataHistoryMsg(m *eveapi.Masker, revs []datastore.MetadataRevision, current eve.MetadataField) string {
+	var builder strings.Builder
+	after := current
+	for _, rev := range revs {
+		builder.WriteString(fmt.Sprintf("*%d* - %s by <@%s> (%s)\n```%s```\n", rev.Revision, rev.CreatedAt.Format("2006-01-02 15:04 MST"), rev.CreatedBy, rev.Action, maskChanges(m, metadata.Diff(rev.Value, after))))
+		after = rev.Value
+	}
+	return builder.String()
+}
+
+// maskChanges masks the secret values of the metadata changes (a changed secret is still shown as changed)
+func maskChanges(m *eveapi.Masker, changes metadata.Changes) metadata.Changes {
+	result := make(metadata.Changes, len(changes))
+	for i, c := range changes {
+		c.Old, c.New = m.MaskValue(c.Key, c.Old), m.MaskValue(c.Key, c.New)
+		result[i] = c
+	}
+	return result
+}
+
+// metadataLayer is a metadata record that applies to a service (with the stacking order of its map)
+type metadataLayer struct {
+	md       eve.Metadata
+	stacking int
+}
+
+// serviceMetadataLayers returns every metadata record that applies to the service (lowest stacking order first)
+func serviceMetadataLayers(ctx context.Context, provider *service.Provider, svc eve.Service) ([]metadataLayer, error) {
+	maps, err := provider.EveAPI.GetServiceMetadataMaps(ctx, svc.ID)
+	if err != nil {
+		if resourceNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.SliceStable(maps, func(i, j int) bool { return maps[i].StackingOrder < maps[j].StackingOrder })
+
+	var layers []metadataLayer
+	for _, m := range maps {
+		md, err := provider.EveAPI.GetMetadata(ctx, strconv.Itoa(m.MetadataID))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, metadataLayer{md: md, stacking: m.StackingOrder})
+	}
+	return layers, nil
+}
+
+// metadataLayersMsg formats every layer and the effective (merged) metadata with the render func (masked or not)
+func metadataLayersMsg(layers []metadataLayer, render func(model interface{}) string) string {
+	var builder strings.Builder
+	for _, l := range layers {
+		builder.WriteString(fmt.Sprintf("`%s` (stacking %d)\n%s\n", l.md.Description, l.stacking, render(l.md)))
+	}
+	merged, _ := metadata.Effective(metadataStack(layers, nil)...)
+	effective := eve.Metadata{ID: layers[len(layers)-1].md.ID, Value: merged}
+	builder.WriteString(fmt.Sprintf("*effective metadata*\n%s", render(effective)))
+	return builder.String()
+}
+
+// metadataStack maps the layers to the metadata.Effective layers (the values are masked with the mask func when it is set)
+// show metadata and show effective metadata merge the layers the same way
+func metadataStack(layers []metadataLayer, mask func(eve.MetadataField) eve.MetadataField) []metadata.Layer {
+	stack := make([]metadata.Layer, len(layers))
+	for i, l := range layers {
+		value := l.md.Value
+		if mask != nil {
+			value = mask(value)
+		}
+		stack[i] = metadata.Layer{Name: l.md.Description, StackingOrder: l.stacking, Value: value}
+	}
+	return stack
+}
+
+// effectiveMetadataMsg formats the effective metadata values with the layer that won (masked unless unmasked is set)
+func effectiveMetadataMsg(m *eveapi.Masker, layers []metadataLayer, unmasked bool) string {
+	mask := m.Mask
+	if unmasked {
+		mask = nil
+	}
+	stack := metadataStack(layers, mask)
+	_, origins := metadata.Effective(stack...)
+	if len(origins) == 0 {
+		return "no metadata"
+	}
+	var lines []string
+	for _, o := range origins {
+		lines = append(lines, o.String())
+	}
+	return "```" + strings.Join(lines, "\n") + "```"
+}
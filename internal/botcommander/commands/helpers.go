@@ -1,10 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
 	"github.com/unanet/eve/pkg/eve"
 )
 
@@ -103,37 +106,46 @@ func CleanUrls(input string) string {
 	return cleanEncoding(result)
 }
 
-func hydrateMetadataMap(keyvals []string) params.MetadataMap {
+// typedValueSuffix marks a typed metadata value (i.e. `key:=123`, `key:=true`, `key:=@json{...}`)
+const typedValueSuffix = ":"
+
+// hydrateMetadataMap hydrates the metadata from the key=value fields (the tokenizer splits them, the values with spaces are quoted)
+// the typed values (key:=value) are parsed as json, the fields without a key (i.e. the unquoted words of a value) are rejected
+func hydrateMetadataMap(keyvals []string) (params.MetadataMap, error) {
 	result := make(params.MetadataMap)
 	if len(keyvals) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	for i, s := range keyvals {
-		if strings.Contains(s, "=") {
-			argKV := strings.Split(s, "=")
-
-			result[argKV[0]] = extractMetadataValue(argKV[1], keyvals[i+1:])
+	var stray []string
+	for _, s := range keyvals {
+		argKV := strings.SplitN(s, "=", 2)
+		if len(argKV) != 2 || len(argKV[0]) == 0 {
+			stray = append(stray, "`"+s+"`")
+			continue
+		}
+		if key := strings.TrimSuffix(argKV[0], typedValueSuffix); key != argKV[0] {
+			value, err := parseTypedValue(key, argKV[1])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+			continue
 		}
+		result[argKV[0]] = argKV[1]
+	}
+	if len(stray) > 0 {
+		return nil, fmt.Errorf("unexpected %s, expected key=value (quote the values with spaces, i.e. key=\"two words\")", strings.Join(stray, ", "))
 	}
 
-	return result
+	return result, nil
 }
 
-func extractMetadataValue(initialValue string, keyvals []string) string {
-	var builder strings.Builder
-
-	// Let's not forget our initial value for a properly formatted metadata value of `FOO=BAR`.
-	// If this has a space like `FOO= BAR`, we will trim the spaces out later
-	builder.WriteString(initialValue + " ")
-
-	for _, s := range keyvals {
-		// While we iterate over our values, we want to stop if we see a `=`. This will indicate the start of a new key
-		if strings.Contains(s, "=") {
-			break
-		}
-
-		builder.WriteString(s + " ")
+// parseTypedValue parses a typed metadata value: a number, a bool, null or a json value (@json{...})
+func parseTypedValue(key, raw string) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(raw, tokenizer.JSONPrefix)), &value); err != nil {
+		return nil, fmt.Errorf("invalid typed value `%s:=%s`, expected a number, true, false, null or %s{...}", key, raw, tokenizer.JSONPrefix)
 	}
-	return strings.TrimSpace(builder.String())
-}
\ No newline at end of file
+	return value, nil
+}
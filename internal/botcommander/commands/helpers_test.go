@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
 )
 
 func Test_Metadata(t *testing.T) {
@@ -12,9 +13,10 @@ func Test_Metadata(t *testing.T) {
 		input []string
 	}
 	tests := []struct {
-		name string
-		args args
-		want params.MetadataMap
+		name    string
+		args    args
+		want    params.MetadataMap
+		wantErr string
 	}{
 		{
 			name: "test handling empty metadata",
@@ -28,7 +30,7 @@ func Test_Metadata(t *testing.T) {
 			args: args{
 				input: []string{"FOO"},
 			},
-			want: params.MetadataMap{},
+			wantErr: "unexpected `FOO`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
 		},
 		{
 			name: "test handling single key value parameter",
@@ -55,7 +57,7 @@ func Test_Metadata(t *testing.T) {
 			},
 		},
 		{
-			name: "test handling params with spaces",
+			name: "test rejecting the unquoted value after the equals",
 			args: args{
 				input: []string{
 					"FOO=",
@@ -63,13 +65,23 @@ func Test_Metadata(t *testing.T) {
 					"FIZZ=BUZZ",
 				},
 			},
+			wantErr: "unexpected `BAR`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
+		},
+		{
+			name: "test handling empty values",
+			args: args{
+				input: []string{
+					"FOO=",
+					"FIZZ=BUZZ",
+				},
+			},
 			want: params.MetadataMap{
-				"FOO":  "BAR",
+				"FOO":  "",
 				"FIZZ": "BUZZ",
 			},
 		},
 		{
-			name: "test handling multiple params with spaces",
+			name: "test rejecting multiple unquoted values",
 			args: args{
 				input: []string{
 					"FOO=",
@@ -79,11 +91,7 @@ func Test_Metadata(t *testing.T) {
 					"SHOP",
 				},
 			},
-			want: params.MetadataMap{
-				"FOO":  "BAR",
-				"FIZZ": "BUZZ",
-				"TACO": "SHOP",
-			},
+			wantErr: "unexpected `BAR`, `SHOP`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
 		},
 		{
 			name: "test handling params with space between params",
@@ -99,7 +107,7 @@ func Test_Metadata(t *testing.T) {
 			},
 		},
 		{
-			name: "test handling params with multiple spaces between params at the beginning",
+			name: "test rejecting the unquoted words at the beginning",
 			args: args{
 				input: []string{
 					"FOO=BAR",
@@ -108,13 +116,10 @@ func Test_Metadata(t *testing.T) {
 					"FIZZ=BUZZ",
 				},
 			},
-			want: params.MetadataMap{
-				"FOO":  "BAR TACOS BURRITO",
-				"FIZZ": "BUZZ",
-			},
+			wantErr: "unexpected `TACOS`, `BURRITO`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
 		},
 		{
-			name: "test handling params with multiple spaces between params at the end",
+			name: "test rejecting the unquoted words at the end",
 			args: args{
 				input: []string{
 					"FOO=BAR",
@@ -123,10 +128,16 @@ func Test_Metadata(t *testing.T) {
 					"BURRITO",
 				},
 			},
-			want: params.MetadataMap{
-				"FOO":  "BAR",
-				"FIZZ": "BUZZ TACOS BURRITO",
+			wantErr: "unexpected `TACOS`, `BURRITO`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
+		},
+		{
+			name: "test rejecting a field without a key",
+			args: args{
+				input: []string{
+					"=BAR",
+				},
 			},
+			wantErr: "unexpected `=BAR`, expected key=value (quote the values with spaces, i.e. key=\"two words\")",
 		},
 		{
 			name: "test handling params with hyphens",
@@ -153,7 +164,87 @@ func Test_Metadata(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := hydrateMetadataMap(tt.args.input); !reflect.DeepEqual(got, tt.want) {
+			got, err := hydrateMetadataMap(tt.args.input)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MetadataTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    params.MetadataMap
+		wantErr string
+	}{
+		{
+			name:  "quoted values",
+			input: `FOO="BAR  TACOS" FIZZ='BUZZ=1'`,
+			want:  params.MetadataMap{"FOO": "BAR  TACOS", "FIZZ": "BUZZ=1"},
+		},
+		{
+			name:  "equals in the value",
+			input: "db=jdbc:postgresql://db:5432/app?escapeSyntaxCallMode=callIfNoReturn",
+			want:  params.MetadataMap{"db": "jdbc:postgresql://db:5432/app?escapeSyntaxCallMode=callIfNoReturn"},
+		},
+		{
+			name:  "slack smart quotes",
+			input: "FOO=“BAR TACOS”",
+			want:  params.MetadataMap{"FOO": "BAR TACOS"},
+		},
+		{
+			name:  "quoted number stays a string",
+			input: `port="8080"`,
+			want:  params.MetadataMap{"port": "8080"},
+		},
+		{
+			name:  "typed values",
+			input: `port:=8080 ratio:=0.5 enabled:=true nothing:=null`,
+			want:  params.MetadataMap{"port": float64(8080), "ratio": 0.5, "enabled": true, "nothing": nil},
+		},
+		{
+			name:  "json value",
+			input: `hosts:=@json["a", "b"] limits:=@json{"cpu": "500m", "replicas": 2}`,
+			want: params.MetadataMap{
+				"hosts":  []interface{}{"a", "b"},
+				"limits": map[string]interface{}{"cpu": "500m", "replicas": float64(2)},
+			},
+		},
+		{
+			name:    "invalid typed value",
+			input:   "port:=eighty",
+			wantErr: "invalid typed value `port:=eighty`, expected a number, true, false, null or @json{...}",
+		},
+		{
+			name:    "invalid json value",
+			input:   `limits:=@json{"cpu": }`,
+			wantErr: "invalid typed value `limits:=@json{\"cpu\": }`, expected a number, true, false, null or @json{...}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hydrateMetadataMap(tokenizer.Split(tt.input))
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got = %v\nwant %v", got, tt.want)
 			}
 		})
@@ -28,6 +28,15 @@ func NewFactory() Factory {
 			RestartCmdName:          NewRestartCommand,
 			RunCmdName:              NewRunCommand,
 			AuthCmdName:             NewAuthCommand,
+			AliasCmdName:            NewAliasCommand,
+			ContextCmdName:          NewContextCommand,
+			ExportCmdName:           NewExportCommand,
+			CopyCmdName:             NewCopyCommand,
+			DiffCmdName:             NewDiffCommand,
+			RestoreCmdName:          NewRestoreCommand,
+			SubscribeCmdName:        NewSubscribeCommand,
+			UnsubscribeCmdName:      NewUnsubscribeCommand,
+			LogsCmdName:             NewLogsCommand,
 		},
 	}
 }
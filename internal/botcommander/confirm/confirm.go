@@ -0,0 +1,99 @@
+// Package confirm keeps the pending confirmations (i.e. the Confirm/Cancel buttons of a metadata upload)
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	goerrors "errors"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// DefaultTTL is how long a confirmation stays pending
+const DefaultTTL = 15 * time.Minute
+
+var (
+	// ErrExpired is returned when the confirmation doesn't exist (anymore)
+	ErrExpired = goerrors.New("the confirmation has expired")
+	// ErrWrongUser is returned when someone else than the requesting user confirms
+	ErrWrongUser = goerrors.New("only the requesting user can confirm")
+)
+
+// Request is a pending confirmation
+// the Payload (json) is the change applied with the Action once the user confirms the request,
+// the requests are persisted so that any eve-bot instance can apply them
+type Request struct {
+	User, Channel, Timestamp string
+	Action                   string
+	Payload                  []byte
+}
+
+// Store keeps the pending confirmations in the datastore (expired with a TTL)
+type Store struct {
+	db  interfaces.ConfirmationStore
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewStore creates a confirmation Store
+func NewStore(db interfaces.ConfirmationStore, ttl time.Duration) *Store {
+	return &Store{
+		db:  db,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Add adds the request and returns the confirmation id
+func (s *Store) Add(ctx context.Context, r Request) (string, error) {
+	now := s.now().UTC()
+	c := datastore.Confirmation{
+		ID:        newID(),
+		User:      r.User,
+		Channel:   r.Channel,
+		Timestamp: r.Timestamp,
+		Action:    r.Action,
+		Payload:   string(r.Payload),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.db.SaveConfirmation(ctx, c); err != nil {
+		return "", err
+	}
+	return c.ID, nil
+}
+
+// Take removes and returns the pending request, only the requesting user can take it (once)
+func (s *Store) Take(ctx context.Context, id, user string) (Request, error) {
+	c, err := s.db.ReadConfirmation(ctx, id)
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			return Request{}, ErrExpired
+		}
+		return Request{}, err
+	}
+	if c.User != user {
+		return Request{}, ErrWrongUser
+	}
+	if c, err = s.db.TakeConfirmation(ctx, id); err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			return Request{}, ErrExpired
+		}
+		return Request{}, err
+	}
+	// DynamoDB deletes the expired items within a few days
+	if s.now().After(c.ExpiresAt) {
+		return Request{}, ErrExpired
+	}
+	return Request{User: c.User, Channel: c.Channel, Timestamp: c.Timestamp, Action: c.Action, Payload: []byte(c.Payload)}, nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package confirm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/go/pkg/errors"
+)
+
+type fakeConfirmationStore map[string]datastore.Confirmation
+
+func (f fakeConfirmationStore) SaveConfirmation(ctx context.Context, c datastore.Confirmation) error {
+	f[c.ID] = c
+	return nil
+}
+
+func (f fakeConfirmationStore) ReadConfirmation(ctx context.Context, id string) (*datastore.Confirmation, error) {
+	c, ok := f[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (f fakeConfirmationStore) TakeConfirmation(ctx context.Context, id string) (*datastore.Confirmation, error) {
+	c, ok := f[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	delete(f, id)
+	return &c, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	db := fakeConfirmationStore{}
+	s := NewStore(db, time.Minute)
+	s.now = func() time.Time { return now }
+
+	id, err := s.Add(ctx, Request{User: "alice", Channel: "C1", Timestamp: "1.1", Action: "metadata", Payload: []byte(`{"a":1}`)})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if db[id].ExpiresAt != now.Add(time.Minute) {
+		t.Errorf("Add() ExpiresAt = %v, want %v", db[id].ExpiresAt, now.Add(time.Minute))
+	}
+
+	if _, err := s.Take(ctx, id, "bob"); err != ErrWrongUser {
+		t.Fatalf("Take() by another user error = %v, want %v", err, ErrWrongUser)
+	}
+	r, err := s.Take(ctx, id, "alice")
+	if err != nil || r.Channel != "C1" || r.Action != "metadata" || string(r.Payload) != `{"a":1}` {
+		t.Fatalf("Take() got = %v, %v", r, err)
+	}
+	if _, err := s.Take(ctx, id, "alice"); err != ErrExpired {
+		t.Fatalf("Take() twice error = %v, want %v", err, ErrExpired)
+	}
+
+	expired, _ := s.Add(ctx, Request{User: "alice"})
+	now = now.Add(2 * time.Minute)
+	if _, err := s.Take(ctx, expired, "alice"); err != ErrExpired {
+		t.Fatalf("Take() expired error = %v, want %v", err, ErrExpired)
+	}
+	if _, ok := db[expired]; ok {
+		t.Errorf("Take() expired kept the confirmation")
+	}
+}
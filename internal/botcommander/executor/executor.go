@@ -3,14 +3,43 @@ package executor
 import (
 	"context"
 	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
 	"github.com/unanet/eve-bot/internal/service"
 
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
 	"github.com/unanet/eve-bot/internal/botcommander/commands/handlers"
+	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/tracing"
+	"github.com/unanet/eve-bot/internal/webhooks"
+	"github.com/unanet/go/pkg/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var statHandlerDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "command_handler_duration_seconds",
+		Help:    "The duration of the command handlers by command name",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"command"})
+
+// eveAPIWriteCommands are the commands that change the eve-api resources
+// (they are answered right away while the eve-api is unavailable, the other commands use the last known good responses)
+var eveAPIWriteCommands = map[string]bool{
+	commands.DeployCmdName:  true,
+	commands.SetCmdName:     true,
+	commands.DeleteCmdName:  true,
+	commands.ReleaseCmdName: true,
+	commands.RestartCmdName: true,
+	commands.RunCmdName:     true,
+	commands.CopyCmdName:    true,
+	commands.RestoreCmdName: true,
+}
+
 // EvebotCommandExecutor is the data structure that implements the Executor
 type EvebotCommandExecutor struct {
 	svc               *service.Provider
@@ -26,9 +55,39 @@ func New(svc *service.Provider, handlerFactory handlers.Factory) interfaces.Comm
 }
 
 // Execute satisfies the Executor.Execute interface
+// every command gets a request id (propagated to the eve-api calls with the X-Request-Id header)
 func (h *EvebotCommandExecutor) Execute(ctx context.Context, cmd commands.EvebotCommand, timestamp string) {
+	if len(log.GetReqID(ctx)) == 0 {
+		ctx = context.WithValue(ctx, log.RequestIDKey, log.GetNextRequestID())
+	}
+	ctx, span := tracing.Start(ctx, "executor.execute",
+		attribute.String("evebot.command", cmd.Info().CommandName), attribute.String("evebot.request_id", log.GetReqID(ctx)))
+	defer span.End()
 	if cmdHandlerFunc := h.cmdHandlerFactory.Items()[cmd.Info().CommandName]; cmdHandlerFunc != nil {
-		cmdHandlerFunc(h.svc).Handle(ctx, cmd, timestamp)
+		if status, ok := h.svc.EveAPI.(interfaces.EveAPIStatus); ok && eveAPIWriteCommands[cmd.Info().CommandName] {
+			if since, unavailable := status.UnavailableSince(); unavailable {
+				h.svc.ChatService.UserNotificationThread(ctx, eveapi.UnavailableError{Since: since}.Error()+", please try again once it is back", cmd.Info().User, cmd.Info().Channel, timestamp)
+				return
+			}
+		}
+		ctx = eveapi.WithStaleReads(ctx)
+		func() {
+			handlerCtx, handlerSpan := tracing.Start(ctx, "handler."+cmd.Info().CommandName)
+			defer handlerSpan.End()
+			start := time.Now()
+			cmdHandlerFunc(h.svc).Handle(handlerCtx, cmd, timestamp)
+			duration := time.Since(start)
+			statHandlerDuration.WithLabelValues(cmd.Info().CommandName).Observe(duration.Seconds())
+			h.svc.Webhooks.Publish(ctx, webhooks.EventCommandExecuted, webhooks.CommandData{
+				Command:  cmd.Info().CommandName,
+				User:     cmd.Info().User,
+				Channel:  cmd.Info().Channel,
+				Duration: duration.Seconds(),
+			})
+		}()
+		if banner, stale := eveapi.StaleReadsFrom(ctx).Banner(); stale {
+			h.svc.ChatService.UserNotificationThread(ctx, banner, cmd.Info().User, cmd.Info().Channel, timestamp)
+		}
 		return
 	}
 	h.svc.ChatService.ErrorNotificationThread(ctx, cmd.Info().User, cmd.Info().Channel, timestamp, errors.New("failed to execute command; invalid command handler"))
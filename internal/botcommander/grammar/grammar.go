@@ -0,0 +1,249 @@
+// Package grammar is a small declarative parser for the evebot commands
+//
+// Each command declares its grammar (keywords, params, args) once, e.g.
+//
+//	grammar.New("show",
+//		grammar.Keyword("metadata").As("resource"),
+//		grammar.AnyOrder(
+//			grammar.Clause("for", grammar.Param("service")),
+//			grammar.Clause("in", grammar.Param("namespace"), grammar.Param("environment")),
+//		),
+//	)
+//
+// and the grammar is used to both parse the input (into Options) and generate the help usage
+package grammar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+	"github.com/unanet/eve-bot/internal/botcommander/suggest"
+)
+
+// Options are the key/value pairs resolved from the command input
+type Options map[string]interface{}
+
+// placeholder matches the {{ key }} labels of the Param usage (see state.fill)
+var placeholder = regexp.MustCompile(`\{\{ (\w+) \}\}`)
+
+// Rule is a single production in a command grammar
+type Rule interface {
+	// first returns the keywords that can start the rule (nil when the rule starts with a value)
+	first() []string
+	// parse consumes the input and hydrates the options
+	parse(s *state) error
+	// usage returns the alternative usage strings for the rule
+	usage() []string
+}
+
+// Grammar is the declared grammar for a single command
+type Grammar struct {
+	name string
+	root Rule
+}
+
+// New creates a command grammar for the rules that follow the command name
+func New(cmdName string, rules ...Rule) Grammar {
+	return Grammar{
+		name: cmdName,
+		root: Seq(rules...),
+	}
+}
+
+// Parse parses the command input (the first field is the command name)
+// the options resolved before an invalid optional clause are returned along with the error,
+// every other error returns empty options
+func (g Grammar) Parse(input []string) (Options, error) {
+	s := &state{input: input, pos: 1, opts: make(Options)}
+	if err := g.parse(s); err != nil {
+		if _, partial := err.(partialError); partial {
+			return s.opts, err
+		}
+		return Options{}, err
+	}
+	return s.opts, nil
+}
+
+// Complete fills in the Defaulted clauses (and the Optional clauses with OrDefault) left out of the command input
+// with the default option values (i.e. the channel namespace and environment) and returns the completed input
+// the input is returned untouched with the error when it doesn't parse
+func (g Grammar) Complete(input []string, defaults Options) ([]string, error) {
+	s := &state{input: input, pos: 1, opts: make(Options), defaults: defaults}
+	if err := g.parse(s); err != nil {
+		return input, err
+	}
+	if len(s.inserted) == 0 {
+		return input, nil
+	}
+	result := make([]string, 0, len(input))
+	next := 0
+	for pos := 0; pos <= len(input); pos++ {
+		for ; next < len(s.inserted) && s.inserted[next].pos == pos; next++ {
+			result = append(result, s.inserted[next].tokens...)
+		}
+		if pos < len(input) {
+			result = append(result, input[pos])
+		}
+	}
+	return result, nil
+}
+
+func (g Grammar) parse(s *state) error {
+	if err := g.root.parse(s); err != nil {
+		return err
+	}
+	if tok, ok := s.peek(); ok {
+		return fmt.Errorf("unexpected `%s` at position %d", tok, s.pos+1)
+	}
+	return nil
+}
+
+// Usage generates the help usage for every alternative in the grammar
+func (g Grammar) Usage() help.Usage {
+	var result help.Usage
+	for _, u := range g.root.usage() {
+		result = append(result, strings.TrimSpace(g.name+" "+u))
+	}
+	return result
+}
+
+// partialError is an error in an optional clause, after the required rules were already resolved
+type partialError struct {
+	error
+}
+
+type state struct {
+	input []string
+	pos   int
+	opts  Options
+	// defaults are the option values of the clauses left out of the input (see Complete)
+	defaults Options
+	// inserted are the clauses filled in with the defaults
+	inserted []insertion
+}
+
+// insertion is a clause filled in with the defaults at an input position
+type insertion struct {
+	pos    int
+	tokens []string
+}
+
+// child is a state to try a rule from the current position, its options are merged on success
+func (s *state) child() *state {
+	return &state{input: s.input, pos: s.pos, opts: make(Options), defaults: s.defaults}
+}
+
+func (s *state) merge(child *state) {
+	for k, v := range child.opts {
+		s.opts[k] = v
+	}
+	s.pos = child.pos
+	s.inserted = append(s.inserted, child.inserted...)
+}
+
+// fill parses the rule usage with its {{ key }} params replaced by the defaults and inserts it at the current position
+// it returns false (and leaves the state untouched) when a default is missing
+func (s *state) fill(r Rule) bool {
+	if len(s.defaults) == 0 {
+		return false
+	}
+	missing := false
+	rendered := placeholder.ReplaceAllStringFunc(r.usage()[0], func(label string) string {
+		value, ok := s.defaults[placeholder.FindStringSubmatch(label)[1]].(string)
+		if !ok || len(value) == 0 {
+			missing = true
+		}
+		return value
+	})
+	if missing {
+		return false
+	}
+	tokens := strings.Fields(rendered)
+	filled := &state{input: append([]string{""}, tokens...), pos: 1, opts: make(Options)}
+	if err := r.parse(filled); err != nil || filled.pos != len(filled.input) {
+		return false
+	}
+	for k, v := range filled.opts {
+		s.opts[k] = v
+	}
+	s.inserted = append(s.inserted, insertion{pos: s.pos, tokens: tokens})
+	return true
+}
+
+func (s *state) peek() (string, bool) {
+	if s.pos >= len(s.input) {
+		return "", false
+	}
+	return s.input[s.pos], true
+}
+
+func (s *state) next() string {
+	tok := s.input[s.pos]
+	s.pos++
+	return tok
+}
+
+func (s *state) remaining() []string {
+	if s.pos >= len(s.input) {
+		return nil
+	}
+	return s.input[s.pos:]
+}
+
+// expected returns the "expected X at position N" error for the current position
+func (s *state) expected(what string) error {
+	if tok, ok := s.peek(); ok {
+		return fmt.Errorf("expected %s at position %d but got `%s`", what, s.pos+1, tok)
+	}
+	return fmt.Errorf("expected %s at position %d", what, s.pos+1)
+}
+
+// expectedKeyword returns the "expected X at position N" error with the closest keyword suggestion
+// the short connective keywords (in, to) are never suggested, they are usually left out (not mistyped)
+func (s *state) expectedKeyword(keywords []string, suggestions []string) error {
+	err := s.expected(describe(keywords))
+	var candidates []string
+	for _, k := range suggestions {
+		if len(k) > 2 {
+			candidates = append(candidates, k)
+		}
+	}
+	if tok, ok := s.peek(); ok {
+		if k, ok := suggest.Closest(tok, candidates); ok {
+			return fmt.Errorf("%v — did you mean `%s`?", err, k)
+		}
+	}
+	return err
+}
+
+// matches checks if the next token can start a rule
+// rules that start with a value match any token
+func (s *state) matches(r Rule) bool {
+	tok, ok := s.peek()
+	if !ok {
+		return false
+	}
+	keywords := r.first()
+	if keywords == nil {
+		return true
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(k, tok) {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(keywords []string) string {
+	quoted := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		quoted = append(quoted, "`"+k+"`")
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return "one of " + strings.Join(quoted, ", ")
+}
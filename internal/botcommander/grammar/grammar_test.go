@@ -0,0 +1,273 @@
+package grammar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+	"github.com/unanet/eve-bot/internal/botcommander/help"
+)
+
+var testGrammar = New("test",
+	OneOf(
+		Seq(Keyword("environments").As("resource")),
+		Seq(Keyword("services", "service").As("resource"), Clause("in", Param("namespace"), Param("environment"))),
+		Seq(
+			Keyword("metadata").As("resource"),
+			AnyOrder(
+				Clause("for", Param("service")),
+				Clause("in", Param("namespace"), Param("environment")),
+			),
+		),
+		Seq(
+			Keyword("version").As("resource"),
+			AnyOrder(
+				Optional(Clause("for", Param("service"))),
+				Clause("in", Param("namespace"), Param("environment")),
+				Clause("to", Param("version")),
+			),
+		),
+		Seq(
+			Keyword("feed").As("resource"),
+			Param("from"),
+			Optional(Clause("to", Param("to"))).Or("to", ""),
+		),
+		Seq(
+			Keyword("deploy").As("resource"),
+			Optional(Flag("all", "all")).Or("all", false),
+			Param("namespace"),
+			Args(args.DefaultDryrunArg(), args.DefaultServicesArg()),
+		),
+		Seq(
+			Keyword("keys").As("resource"),
+			Remainder("{{ key }}", func(values []string, opts Options) error {
+				opts["keys"] = values
+				return nil
+			}),
+		),
+	),
+)
+
+func TestGrammar_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Options
+		wantErr string
+	}{
+		{
+			name:  "keyword",
+			input: "test environments",
+			want:  Options{"resource": "environments"},
+		},
+		{
+			name:  "keyword alias and case",
+			input: "test Service in current int",
+			want:  Options{"resource": "service", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "clauses in declared order",
+			input: "test metadata for api in current int",
+			want:  Options{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "clauses in any order",
+			input: "test metadata in current int for api",
+			want:  Options{"resource": "metadata", "service": "api", "namespace": "current", "environment": "int"},
+		},
+		{
+			name:  "optional clause",
+			input: "test version for api in current int to 1.2",
+			want:  Options{"resource": "version", "service": "api", "namespace": "current", "environment": "int", "version": "1.2"},
+		},
+		{
+			name:  "optional clause left out",
+			input: "test version to 1.2 in current int",
+			want:  Options{"resource": "version", "namespace": "current", "environment": "int", "version": "1.2"},
+		},
+		{
+			name:  "optional default",
+			input: "test feed int",
+			want:  Options{"resource": "feed", "from": "int", "to": ""},
+		},
+		{
+			name:  "optional set",
+			input: "test feed int to prod",
+			want:  Options{"resource": "feed", "from": "int", "to": "prod"},
+		},
+		{
+			name:  "flag and args",
+			input: "test deploy all current dryrun=true services=api,billing",
+			want: Options{
+				"resource":  "deploy",
+				"all":       true,
+				"namespace": "current",
+				"dryrun":    true,
+				"services":  args.NewServicesArg([]string{"api", "billing"}).Value(),
+			},
+		},
+		{
+			name:  "flag default",
+			input: "test deploy current",
+			want:  Options{"resource": "deploy", "all": false, "namespace": "current"},
+		},
+		{
+			name:  "remainder",
+			input: "test keys a b c",
+			want:  Options{"resource": "keys", "keys": []string{"a", "b", "c"}},
+		},
+		{
+			name:    "unknown keyword",
+			input:   "test jobs in current int",
+			want:    Options{},
+			wantErr: "expected one of `environments`, `services`, `metadata`, `version`, `feed`, `deploy`, `keys` at position 2 but got `jobs`",
+		},
+		{
+			name:    "mistyped keyword",
+			input:   "test servcies in current int",
+			want:    Options{},
+			wantErr: "expected one of `environments`, `services`, `metadata`, `version`, `feed`, `deploy`, `keys` at position 2 but got `servcies` — did you mean `services`?",
+		},
+		{
+			name:    "missing keyword",
+			input:   "test services current int",
+			want:    Options{},
+			wantErr: "expected `in` at position 3 but got `current`",
+		},
+		{
+			name:    "missing param",
+			input:   "test services in current",
+			want:    Options{},
+			wantErr: "expected {{ environment }} at position 5",
+		},
+		{
+			name:    "missing required clause",
+			input:   "test metadata for api",
+			want:    Options{},
+			wantErr: "expected `in` at position 5",
+		},
+		{
+			name:    "repeated clause",
+			input:   "test metadata for api for billing in current int",
+			want:    Options{},
+			wantErr: "expected `in` at position 5 but got `for`",
+		},
+		{
+			name:    "invalid optional clause keeps the required options",
+			input:   "test feed int to",
+			want:    Options{"resource": "feed", "from": "int"},
+			wantErr: "expected {{ to }} at position 5",
+		},
+		{
+			name:    "unexpected trailing input",
+			input:   "test environments please",
+			want:    Options{},
+			wantErr: "unexpected `please` at position 3",
+		},
+		{
+			name:    "unknown arg",
+			input:   "test deploy current force=true",
+			want:    Options{},
+			wantErr: "expected {{ arg=value }} at position 4 but got `force=true`",
+		},
+		{
+			name:    "invalid arg value",
+			input:   "test deploy current dryrun=maybe",
+			want:    Options{},
+			wantErr: "invalid arg `dryrun=maybe` at position 4",
+		},
+		{
+			name:    "missing remainder",
+			input:   "test keys",
+			want:    Options{},
+			wantErr: "expected {{ key }} at position 3",
+		},
+		{
+			name:    "empty",
+			input:   "test",
+			want:    Options{},
+			wantErr: "expected one of `environments`, `services`, `metadata`, `version`, `feed`, `deploy`, `keys` at position 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testGrammar.Parse(strings.Fields(tt.input))
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("Parse() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrammar_Usage(t *testing.T) {
+	want := help.Usage{
+		"test environments",
+		"test services in {{ namespace }} {{ environment }}",
+		"test metadata for {{ service }} in {{ namespace }} {{ environment }}",
+		"test version in {{ namespace }} {{ environment }} to {{ version }}",
+		"test version for {{ service }} in {{ namespace }} {{ environment }} to {{ version }}",
+		"test feed {{ from }}",
+		"test feed {{ from }} to {{ to }}",
+		"test deploy {{ namespace }}",
+		"test deploy {{ namespace }} {{ arg=value }}",
+		"test deploy all {{ namespace }}",
+		"test deploy all {{ namespace }} {{ arg=value }}",
+		"test keys {{ key }}",
+	}
+	if got := testGrammar.Usage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Usage() got = %v\nwant %v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGrammar_Complete(t *testing.T) {
+	in := Defaulted(Clause("in", Param("namespace"), Param("environment")))
+	g := New("test",
+		OneOf(
+			Seq(Keyword("services").As("resource"), in),
+			Seq(Keyword("version").As("resource"), AnyOrder(Optional(Clause("for", Param("service"))), in, Clause("to", Param("version")))),
+			Seq(Keyword("metadata").As("resource"), OneOf(AnyOrder(Optional(Clause("for", Param("service"))), in), Clause("across", Param("environment")))),
+			Seq(Keyword("deploy").As("resource"), Defaulted(Seq(Param("namespace"), Clause("in", Param("environment")))), Args(args.DefaultServicesArg())),
+			Seq(Keyword("subscribe").As("resource"), Optional(in).OrDefault()),
+		),
+	)
+	defaults := Options{"namespace": "current", "environment": "int"}
+	tests := []struct {
+		name     string
+		input    string
+		defaults Options
+		want     string
+		wantErr  bool
+	}{
+		{name: "missing clause", input: "test services", defaults: defaults, want: "test services in current int"},
+		{name: "clause in the input", input: "test services in latest qa", defaults: defaults, want: "test services in latest qa"},
+		{name: "missing default", input: "test services", defaults: Options{"environment": "int"}, want: "test services", wantErr: true},
+		{name: "no defaults", input: "test services", want: "test services", wantErr: true},
+		{name: "any order", input: "test version for api to 2.0", defaults: defaults, want: "test version for api to 2.0 in current int"},
+		{name: "one of", input: "test metadata", defaults: defaults, want: "test metadata in current int"},
+		{name: "one of with a clause", input: "test metadata for api", defaults: defaults, want: "test metadata for api in current int"},
+		{name: "one of with another clause", input: "test metadata across qa", defaults: defaults, want: "test metadata across qa"},
+		{name: "value", input: "test deploy services=api", defaults: defaults, want: "test deploy current in int services=api"},
+		{name: "value in the input", input: "test deploy latest in qa", defaults: defaults, want: "test deploy latest in qa"},
+		{name: "optional", input: "test subscribe", defaults: defaults, want: "test subscribe in current int"},
+		{name: "optional without defaults", input: "test subscribe", want: "test subscribe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Complete(strings.Fields(tt.input), tt.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, strings.Fields(tt.want)) {
+				t.Errorf("Complete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
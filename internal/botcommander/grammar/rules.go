@@ -0,0 +1,440 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/args"
+)
+
+type keyword struct {
+	words []string
+	key   string
+}
+
+// Keyword matches a literal word (or one of its aliases)
+func Keyword(word string, aliases ...string) *keyword {
+	return &keyword{words: append([]string{word}, aliases...)}
+}
+
+// As stores the matched (lower case) keyword in the options
+func (k *keyword) As(key string) *keyword {
+	k.key = key
+	return k
+}
+
+func (k *keyword) first() []string {
+	return k.words
+}
+
+func (k *keyword) parse(s *state) error {
+	if !s.matches(k) {
+		return s.expectedKeyword(k.words[:1], k.words)
+	}
+	tok := strings.ToLower(s.next())
+	if len(k.key) > 0 {
+		s.opts[k.key] = tok
+	}
+	return nil
+}
+
+func (k *keyword) usage() []string {
+	return []string{k.words[0]}
+}
+
+type flag struct {
+	word, key string
+}
+
+// Flag matches a literal word and sets the option to true
+func Flag(key, word string) Rule {
+	return flag{word: word, key: key}
+}
+
+func (f flag) first() []string {
+	return []string{f.word}
+}
+
+func (f flag) parse(s *state) error {
+	if !s.matches(f) {
+		return s.expected(describe(f.first()))
+	}
+	s.next()
+	s.opts[f.key] = true
+	return nil
+}
+
+func (f flag) usage() []string {
+	return []string{f.word}
+}
+
+type param struct {
+	label string
+	fn    func(value string, opts Options) error
+}
+
+// Param matches a single value and stores it in the options
+func Param(key string) Rule {
+	return ParamFunc("{{ "+key+" }}", func(value string, opts Options) error {
+		opts[key] = value
+		return nil
+	})
+}
+
+// ParamFunc matches a single value and hydrates the options with the fn
+func ParamFunc(label string, fn func(value string, opts Options) error) Rule {
+	return param{label: label, fn: fn}
+}
+
+func (p param) first() []string {
+	return nil
+}
+
+func (p param) parse(s *state) error {
+	if _, ok := s.peek(); !ok {
+		return s.expected(p.label)
+	}
+	return p.fn(s.next(), s.opts)
+}
+
+func (p param) usage() []string {
+	return []string{p.label}
+}
+
+type remainder struct {
+	label string
+	fn    func(values []string, opts Options) error
+}
+
+// Remainder matches the rest of the input (at least one value) and hydrates the options with the fn
+func Remainder(label string, fn func(values []string, opts Options) error) Rule {
+	return remainder{label: label, fn: fn}
+}
+
+func (r remainder) first() []string {
+	return nil
+}
+
+func (r remainder) parse(s *state) error {
+	values := s.remaining()
+	if len(values) == 0 {
+		return s.expected(r.label)
+	}
+	s.pos = len(s.input)
+	return r.fn(values, s.opts)
+}
+
+func (r remainder) usage() []string {
+	return []string{r.label}
+}
+
+type arguments struct {
+	allowed args.Args
+}
+
+// Args matches the rest of the input as optional key=value arguments
+// only the allowed arguments are accepted
+func Args(allowed ...args.Arg) Rule {
+	return arguments{allowed: allowed}
+}
+
+func (a arguments) first() []string {
+	return nil
+}
+
+func (a arguments) parse(s *state) error {
+	for {
+		tok, ok := s.peek()
+		if !ok {
+			return nil
+		}
+		argKV := strings.SplitN(tok, "=", 2)
+		if len(argKV) != 2 || !a.isAllowed(argKV[0]) {
+			return s.expected("{{ arg=value }}")
+		}
+		suppliedArg := args.ResolveArgumentKV(argKV)
+		if suppliedArg == nil {
+			return fmt.Errorf("invalid arg `%s` at position %d", tok, s.pos+1)
+		}
+		s.opts[suppliedArg.Name()] = suppliedArg.Value()
+		s.next()
+	}
+}
+
+func (a arguments) isAllowed(name string) bool {
+	for _, arg := range a.allowed {
+		if strings.EqualFold(arg.Name(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a arguments) usage() []string {
+	return []string{"", "{{ arg=value }}"}
+}
+
+type seq struct {
+	rules []Rule
+}
+
+// Seq matches the rules in order
+func Seq(rules ...Rule) Rule {
+	return seq{rules: rules}
+}
+
+// Clause is a keyword followed by its rules (i.e. `in {{ namespace }} {{ environment }}`)
+func Clause(word string, rules ...Rule) Rule {
+	return seq{rules: append([]Rule{Keyword(word)}, rules...)}
+}
+
+func (q seq) first() []string {
+	if len(q.rules) == 0 {
+		return nil
+	}
+	return q.rules[0].first()
+}
+
+func (q seq) parse(s *state) error {
+	for _, r := range q.rules {
+		if err := r.parse(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q seq) usage() []string {
+	result := []string{""}
+	for _, r := range q.rules {
+		var combined []string
+		for _, prefix := range result {
+			for _, u := range r.usage() {
+				combined = append(combined, strings.TrimSpace(prefix+" "+u))
+			}
+		}
+		result = combined
+	}
+	return result
+}
+
+type oneOf struct {
+	rules []Rule
+}
+
+// OneOf matches the first rule that starts with the next keyword
+func OneOf(rules ...Rule) Rule {
+	return oneOf{rules: rules}
+}
+
+func (o oneOf) first() []string {
+	var result []string
+	for _, r := range o.rules {
+		if r.first() == nil {
+			return nil
+		}
+		result = append(result, r.first()...)
+	}
+	return result
+}
+
+func (o oneOf) parse(s *state) error {
+	for _, r := range o.rules {
+		if r.first() != nil && s.matches(r) {
+			return r.parse(s)
+		}
+	}
+	// rules that start with a value are the fallback
+	for _, r := range o.rules {
+		if r.first() == nil && s.matches(r) {
+			return r.parse(s)
+		}
+	}
+	// the first rule that can be completed with the defaults (i.e. a Defaulted clause)
+	if len(s.defaults) > 0 {
+		for _, r := range o.rules {
+			child := s.child()
+			if err := r.parse(child); err == nil {
+				s.merge(child)
+				return nil
+			}
+		}
+	}
+	return s.expectedKeyword(o.keywords(), o.first())
+}
+
+func (o oneOf) keywords() []string {
+	var result []string
+	for _, r := range o.rules {
+		if r.first() != nil {
+			result = append(result, r.first()[0])
+		}
+	}
+	return result
+}
+
+func (o oneOf) usage() []string {
+	var result []string
+	for _, r := range o.rules {
+		result = append(result, r.usage()...)
+	}
+	return result
+}
+
+type optional struct {
+	rule         Rule
+	defaults     Options
+	fromDefaults bool
+}
+
+// Optional matches the rules when the input starts with the first rule
+// an error after the optional rules were started is returned with the options resolved so far
+func Optional(rules ...Rule) *optional {
+	return &optional{rule: Seq(rules...)}
+}
+
+// Or sets the option value when the optional rules aren't in the input
+func (o *optional) Or(key string, value interface{}) *optional {
+	if o.defaults == nil {
+		o.defaults = make(Options)
+	}
+	o.defaults[key] = value
+	return o
+}
+
+// OrDefault fills in the optional rules with the default option values of the parse when they aren't in the input (see Complete)
+func (o *optional) OrDefault() *optional {
+	o.fromDefaults = true
+	return o
+}
+
+func (o *optional) first() []string {
+	return o.rule.first()
+}
+
+func (o *optional) parse(s *state) error {
+	if !s.matches(o.rule) {
+		o.skip(s)
+		return nil
+	}
+	child := s.child()
+	if err := o.rule.parse(child); err != nil {
+		if _, partial := err.(partialError); partial {
+			return err
+		}
+		return partialError{err}
+	}
+	s.merge(child)
+	return nil
+}
+
+func (o *optional) skip(s *state) {
+	for k, v := range o.defaults {
+		s.opts[k] = v
+	}
+	if o.fromDefaults {
+		s.fill(o.rule)
+	}
+}
+
+func (o *optional) usage() []string {
+	return append([]string{""}, o.rule.usage()...)
+}
+
+type anyOrder struct {
+	rules []Rule
+}
+
+// AnyOrder matches the keyword clauses in any order, each clause at most once
+// the clauses that aren't Optional are required
+func AnyOrder(clauses ...Rule) Rule {
+	return anyOrder{rules: clauses}
+}
+
+func (a anyOrder) first() []string {
+	var result []string
+	for _, r := range a.rules {
+		result = append(result, r.first()...)
+	}
+	return result
+}
+
+func (a anyOrder) parse(s *state) error {
+	matched := make([]bool, len(a.rules))
+	for {
+		next := -1
+		for i, r := range a.rules {
+			if !matched[i] && s.matches(r) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		if err := a.rules[next].parse(s); err != nil {
+			return err
+		}
+		matched[next] = true
+	}
+
+	for i, r := range a.rules {
+		if matched[i] {
+			continue
+		}
+		if o, ok := r.(*optional); ok {
+			o.skip(s)
+			continue
+		}
+		if d, ok := r.(defaulted); ok {
+			if err := d.parse(s); err != nil {
+				return err
+			}
+			continue
+		}
+		return s.expectedKeyword(r.first(), r.first())
+	}
+	return nil
+}
+
+func (a anyOrder) usage() []string {
+	return seq(a).usage()
+}
+
+type defaulted struct {
+	rule Rule
+}
+
+// Defaulted matches the rule or, when the input doesn't have it, fills it in with the default option values of the parse
+// (see Complete), the rule params are labeled {{ key }} (i.e. `in {{ namespace }} {{ environment }}`)
+// the rules that start with a value are filled in when they don't parse
+func Defaulted(rule Rule) Rule {
+	return defaulted{rule: rule}
+}
+
+func (d defaulted) first() []string {
+	return d.rule.first()
+}
+
+func (d defaulted) parse(s *state) error {
+	if d.rule.first() != nil {
+		if s.matches(d.rule) || !s.fill(d.rule) {
+			return d.rule.parse(s)
+		}
+		return nil
+	}
+	child := s.child()
+	err := d.rule.parse(child)
+	if err == nil {
+		s.merge(child)
+		return nil
+	}
+	if s.fill(d.rule) {
+		return nil
+	}
+	return err
+}
+
+func (d defaulted) usage() []string {
+	return d.rule.usage()
+}
@@ -2,10 +2,14 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/changelog"
 	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/kube"
 	"github.com/unanet/eve/pkg/eve"
 )
 
@@ -24,12 +28,16 @@ type ChatProvider interface {
 	ShowResultsMessageThread(ctx context.Context, msg, user, channel, ts string)
 	ReleaseResultsMessageThread(ctx context.Context, msg, user, channel, ts string)
 	PostPrivateMessage(ctx context.Context, msg string, user string)
+	ConfirmationMessageThread(ctx context.Context, msg, user, channel, ts, confirmationID string)
+	GetFile(ctx context.Context, fileID string) (*chatmodels.File, error)
+	DownloadFile(ctx context.Context, url string, maxBytes int64) ([]byte, error)
+	UploadFileThread(ctx context.Context, filename string, content []byte, msg, user, channel, ts string)
 }
 
 // EveAPI interface used to interface with eve/pipeline API
-// TODO: clean up this interface with more generic calls (GET,PUT,POST,DELETE,PATCH with interfaces{})
+// (the eveapi.Client implements the calls on top of its generic Get/Post/Put/Patch/Delete requests)
 type EveAPI interface {
-	Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, slackUser, slackChannel, ts string) (*eve.DeploymentPlanOptions, error)
+	Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, deploymentID string) (*eve.DeploymentPlanOptions, error)
 	GetEnvironmentByID(ctx context.Context, id string) (*eve.Environment, error)
 	GetEnvironments(ctx context.Context) ([]eve.Environment, error)
 	GetNamespacesByEnvironment(ctx context.Context, environmentName string) ([]eve.Namespace, error)
@@ -44,13 +52,91 @@ type EveAPI interface {
 	GetMetadata(ctx context.Context, key string) (eve.Metadata, error)
 	UpsertMergeMetadata(context.Context, eve.Metadata) (eve.Metadata, error)
 	UpsertMetadataServiceMap(context.Context, eve.MetadataServiceMap) (eve.MetadataServiceMap, error)
+	GetMetadataServiceMaps(ctx context.Context, metadataID int) ([]eve.MetadataServiceMap, error)
+	GetServiceMetadataMaps(ctx context.Context, serviceID int) ([]eve.MetadataServiceMap, error)
 	DeleteMetadataKey(ctx context.Context, id int, key string) (eve.Metadata, error)
 	GetNamespaceJobs(ctx context.Context, ns *eve.Namespace) ([]eve.Job, error)
 }
 
+// EveAPIStatus interface used to check the eve-api availability (implemented by the eveapi.Client circuit breaker)
+type EveAPIStatus interface {
+	UnavailableSince() (time.Time, bool)
+}
+
+// EveAPICache interface used to invalidate the cached eve-api resources (implemented by the eveapi.CachedClient)
+type EveAPICache interface {
+	Invalidate(resources ...string)
+}
+
+// AliasStore interface used to persist the user defined command aliases
+type AliasStore interface {
+	SaveAlias(ctx context.Context, alias datastore.Alias) error
+	ReadAlias(ctx context.Context, owner, name string) (*datastore.Alias, error)
+	ListAliases(ctx context.Context, owner string) ([]datastore.Alias, error)
+	DeleteAlias(ctx context.Context, owner, name string) error
+}
+
+// ChannelContextStore interface used to persist the default namespace/environment of a channel
+type ChannelContextStore interface {
+	SaveChannelContext(ctx context.Context, cc datastore.ChannelContext) error
+	ReadChannelContext(ctx context.Context, channelID string) (*datastore.ChannelContext, error)
+	DeleteChannelContext(ctx context.Context, channelID string) error
+}
+
+// MetadataHistoryStore interface used to persist the metadata revisions (the snapshots before every change)
+type MetadataHistoryStore interface {
+	SaveMetadataRevision(ctx context.Context, rev datastore.MetadataRevision) (datastore.MetadataRevision, error)
+	ListMetadataRevisions(ctx context.Context, key string, limit int) ([]datastore.MetadataRevision, error)
+	ReadMetadataRevision(ctx context.Context, key string, revision int) (*datastore.MetadataRevision, error)
+}
+
+// DeploymentStore interface used to persist the deployment requests (correlated with the eve-api callbacks)
+type DeploymentStore interface {
+	SaveDeployment(ctx context.Context, d datastore.Deployment) error
+	ReadDeployment(ctx context.Context, id string) (*datastore.Deployment, error)
+	UpdateDeploymentPlanIDs(ctx context.Context, id string, planIDs []string) error
+	RecordDeploymentCallback(ctx context.Context, id string, plan eve.NSDeploymentPlan) (*datastore.Deployment, error)
+	ListDeployments(ctx context.Context, filter datastore.DeploymentFilter) ([]datastore.Deployment, error)
+	ListOverdueDeployments(ctx context.Context, now time.Time) ([]datastore.Deployment, error)
+	MarkDeploymentOverdue(ctx context.Context, id string, at time.Time) error
+}
+
+// SubscriptionStore interface used to persist the deployment subscriptions
+type SubscriptionStore interface {
+	SaveSubscription(ctx context.Context, sub datastore.Subscription) error
+	ListSubscriptions(ctx context.Context, owner string) ([]datastore.Subscription, error)
+	ListAllSubscriptions(ctx context.Context) ([]datastore.Subscription, error)
+	DeleteSubscription(ctx context.Context, owner, key string) error
+}
+
+// ConfirmationStore interface used to persist the pending confirmations
+type ConfirmationStore interface {
+	SaveConfirmation(ctx context.Context, c datastore.Confirmation) error
+	ReadConfirmation(ctx context.Context, id string) (*datastore.Confirmation, error)
+	TakeConfirmation(ctx context.Context, id string) (*datastore.Confirmation, error)
+}
+
+// CronStateStore interface used to share the cron error suppressions and digests between the eve-bot instances
+type CronStateStore interface {
+	SuppressCronError(ctx context.Context, key string, now time.Time, window time.Duration) (*datastore.CronSuppression, bool, error)
+	AddCronDigest(ctx context.Context, channel string, entry datastore.CronDigestEntry, expiresAt time.Time) error
+	TakeCronDigests(ctx context.Context, before time.Time) ([]datastore.CronDigest, error)
+}
+
+// ChangelogProvider interface used to summarize the changes of an artifact between two versions (optional)
+type ChangelogProvider interface {
+	Changelog(ctx context.Context, artifact, from, to string) (*changelog.Changes, error)
+}
+
+// KubernetesProvider interface used to read the pods and the logs of the services (optional)
+type KubernetesProvider interface {
+	Pods(ctx context.Context, ns eve.Namespace, service string) ([]kube.Pod, error)
+	Logs(ctx context.Context, ns eve.Namespace, service string, opts kube.LogOptions) ([]byte, error)
+}
+
 // CommandResolver resolves the input and returns an EvebotCommand (Invalid command instead of an error for error cases)
 type CommandResolver interface {
-	Resolve(input, channel, user string) commands.EvebotCommand
+	Resolve(ctx context.Context, input, channel, user string) commands.EvebotCommand
 }
 
 // CommandExecutor interface takes an EvebotCommand and Executes a matching handler
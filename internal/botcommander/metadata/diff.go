@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// ChangeType is the type of a metadata key change
+type ChangeType string
+
+const (
+	// Added is a new key
+	Added ChangeType = "+"
+	// Changed is an existing key with a new value
+	Changed ChangeType = "~"
+	// Removed is a deleted key
+	Removed ChangeType = "-"
+)
+
+// Change is the change of a single metadata key
+type Change struct {
+	Key      string
+	Type     ChangeType
+	Old, New interface{}
+}
+
+// Changes are the metadata changes (sorted by key)
+type Changes []Change
+
+// Diff returns the changes from the current to the next metadata value
+func Diff(current, next eve.MetadataField) Changes {
+	var result Changes
+	for k, v := range next {
+		old, ok := current[k]
+		switch {
+		case !ok:
+			result = append(result, Change{Key: k, Type: Added, New: v})
+		case !reflect.DeepEqual(old, v):
+			result = append(result, Change{Key: k, Type: Changed, Old: old, New: v})
+		}
+	}
+	for k, v := range current {
+		if _, ok := next[k]; !ok {
+			result = append(result, Change{Key: k, Type: Removed, Old: v})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// Merge returns the current metadata value with the update merged in (the way eve merges the metadata)
+func Merge(current, update eve.MetadataField) eve.MetadataField {
+	result := make(eve.MetadataField, len(current)+len(update))
+	for k, v := range current {
+		result[k] = v
+	}
+	for k, v := range update {
+		result[k] = v
+	}
+	return result
+}
+
+// Stack merges the metadata layers (lowest stacking order first) the way eve merges the service metadata:
+// the nested objects are merged and every other value of a later layer overrides the earlier one
+func Stack(layers ...eve.MetadataField) eve.MetadataField {
+	result := make(eve.MetadataField)
+	for _, layer := range layers {
+		result = eve.MetadataField(deepMerge(result, layer))
+	}
+	return result
+}
+
+func deepMerge(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := dst[k].(map[string]interface{})
+		if srcOK && dstOK {
+			dst[k] = deepMerge(copyMap(dstMap), srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// String formats the changes as a diff (one key per line)
+func (c Changes) String() string {
+	if len(c) == 0 {
+		return "no changes"
+	}
+	var builder strings.Builder
+	for _, change := range c {
+		switch change.Type {
+		case Added:
+			builder.WriteString(fmt.Sprintf("+ %s: %s\n", change.Key, formatValue(change.New)))
+		case Changed:
+			builder.WriteString(fmt.Sprintf("~ %s: %s → %s\n", change.Key, formatValue(change.Old), formatValue(change.New)))
+		case Removed:
+			builder.WriteString(fmt.Sprintf("- %s: %s\n", change.Key, formatValue(change.Old)))
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func formatValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// Select returns the metadata value with only the keys, and the keys that aren't in the metadata value
+func Select(field eve.MetadataField, keys []string) (eve.MetadataField, []string) {
+	result := make(eve.MetadataField, len(keys))
+	var missing []string
+	for _, k := range keys {
+		v, ok := field[k]
+		if !ok {
+			missing = append(missing, k)
+			continue
+		}
+		result[k] = v
+	}
+	return result, missing
+}
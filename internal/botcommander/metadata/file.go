@@ -0,0 +1,129 @@
+// Package metadata parses the metadata files (json, yaml, env) and diffs the metadata values
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unanet/eve/pkg/eve"
+	"gopkg.in/yaml.v3"
+)
+
+// Limits are the limits for the uploaded metadata files
+type Limits struct {
+	MaxBytes int64
+	MaxKeys  int
+}
+
+// SupportedExtensions are the supported metadata file extensions
+var SupportedExtensions = []string{".json", ".yaml", ".yml", ".env"}
+
+// IsSupported checks if the file name has a supported metadata file extension
+func IsSupported(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, supported := range SupportedExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFile parses the metadata file content (the format is based on the file extension)
+func ParseFile(name string, content []byte, limits Limits) (eve.MetadataField, error) {
+	if limits.MaxBytes > 0 && int64(len(content)) > limits.MaxBytes {
+		return nil, fmt.Errorf("metadata file `%s` is too large (%d bytes), the limit is %d bytes", name, len(content), limits.MaxBytes)
+	}
+
+	var field eve.MetadataField
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		field, err = parseJSON(content)
+	case ".yaml", ".yml":
+		field, err = parseYAML(content)
+	case ".env":
+		field, err = parseEnv(content)
+	default:
+		return nil, fmt.Errorf("unsupported metadata file `%s`, expected one of: %s", name, strings.Join(SupportedExtensions, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata file `%s`: %w", name, err)
+	}
+
+	if len(field) == 0 {
+		return nil, fmt.Errorf("metadata file `%s` doesn't contain any keys", name)
+	}
+	if limits.MaxKeys > 0 && len(field) > limits.MaxKeys {
+		return nil, fmt.Errorf("metadata file `%s` has too many keys (%d), the limit is %d", name, len(field), limits.MaxKeys)
+	}
+	return field, nil
+}
+
+func parseJSON(content []byte) (eve.MetadataField, error) {
+	var field eve.MetadataField
+	if err := json.Unmarshal(content, &field); err != nil {
+		return nil, fmt.Errorf("expected a json object: %w", err)
+	}
+	return field, nil
+}
+
+func parseYAML(content []byte) (eve.MetadataField, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("expected a yaml mapping: %w", err)
+	}
+	// round trip the values through json, so the types match the values returned by eve
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported yaml value: %w", err)
+	}
+	return parseJSON(b)
+}
+
+// parseEnv parses the KEY=VALUE lines (blank lines, # comments and the export prefix are ignored)
+func parseEnv(content []byte) (eve.MetadataField, error) {
+	field := make(eve.MetadataField)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		kv := strings.SplitN(text, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || len(key) == 0 || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("expected KEY=VALUE at line %d", line)
+		}
+		value, err := envValue(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value at line %d: %w", line, err)
+		}
+		field[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func envValue(raw string) (string, error) {
+	switch {
+	case len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"':
+		return strconv.Unquote(raw)
+	case len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'':
+		return raw[1 : len(raw)-1], nil
+	}
+	// strip the inline comment of an unquoted value
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	return raw, nil
+}
@@ -0,0 +1,32 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/unanet/eve/pkg/eve"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// JSONFormat is the json file format
+	JSONFormat = "json"
+	// YAMLFormat is the yaml file format
+	YAMLFormat = "yaml"
+)
+
+// Marshal marshals the metadata value in the file format (json or yaml)
+// the marshaled value can be uploaded again as a metadata file
+func Marshal(field eve.MetadataField, format string) ([]byte, error) {
+	if field == nil {
+		field = eve.MetadataField{}
+	}
+	switch format {
+	case JSONFormat:
+		return json.MarshalIndent(field, "", "  ")
+	case YAMLFormat:
+		return yaml.Marshal(map[string]interface{}(field))
+	default:
+		return nil, fmt.Errorf("unsupported metadata format `%s`", format)
+	}
+}
@@ -0,0 +1,185 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestParseFile(t *testing.T) {
+	limits := Limits{MaxBytes: 256, MaxKeys: 3}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    eve.MetadataField
+		wantErr string
+	}{
+		{
+			name:    "json",
+			file:    "api.json",
+			content: `{"url": "https://api", "replicas": 2, "flags": {"beta": true}}`,
+			want:    eve.MetadataField{"url": "https://api", "replicas": float64(2), "flags": map[string]interface{}{"beta": true}},
+		},
+		{
+			name:    "yaml",
+			file:    "api.YAML",
+			content: "url: https://api\nreplicas: 2\nhosts:\n  - a\n  - b\n",
+			want:    eve.MetadataField{"url": "https://api", "replicas": float64(2), "hosts": []interface{}{"a", "b"}},
+		},
+		{
+			name:    "env",
+			file:    ".env",
+			content: "# comment\n\nexport URL=https://api?a=b # inline\nMSG=\"say \\\"hi\\\"\"\nRAW='a # b'\n",
+			want:    eve.MetadataField{"URL": "https://api?a=b", "MSG": `say "hi"`, "RAW": "a # b"},
+		},
+		{
+			name:    "json array",
+			file:    "api.json",
+			content: `["a"]`,
+			wantErr: "invalid metadata file `api.json`: expected a json object: json: cannot unmarshal array into Go value of type eve.MetadataField",
+		},
+		{
+			name:    "invalid env line",
+			file:    "api.env",
+			content: "URL=https://api\nbroken line\n",
+			wantErr: "invalid metadata file `api.env`: expected KEY=VALUE at line 2",
+		},
+		{
+			name:    "empty",
+			file:    "api.yaml",
+			content: "",
+			wantErr: "metadata file `api.yaml` doesn't contain any keys",
+		},
+		{
+			name:    "too many keys",
+			file:    "api.env",
+			content: "A=1\nB=2\nC=3\nD=4\n",
+			wantErr: "metadata file `api.env` has too many keys (4), the limit is 3",
+		},
+		{
+			name:    "too large",
+			file:    "api.json",
+			content: string(make([]byte, 257)),
+			wantErr: "metadata file `api.json` is too large (257 bytes), the limit is 256 bytes",
+		},
+		{
+			name:    "unsupported",
+			file:    "api.txt",
+			content: "A=1",
+			wantErr: "unsupported metadata file `api.txt`, expected one of: .json, .yaml, .yml, .env",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFile(tt.file, []byte(tt.content), limits)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ParseFile() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFile() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFile() got = %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := eve.MetadataField{"a": "1", "b": float64(2), "c": true}
+	next := Merge(current, eve.MetadataField{"b": float64(3), "d": "new"})
+	delete(next, "c")
+
+	changes := Diff(current, next)
+	want := Changes{
+		{Key: "b", Type: Changed, Old: float64(2), New: float64(3)},
+		{Key: "c", Type: Removed, Old: true},
+		{Key: "d", Type: Added, New: "new"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Diff() got = %v\nwant %v", changes, want)
+	}
+	if got, want := changes.String(), "~ b: 2 → 3\n- c: true\n+ d: \"new\""; got != want {
+		t.Errorf("String() got = %q, want %q", got, want)
+	}
+	if got := Diff(current, current).String(); got != "no changes" {
+		t.Errorf("String() got = %q, want no changes", got)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	field := eve.MetadataField{"url": "https://api", "replicas": float64(2), "flags": map[string]interface{}{"beta": true}}
+	for _, format := range []string{JSONFormat, YAMLFormat} {
+		t.Run(format, func(t *testing.T) {
+			b, err := Marshal(field, format)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error = %v", err)
+			}
+			got, err := ParseFile("metadata."+format, b, Limits{})
+			if err != nil {
+				t.Fatalf("ParseFile() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, field) {
+				t.Errorf("round trip got = %v\nwant %v", got, field)
+			}
+		})
+	}
+	if _, err := Marshal(field, "xml"); err == nil {
+		t.Errorf("Marshal() expected an error for an unsupported format")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	got, missing := Select(eve.MetadataField{"a": "1", "b": "2", "c": "3"}, []string{"a", "c", "d"})
+	if want := (eve.MetadataField{"a": "1", "c": "3"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Select() got = %v, want %v", got, want)
+	}
+	if want := []string{"d"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("Select() missing = %v, want %v", missing, want)
+	}
+}
+
+func TestStack(t *testing.T) {
+	env := eve.MetadataField{"log_level": "info", "db": map[string]interface{}{"host": "db", "port": float64(5432)}}
+	ns := eve.MetadataField{"db": map[string]interface{}{"host": "ns-db"}}
+	svc := eve.MetadataField{"log_level": "debug"}
+
+	got := Stack(env, ns, svc)
+	want := eve.MetadataField{"log_level": "debug", "db": map[string]interface{}{"host": "ns-db", "port": float64(5432)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stack() = %v, want %v", got, want)
+	}
+	if env["db"].(map[string]interface{})["host"] != "db" {
+		t.Errorf("Stack() changed a layer")
+	}
+}
+
+func TestEffective(t *testing.T) {
+	env := Layer{Name: "eve-bot-environment:int", StackingOrder: 200, Value: eve.MetadataField{"log_level": "info", "db": map[string]interface{}{"host": "db", "port": float64(5432)}}}
+	ns := Layer{Name: "eve-bot-namespace:current-int", StackingOrder: 300, Value: eve.MetadataField{"db": map[string]interface{}{"host": "ns-db"}}}
+	svc := Layer{Name: "eve-bot:api:current-int", StackingOrder: 400, Value: eve.MetadataField{"log_level": "debug", "feature": true}}
+
+	effective, origins := Effective(env, ns, svc)
+	if want := Stack(env.Value, ns.Value, svc.Value); !reflect.DeepEqual(effective, want) {
+		t.Errorf("Effective() = %v, want %v", effective, want)
+	}
+
+	var got []string
+	for _, o := range origins {
+		got = append(got, o.String())
+	}
+	want := []string{
+		"db.host: \"ns-db\" ← eve-bot-namespace:current-int (300), overrides eve-bot-environment:int (200)",
+		"db.port: 5432 ← eve-bot-environment:int (200)",
+		"feature: true ← eve-bot:api:current-int (400)",
+		"log_level: \"debug\" ← eve-bot:api:current-int (400), overrides eve-bot-environment:int (200)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Effective() origins =\n%v\nwant\n%v", got, want)
+	}
+}
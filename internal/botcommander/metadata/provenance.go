@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// Layer is a metadata record mapped to a service (i.e. the eve-bot, namespace or environment metadata)
+type Layer struct {
+	Name          string
+	StackingOrder int
+	Value         eve.MetadataField
+}
+
+func (l Layer) String() string {
+	return fmt.Sprintf("%s (%d)", l.Name, l.StackingOrder)
+}
+
+// Origin is an effective metadata value with the layer that won and the layers it overrides (highest stacking order first)
+// the nested values are reported by their path (i.e. db.host)
+type Origin struct {
+	Key       string
+	Value     interface{}
+	Layer     Layer
+	Overrides []Layer
+}
+
+// Effective merges the layers (lowest stacking order first) and returns the effective metadata
+// with the origin of every value (sorted by key)
+func Effective(layers ...Layer) (eve.MetadataField, []Origin) {
+	values := make([]eve.MetadataField, len(layers))
+	for i, l := range layers {
+		values[i] = l.Value
+	}
+	effective := Stack(values...)
+
+	var origins []Origin
+	walkValues(effective, nil, func(path []string, v interface{}) {
+		o := Origin{Key: strings.Join(path, "."), Value: v}
+		won := false
+		for i := len(layers) - 1; i >= 0; i-- {
+			if !hasPath(layers[i].Value, path) {
+				continue
+			}
+			// the origins only reference the layers (without their values)
+			l := Layer{Name: layers[i].Name, StackingOrder: layers[i].StackingOrder}
+			if !won {
+				o.Layer, won = l, true
+				continue
+			}
+			o.Overrides = append(o.Overrides, l)
+		}
+		origins = append(origins, o)
+	})
+	sort.Slice(origins, func(i, j int) bool { return origins[i].Key < origins[j].Key })
+	return effective, origins
+}
+
+// walkValues calls fn with every value that isn't a (non empty) nested object
+func walkValues(m map[string]interface{}, path []string, fn func(path []string, v interface{})) {
+	for k, v := range m {
+		p := append(append([]string{}, path...), k)
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			walkValues(nested, p, fn)
+			continue
+		}
+		fn(p, v)
+	}
+}
+
+func hasPath(m map[string]interface{}, path []string) bool {
+	for i, k := range path {
+		v, ok := m[k]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if m, ok = v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// String formats the origins (one key per line)
+func (o Origin) String() string {
+	msg := fmt.Sprintf("%s: %s ← %s", o.Key, formatValue(o.Value), o.Layer)
+	if len(o.Overrides) > 0 {
+		var overrides []string
+		for _, l := range o.Overrides {
+			overrides = append(overrides, l.String())
+		}
+		msg += fmt.Sprintf(", overrides %s", strings.Join(overrides, ", "))
+	}
+	return msg
+}
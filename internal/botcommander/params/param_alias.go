@@ -0,0 +1,34 @@
+package params
+
+const (
+	// AliasName param key/id
+	AliasName = "alias"
+)
+
+// Alias param data struct
+type Alias struct {
+	baseParam
+}
+
+// Name satisfies the param interface and returns the Alias Name
+func (e Alias) Name() string {
+	return e.name
+}
+
+// Description satisfies the param interface and returns the Alias Description
+func (e Alias) Description() string {
+	return e.description
+}
+
+// Value satisfies the param interface and returns the Alias Value
+func (e Alias) Value() string {
+	return e.value
+}
+
+// DefaultAlias is the default Alias (used for help/init)
+func DefaultAlias() Alias {
+	return Alias{baseParam{
+		name:        AliasName,
+		description: "the name of a command shortcut (i.e. shipit)",
+	}}
+}
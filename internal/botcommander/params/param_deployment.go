@@ -0,0 +1,26 @@
+package params
+
+const (
+	// DeploymentName param key/id
+	DeploymentName = "deployment"
+)
+
+// Deployment param data struct
+type Deployment struct {
+	baseParam
+}
+
+// Name satisfies the param interface and returns the Deployment Name
+func (e Deployment) Name() string {
+	return e.name
+}
+
+// Description satisfies the param interface and returns the Deployment Description
+func (e Deployment) Description() string {
+	return e.description
+}
+
+// Value satisfies the param interface and returns the Deployment Value
+func (e Deployment) Value() string {
+	return e.value
+}
@@ -0,0 +1,143 @@
+package resolver
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// maxAliasDepth is how many times an alias can expand to another alias
+const maxAliasDepth = 8
+
+var placeholderMatcher = regexp.MustCompile(`\$([0-9]+|[a-zA-Z_][a-zA-Z0-9_]*)`)
+
+// expandAlias expands the user (or channel) alias in the first field until it resolves to a built-in command
+// nil fields (and no error) are returned when the first field isn't an alias
+func (ebr *EvebotResolver) expandAlias(ctx context.Context, fields []string, channel, user string) ([]string, error) {
+	if ebr.aliases == nil {
+		return nil, nil
+	}
+
+	var chain []string
+	for {
+		name := fields[0]
+		if len(chain) > 0 && ebr.cmdFactory.Items()[name] != nil {
+			return fields, nil
+		}
+
+		for _, previous := range chain {
+			if previous == name {
+				return nil, fmt.Errorf("alias cycle detected: %s -> %s", strings.Join(chain, " -> "), name)
+			}
+		}
+		if len(chain) >= maxAliasDepth {
+			return nil, fmt.Errorf("alias `%s` is nested too deep: %s", chain[0], strings.Join(chain, " -> "))
+		}
+
+		alias, err := ebr.lookupAlias(ctx, name, channel, user)
+		if err != nil {
+			return nil, err
+		}
+		if alias == nil {
+			if len(chain) == 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("alias `%s` expands to an unknown command: `%s`", chain[0], name)
+		}
+
+		chain = append(chain, name)
+		if fields, err = expandTemplate(alias.Name, alias.Command, fields[1:]); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// lookupAlias returns the user alias, falling back to the channel alias (nil when neither exist)
+func (ebr *EvebotResolver) lookupAlias(ctx context.Context, name, channel, user string) (*datastore.Alias, error) {
+	owners := []string{
+		datastore.AliasOwner(datastore.AliasScopeUser, user),
+		datastore.AliasOwner(datastore.AliasScopeChannel, channel),
+	}
+	for _, owner := range owners {
+		alias, err := ebr.aliases.ReadAlias(ctx, owner, name)
+		if err == nil {
+			return alias, nil
+		}
+		if !goerrors.Is(err, errors.ErrNotFound) {
+			return nil, fmt.Errorf("unable to resolve alias `%s`", name)
+		}
+	}
+	return nil, nil
+}
+
+// expandTemplate fills the alias template placeholders with the invocation args
+//
+//	positional placeholders ($1, $2) are filled in order
+//	named placeholders ($env) are filled with env=value args
+//
+// the template is split with the tokenizer before it is filled, so that the quoted template values
+// and the args with whitespace stay a single field
+// args that don't fill a placeholder are appended to the expanded command
+func expandTemplate(name, template string, args []string) ([]string, error) {
+	referenced := make(map[string]bool)
+	for _, match := range placeholderMatcher.FindAllStringSubmatch(template, -1) {
+		referenced[match[1]] = true
+	}
+
+	named := make(map[string]string)
+	var positional []string
+	for _, arg := range args {
+		if kv := strings.SplitN(arg, "=", 2); len(kv) == 2 && referenced[kv[0]] && !isPositional(kv[0]) {
+			named[kv[0]] = kv[1]
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	var missing []string
+	consumed := make(map[int]bool)
+	fill := func(placeholder string) string {
+		key := placeholder[1:]
+		if isPositional(key) {
+			i, _ := strconv.Atoi(key)
+			if i < 1 || i > len(positional) {
+				missing = append(missing, placeholder)
+				return placeholder
+			}
+			consumed[i] = true
+			return positional[i-1]
+		}
+		if val, ok := named[key]; ok {
+			return val
+		}
+		missing = append(missing, placeholder)
+		return placeholder
+	}
+
+	var result []string
+	for _, field := range tokenizer.Split(template) {
+		result = append(result, placeholderMatcher.ReplaceAllStringFunc(field, fill))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("alias `%s` requires a value for: %s", name, strings.Join(missing, ", "))
+	}
+
+	for i, arg := range positional {
+		if !consumed[i+1] {
+			result = append(result, arg)
+		}
+	}
+	return result, nil
+}
+
+func isPositional(key string) bool {
+	_, err := strconv.Atoi(key)
+	return err == nil
+}
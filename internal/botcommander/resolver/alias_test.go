@@ -0,0 +1,204 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/go/pkg/errors"
+)
+
+type fakeAliasStore map[string]datastore.Alias
+
+func (f fakeAliasStore) SaveAlias(ctx context.Context, alias datastore.Alias) error {
+	f[alias.Owner+"/"+alias.Name] = alias
+	return nil
+}
+
+func (f fakeAliasStore) ReadAlias(ctx context.Context, owner, name string) (*datastore.Alias, error) {
+	if alias, ok := f[owner+"/"+name]; ok {
+		return &alias, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (f fakeAliasStore) ListAliases(ctx context.Context, owner string) ([]datastore.Alias, error) {
+	var result []datastore.Alias
+	for _, alias := range f {
+		if alias.Owner == owner {
+			result = append(result, alias)
+		}
+	}
+	return result, nil
+}
+
+func (f fakeAliasStore) DeleteAlias(ctx context.Context, owner, name string) error {
+	delete(f, owner+"/"+name)
+	return nil
+}
+
+func newFakeAliasStore(aliases ...datastore.Alias) fakeAliasStore {
+	store := make(fakeAliasStore)
+	for _, alias := range aliases {
+		_ = store.SaveAlias(context.Background(), alias)
+	}
+	return store
+}
+
+func Test_expandTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "no placeholders",
+			template: "show environments",
+			want:     []string{"show", "environments"},
+		},
+		{
+			name:     "positional placeholder",
+			template: "deploy current in $1 services=api,billing dryrun=true",
+			args:     []string{"una-int"},
+			want:     []string{"deploy", "current", "in", "una-int", "services=api,billing", "dryrun=true"},
+		},
+		{
+			name:     "named placeholder inside a token",
+			template: "deploy current in $env services=$svc",
+			args:     []string{"svc=api:1.2", "env=una-int"},
+			want:     []string{"deploy", "current", "in", "una-int", "services=api:1.2"},
+		},
+		{
+			name:     "unused args are appended",
+			template: "deploy current in $1",
+			args:     []string{"una-int", "dryrun=true"},
+			want:     []string{"deploy", "current", "in", "una-int", "dryrun=true"},
+		},
+		{
+			name:     "quoted values stay a single field",
+			template: `set metadata in current int msg="deploy freeze" by=$1`,
+			args:     []string{"ops team"},
+			want:     []string{"set", "metadata", "in", "current", "int", "msg=deploy freeze", "by=ops team"},
+		},
+		{
+			name:     "missing positional placeholder",
+			template: "deploy $1 in $2",
+			args:     []string{"current"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing named placeholder",
+			template: "show services in current $env",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTemplate("test", tt.template, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvebotResolver_ResolveAlias(t *testing.T) {
+	user := "dummy"
+	channel := "chanelID"
+	userOwner := datastore.AliasOwner(datastore.AliasScopeUser, user)
+	channelOwner := datastore.AliasOwner(datastore.AliasScopeChannel, channel)
+
+	store := newFakeAliasStore(
+		datastore.Alias{Owner: userOwner, Name: "shipit", Command: "deploy current in $1 dryrun=true"},
+		datastore.Alias{Owner: channelOwner, Name: "shipit", Command: "deploy latest in $1"},
+		datastore.Alias{Owner: channelOwner, Name: "showsvc", Command: "show services in current $env"},
+		datastore.Alias{Owner: userOwner, Name: "nested", Command: "shipit una-int"},
+		datastore.Alias{Owner: userOwner, Name: "loop-a", Command: "loop-b"},
+		datastore.Alias{Owner: userOwner, Name: "loop-b", Command: "loop-a"},
+		datastore.Alias{Owner: userOwner, Name: "broken", Command: "deployy current in int"},
+		datastore.Alias{Owner: userOwner, Name: "deploy", Command: "show environments"},
+	)
+	resolver := New(commands.NewFactory(), AliasStoreOpt(store))
+
+	tests := []struct {
+		name  string
+		input string
+		want  commands.EvebotCommand
+	}{
+		{
+			name:  "user alias wins over channel alias",
+			input: "@evebot shipit una-int",
+			want:  commands.NewExpandedCommand(commands.NewDeployCommand(strings.Fields("deploy current in una-int dryrun=true"), channel, user), strings.Fields("deploy current in una-int dryrun=true")),
+		},
+		{
+			name:  "channel alias with named placeholder",
+			input: "@evebot showsvc env=una-int",
+			want:  commands.NewExpandedCommand(commands.NewShowCommand(strings.Fields("show services in current una-int"), channel, user), strings.Fields("show services in current una-int")),
+		},
+		{
+			name:  "nested alias",
+			input: "@evebot nested",
+			want:  commands.NewExpandedCommand(commands.NewDeployCommand(strings.Fields("deploy current in una-int dryrun=true"), channel, user), strings.Fields("deploy current in una-int dryrun=true")),
+		},
+		{
+			name:  "built-in commands are never shadowed",
+			input: "@evebot deploy current in int",
+			want:  commands.NewDeployCommand(strings.Fields("deploy current in int"), channel, user),
+		},
+		{
+			name:  "unknown command",
+			input: "@evebot wtf does this do",
+			want:  commands.NewInvalidCommand(strings.Fields("wtf does this do"), channel, user),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := resolver.Resolve(context.Background(), tt.input, channel, user)
+			if !reflect.DeepEqual(cmd, tt.want) {
+				t.Errorf("\nA = %v\nB = %v", cmd, tt.want)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "alias cycle",
+			input:   "@evebot loop-a",
+			wantErr: "alias cycle detected: loop-a -> loop-b -> loop-a",
+		},
+		{
+			name:    "alias to unknown command",
+			input:   "@evebot broken",
+			wantErr: "alias `broken` expands to an unknown command: `deployy`",
+		},
+		{
+			name:    "missing placeholder",
+			input:   "@evebot shipit",
+			wantErr: "alias `shipit` requires a value for: $1",
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := resolver.Resolve(context.Background(), tt.input, channel, user)
+			msg, cont := cmd.AckMsg()
+			if cont {
+				t.Errorf("expected the alias error command to not continue")
+			}
+			if !strings.Contains(msg, tt.wantErr) {
+				t.Errorf("expected ack message to contain %q, got %q", tt.wantErr, msg)
+			}
+		})
+	}
+}
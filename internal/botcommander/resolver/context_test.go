@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/go/pkg/errors"
+)
+
+type fakeContextStore map[string]datastore.ChannelContext
+
+func (f fakeContextStore) SaveChannelContext(ctx context.Context, cc datastore.ChannelContext) error {
+	f[cc.ChannelID] = cc
+	return nil
+}
+
+func (f fakeContextStore) ReadChannelContext(ctx context.Context, channelID string) (*datastore.ChannelContext, error) {
+	if cc, ok := f[channelID]; ok {
+		return &cc, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (f fakeContextStore) DeleteChannelContext(ctx context.Context, channelID string) error {
+	delete(f, channelID)
+	return nil
+}
+
+func TestEvebotResolver_ResolveChannelContext(t *testing.T) {
+	user := "dummy"
+	channel := "chanelID"
+
+	contexts := fakeContextStore{
+		channel: datastore.ChannelContext{ChannelID: channel, Namespace: "current", Environment: "int"},
+	}
+	aliases := newFakeAliasStore(
+		datastore.Alias{Owner: datastore.AliasOwner(datastore.AliasScopeUser, user), Name: "svcs", Command: "show services"},
+	)
+	resolver := New(commands.NewFactory(), AliasStoreOpt(aliases), ChannelContextStoreOpt(contexts))
+
+	tests := []struct {
+		name    string
+		input   string
+		channel string
+		want    commands.EvebotCommand
+	}{
+		{
+			name:    "defaults filled in",
+			input:   "@evebot deploy services=api",
+			channel: channel,
+			want:    commands.NewExpandedCommand(commands.NewDeployCommand(strings.Fields("deploy current in int services=api"), channel, user), strings.Fields("deploy current in int services=api")),
+		},
+		{
+			name:    "explicit location wins",
+			input:   "@evebot show services in latest qa",
+			channel: channel,
+			want:    commands.NewShowCommand(strings.Fields("show services in latest qa"), channel, user),
+		},
+		{
+			name:    "defaults filled in after alias expansion",
+			input:   "@evebot svcs",
+			channel: channel,
+			want:    commands.NewExpandedCommand(commands.NewShowCommand(strings.Fields("show services in current int"), channel, user), strings.Fields("show services in current int")),
+		},
+		{
+			name:    "channel without context",
+			input:   "@evebot show services",
+			channel: "other",
+			want:    commands.NewShowCommand(strings.Fields("show services"), "other", user),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := resolver.Resolve(context.Background(), tt.input, tt.channel, user)
+			if !reflect.DeepEqual(cmd, tt.want) {
+				t.Errorf("\nA = %v\nB = %v", cmd, tt.want)
+			}
+		})
+	}
+}
+
+// countingContextStore counts the channel context reads
+type countingContextStore struct {
+	fakeContextStore
+	reads int
+}
+
+func (c *countingContextStore) ReadChannelContext(ctx context.Context, channelID string) (*datastore.ChannelContext, error) {
+	c.reads++
+	return c.fakeContextStore.ReadChannelContext(ctx, channelID)
+}
+
+func TestEvebotResolver_ResolveChannelContextReads(t *testing.T) {
+	channel := "chanelID"
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "missing location", input: "@evebot show services", want: 1},
+		{name: "explicit location", input: "@evebot show services in latest qa", want: 0},
+		{name: "help", input: "@evebot deploy help", want: 0},
+		{name: "alias command", input: "@evebot alias list", want: 0},
+		{name: "without a location", input: "@evebot show environments", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contexts := &countingContextStore{fakeContextStore: fakeContextStore{
+				channel: datastore.ChannelContext{ChannelID: channel, Namespace: "current", Environment: "int"},
+			}}
+			resolver := New(commands.NewFactory(), ChannelContextStoreOpt(contexts))
+			resolver.Resolve(context.Background(), tt.input, channel, "dummy")
+			if contexts.reads != tt.want {
+				t.Errorf("ReadChannelContext() calls = %d, want %d", contexts.reads, tt.want)
+			}
+		})
+	}
+}
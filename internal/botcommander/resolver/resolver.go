@@ -1,9 +1,15 @@
 package resolver
 
 import (
-	"strings"
+	"context"
+	goerrors "errors"
+
+	"github.com/unanet/go/pkg/errors"
 
 	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/botcommander/suggest"
+	"github.com/unanet/eve-bot/internal/botcommander/tokenizer"
+	"github.com/unanet/eve-bot/internal/tracing"
 
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
@@ -14,22 +20,47 @@ import (
 // EvebotResolver implements the Resolver interface
 type EvebotResolver struct {
 	cmdFactory commands.Factory
+	aliases    interfaces.AliasStore
+	contexts   interfaces.ChannelContextStore
+}
+
+// Option type for the dynamic resolver options
+type Option func(*EvebotResolver)
+
+// AliasStoreOpt enables the user defined command aliases
+func AliasStoreOpt(a interfaces.AliasStore) Option {
+	return func(ebr *EvebotResolver) {
+		ebr.aliases = a
+	}
+}
+
+// ChannelContextStoreOpt enables the channel default namespace/environment
+func ChannelContextStoreOpt(c interfaces.ChannelContextStore) Option {
+	return func(ebr *EvebotResolver) {
+		ebr.contexts = c
+	}
 }
 
 // New instantiates the Resolver
-func New(commandFactory commands.Factory) interfaces.CommandResolver {
-	return &EvebotResolver{
-		commandFactory,
+func New(commandFactory commands.Factory, opts ...Option) interfaces.CommandResolver {
+	ebr := &EvebotResolver{
+		cmdFactory: commandFactory,
+	}
+	for _, opt := range opts {
+		opt(ebr)
 	}
+	return ebr
 }
 
 // Resolve resolves the command input from the Chat User and returns an EvebotCommand
 // this is where all of the "magic" happens that basically translates a user command to an EveBot command
-func (ebr *EvebotResolver) Resolve(input, channel, user string) commands.EvebotCommand {
+func (ebr *EvebotResolver) Resolve(ctx context.Context, input, channel, user string) commands.EvebotCommand {
 	// parse the input string and break out into fields (array)
 	log.Logger.Info("resolve command", zap.String("input", input))
+	_, span := tracing.Start(ctx, "resolver.resolve")
+	defer span.End()
 
-	msgFields := strings.Fields(input)
+	msgFields := tokenizer.Split(input)
 	if len(msgFields) == 1 {
 		// equivalent to just `@evebot`
 		// botIDField := msgFields[0]
@@ -46,13 +77,64 @@ func (ebr *EvebotResolver) Resolve(input, channel, user string) commands.EvebotC
 	// make sure after you create a new command,
 	// you add the New func to the map so that it is picked up here
 	if fn := ebr.cmdFactory.Items()[cleanCmdFields[0]]; fn != nil {
+		if defaultedCmdFields := ebr.applyChannelContext(ctx, cleanCmdFields, channel); defaultedCmdFields != nil {
+			return commands.NewExpandedCommand(fn(defaultedCmdFields, channel, user), defaultedCmdFields)
+		}
 		return fn(cleanCmdFields, channel, user)
 	}
 
+	// user defined aliases never shadow the built-in commands (above)
+	expandedCmdFields, err := ebr.expandAlias(ctx, cleanCmdFields, channel, user)
+	if err != nil {
+		log.Logger.Info("invalid alias", zap.String("command", cleanCmdFields[0]), zap.Error(err))
+		return commands.NewAliasErrorCommand(cleanCmdFields, channel, user, err)
+	}
+	if expandedCmdFields != nil {
+		if fn := ebr.cmdFactory.Items()[expandedCmdFields[0]]; fn != nil {
+			if defaultedCmdFields := ebr.applyChannelContext(ctx, expandedCmdFields, channel); defaultedCmdFields != nil {
+				expandedCmdFields = defaultedCmdFields
+			}
+			return commands.NewExpandedCommand(fn(expandedCmdFields, channel, user), expandedCmdFields)
+		}
+	}
+
 	log.Logger.Info("invalid command", zap.String("command", cleanCmdFields[0]), zap.String("input", input))
+	if suggestion, ok := suggest.Closest(cleanCmdFields[0], ebr.commandNames()); ok {
+		return commands.NewUnknownCommand(cleanCmdFields, channel, user, suggestion)
+	}
 	return commands.NewInvalidCommand(cleanCmdFields, channel, user)
 }
 
+// commandNames are the built-in command names (the "did you mean" candidates)
+func (ebr *EvebotResolver) commandNames() []string {
+	var names []string
+	for name := range ebr.cmdFactory.Items() {
+		names = append(names, name)
+	}
+	return names
+}
+
+// applyChannelContext fills in the missing namespace/environment with the channel defaults
+// the channel context is only read when the command left its location out,
+// nil is returned when the channel doesn't have a context or the command fields didn't change
+func (ebr *EvebotResolver) applyChannelContext(ctx context.Context, cmdFields []string, channel string) []string {
+	if ebr.contexts == nil || !commands.NeedsChannelDefaults(cmdFields) {
+		return nil
+	}
+	cc, err := ebr.contexts.ReadChannelContext(ctx, channel)
+	if err != nil {
+		if !goerrors.Is(err, errors.ErrNotFound) {
+			log.Logger.Error("failed to read channel context", zap.String("channel", channel), zap.Error(err))
+		}
+		return nil
+	}
+	defaulted := commands.ApplyChannelDefaults(cmdFields, cc.Namespace, cc.Environment)
+	if len(defaulted) == len(cmdFields) {
+		return nil
+	}
+	return defaulted
+}
+
 func cleanCommandField(cmdFields []string) []string {
 	var cleanCmdFields []string
 	for _, i := range cmdFields {
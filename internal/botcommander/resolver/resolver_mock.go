@@ -5,6 +5,7 @@
 package resolver
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,15 +36,15 @@ func (m *MockResolver) EXPECT() *MockResolverMockRecorder {
 }
 
 // Resolve mocks base method
-func (m *MockResolver) Resolve(input, channel, user string) commands.EvebotCommand {
+func (m *MockResolver) Resolve(ctx context.Context, input, channel, user string) commands.EvebotCommand {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Resolve", input, channel, user)
+	ret := m.ctrl.Call(m, "Resolve", ctx, input, channel, user)
 	ret0, _ := ret[0].(commands.EvebotCommand)
 	return ret0
 }
 
 // Resolve indicates an expected call of Resolve
-func (mr *MockResolverMockRecorder) Resolve(input, channel, user interface{}) *gomock.Call {
+func (mr *MockResolverMockRecorder) Resolve(ctx, input, channel, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockResolver)(nil).Resolve), input, channel, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockResolver)(nil).Resolve), ctx, input, channel, user)
 }
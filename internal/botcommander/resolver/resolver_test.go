@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
@@ -33,6 +34,7 @@ func TestEvebotResolver_Resolve(t *testing.T) {
 	rootCmd := "@evebot"
 	setMetaDataCmdDbURL := "@evebot set metadata for auroraa in current una-int unanet_database_unatime.database.url=jdbc:postgresql://unanet-aurora-db.app-nonprod.unanet.io:5432/aurorab_int_current?escapeSyntaxCallMode=callIfNoReturn"
 	setMetaDataCmdEncoded := "@evebot set metadata for platform in current una-dev REPORTING_customer.url.regex=&lt;blah&gt;"
+	setMetaDataCmdQuoted := "@evebot set metadata for api in current una-int motd=“don’t panic” replicas:=3"
 
 	type args struct {
 		input, channel, user string
@@ -47,6 +49,11 @@ func TestEvebotResolver_Resolve(t *testing.T) {
 			args: args{input: setMetaDataCmdEncoded, channel: channel, user: user},
 			want: commands.NewSetCommand(strings.Fields("set metadata for platform in current una-dev REPORTING_customer.url.regex=<blah>"), channel, user),
 		},
+		{
+			name: "set metadata quoted",
+			args: args{input: setMetaDataCmdQuoted, channel: channel, user: user},
+			want: commands.NewSetCommand([]string{"set", "metadata", "for", "api", "in", "current", "una-int", "motd=don't panic", "replicas:=3"}, channel, user),
+		},
 		{
 			name: "set metadata db url",
 			args: args{input: setMetaDataCmdDbURL, channel: channel, user: user},
@@ -110,7 +117,7 @@ func TestEvebotResolver_Resolve(t *testing.T) {
 		{
 			name: "invalid deploy command",
 			args: args{input: invalidDeployCmd, channel: channel, user: user},
-			want: commands.NewInvalidCommand(strings.Fields(invalidDeployCmd)[1:], channel, user),
+			want: commands.NewUnknownCommand(strings.Fields(invalidDeployCmd)[1:], channel, user, commands.DeployCmdName),
 		},
 		{
 			name: "invalid deploy command length",
@@ -137,7 +144,7 @@ func TestEvebotResolver_Resolve(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			cmd := resolver.Resolve(tt.args.input, tt.args.channel, tt.args.user)
+			cmd := resolver.Resolve(context.Background(), tt.args.input, tt.args.channel, tt.args.user)
 
 			if !reflect.DeepEqual(cmd, tt.want) {
 				t.Errorf("\nA = %v\nB = %v", cmd, tt.want)
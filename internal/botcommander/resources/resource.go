@@ -31,13 +31,16 @@ type baseResource struct {
 // This map should never be written to, just read for Validation
 // TODO: Support the plural and singular form and then remove "jobs" entry
 var FullResourceMap = map[string]bool{
-	strings.ToLower(EnvironmentName): true,
-	strings.ToLower(NamespaceName):   true,
-	strings.ToLower(ServiceName):     true,
-	strings.ToLower(MetadataName):    true,
-	strings.ToLower(JobName):         true,
-	"jobs":                           true, // Job vs Jobs TODO: Clean this up
-	strings.ToLower(VersionName):     true,
+	strings.ToLower(EnvironmentName):  true,
+	strings.ToLower(NamespaceName):    true,
+	strings.ToLower(ServiceName):      true,
+	strings.ToLower(MetadataName):     true,
+	strings.ToLower(JobName):          true,
+	"jobs":                            true, // Job vs Jobs TODO: Clean this up
+	strings.ToLower(VersionName):      true,
+	strings.ToLower(DeploymentName):   true,
+	strings.ToLower(SubscriptionName): true,
+	strings.ToLower(PodName):          true,
 }
 
 // ValidResMutations are just a map of resources that can be mutated by the bot (user)
@@ -0,0 +1,26 @@
+package resources
+
+const (
+	// DeploymentName resource key/id
+	DeploymentName = "deployments"
+)
+
+// Deployment resource data structure
+type Deployment struct {
+	baseResource
+}
+
+// Name satisfies the resource interface and returns the Deployment Name
+func (e Deployment) Name() string {
+	return e.name
+}
+
+// Description satisfies the resource interface and returns the Deployment Description
+func (e Deployment) Description() string {
+	return e.description
+}
+
+// Value satisfies the resource interface and returns the Deployment Value
+func (e Deployment) Value() string {
+	return e.value
+}
@@ -0,0 +1,26 @@
+package resources
+
+const (
+	// PodName resource key/id
+	PodName = "pods"
+)
+
+// Pod resource data structure
+type Pod struct {
+	baseResource
+}
+
+// Name satisfies the resource interface and returns the Pod Name
+func (e Pod) Name() string {
+	return e.name
+}
+
+// Description satisfies the resource interface and returns the Pod Description
+func (e Pod) Description() string {
+	return e.description
+}
+
+// Value satisfies the resource interface and returns the Pod Value
+func (e Pod) Value() string {
+	return e.value
+}
@@ -0,0 +1,26 @@
+package resources
+
+const (
+	// SubscriptionName resource key/id
+	SubscriptionName = "subscriptions"
+)
+
+// Subscription resource data structure
+type Subscription struct {
+	baseResource
+}
+
+// Name satisfies the resource interface and returns the Subscription Name
+func (e Subscription) Name() string {
+	return e.name
+}
+
+// Description satisfies the resource interface and returns the Subscription Description
+func (e Subscription) Description() string {
+	return e.description
+}
+
+// Value satisfies the resource interface and returns the Subscription Value
+func (e Subscription) Value() string {
+	return e.value
+}
@@ -0,0 +1,97 @@
+// Package suggest provides the "did you mean" suggestions for mistyped commands and resources
+package suggest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Closest returns the candidate closest to the input (by edit distance)
+// false is returned when the input is an exact match or none of the candidates are close enough
+func Closest(input string, candidates []string) (string, bool) {
+	input = strings.ToLower(input)
+	if len(input) == 0 {
+		return "", false
+	}
+
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	var best string
+	bestDistance := maxDistance(input) + 1
+	for _, c := range sorted {
+		if len(c) == 0 {
+			continue
+		}
+		d := Distance(input, strings.ToLower(c))
+		if d == 0 {
+			return "", false
+		}
+		if d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best, len(best) > 0
+}
+
+// Unknown returns the "unknown {{ kind }}" message with the closest suggestion (when there is one)
+//
+//	unknown namespace `curent` — did you mean `current`?
+func Unknown(kind, input string, candidates []string) string {
+	msg := fmt.Sprintf("unknown %s `%s`", kind, input)
+	if s, ok := Closest(input, candidates); ok {
+		msg += fmt.Sprintf(" — did you mean `%s`?", s)
+	}
+	return msg
+}
+
+// Distance is the optimal string alignment distance between a and b
+// (Levenshtein distance where a transposition of two adjacent characters counts as a single edit)
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// maxDistance is how many edits a suggestion can be away from the input
+// short inputs only allow a single edit, otherwise everything is "close"
+func maxDistance(input string) int {
+	switch n := len([]rune(input)); {
+	case n <= 4:
+		return 1
+	case n <= 8:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func min(vals ...int) int {
+	result := vals[0]
+	for _, v := range vals[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
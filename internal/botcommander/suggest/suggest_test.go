@@ -0,0 +1,88 @@
+package suggest
+
+import (
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "deploy", b: "deploy", want: 0},
+		{a: "", b: "show", want: 4},
+		{a: "curent", b: "current", want: 1},
+		{a: "deplyo", b: "deploy", want: 1},
+		{a: "shwo", b: "show", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); got != tt.want {
+				t.Errorf("Distance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	commands := []string{"deploy", "show", "set", "delete", "release", "restart", "run", "help"}
+	tests := []struct {
+		name       string
+		kind       string
+		input      string
+		candidates []string
+		want       string
+	}{
+		{
+			name:       "namespace typo",
+			kind:       "namespace",
+			input:      "curent",
+			candidates: []string{"current", "latest", "patch"},
+			want:       "unknown namespace `curent` — did you mean `current`?",
+		},
+		{
+			name:       "command transposition",
+			kind:       "command",
+			input:      "deplyo",
+			candidates: commands,
+			want:       "unknown command `deplyo` — did you mean `deploy`?",
+		},
+		{
+			name:       "case insensitive",
+			kind:       "command",
+			input:      "SHWO",
+			candidates: commands,
+			want:       "unknown command `SHWO` — did you mean `show`?",
+		},
+		{
+			name:       "nothing close",
+			kind:       "command",
+			input:      "wtf",
+			candidates: commands,
+			want:       "unknown command `wtf`",
+		},
+		{
+			name:       "short inputs only allow a single edit",
+			kind:       "environment",
+			input:      "qe",
+			candidates: []string{"int", "qa", "prod"},
+			want:       "unknown environment `qe` — did you mean `qa`?",
+		},
+		{
+			name:       "no candidates",
+			kind:       "service",
+			input:      "api",
+			candidates: nil,
+			want:       "unknown service `api`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unknown(tt.kind, tt.input, tt.candidates); got != tt.want {
+				t.Errorf("Unknown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
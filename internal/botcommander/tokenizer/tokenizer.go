@@ -0,0 +1,145 @@
+// Package tokenizer splits the chat input into command fields
+//
+// The fields are split on whitespace, except for:
+//
+//	quoted values:     key="some value"  key='a=b c'  "two words"
+//	backslash escapes: key=some\ value  key="say \"hi\""
+//	json values:       key:=@json{"a": [1, 2]}
+//
+// Slack replaces the quotes with smart quotes (“ ” ‘ ’), which are treated as the regular quotes.
+// A quote only starts a quoted value at the start of a field or right after `=`,
+// so apostrophes (don't) are kept as is.
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// JSONPrefix marks the start of a (balanced) json value that is kept verbatim
+const JSONPrefix = "@json"
+
+var smartQuotes = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`,
+	"‘", "'", "’", "'", "‚", "'",
+)
+
+// Split splits the input into fields
+// an unterminated quote runs to the end of the input
+func Split(input string) []string {
+	runes := []rune(smartQuotes.Replace(input))
+
+	var fields []string
+	var field strings.Builder
+	inField := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		case r == '\\' && i+1 < len(runes):
+			i++
+			field.WriteRune(runes[i])
+			inField = true
+		case (r == '"' || r == '\'') && startsValue(field.String(), inField):
+			i = readQuoted(runes, i, &field)
+			inField = true
+		case (r == '{' || r == '[') && strings.HasSuffix(field.String(), JSONPrefix):
+			i = readJSON(runes, i, &field)
+			inField = true
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// Join joins the fields into an input that Split splits back into the same fields
+// the fields with whitespace, quotes or backslashes are quoted (only the value of a key=value field)
+func Join(fields []string) string {
+	quoted := make([]string, 0, len(fields))
+	for _, f := range fields {
+		quoted = append(quoted, quote(f))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quote(field string) string {
+	if len(field) > 0 && !needsQuote(field) {
+		return field
+	}
+	if i := strings.Index(field, "="); i > 0 && !needsQuote(field[:i]) {
+		return field[:i+1] + quoteValue(field[i+1:])
+	}
+	return quoteValue(field)
+}
+
+func needsQuote(value string) bool {
+	return strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '\\' || r == '\''
+	}) >= 0
+}
+
+func quoteValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// startsValue checks if a quote (at the current position) starts a quoted value
+func startsValue(field string, inField bool) bool {
+	return !inField || strings.HasSuffix(field, "=")
+}
+
+// readQuoted reads the quoted value starting at the quote (runes[start]) and returns the position of the closing quote
+func readQuoted(runes []rune, start int, field *strings.Builder) int {
+	quote := runes[start]
+	i := start + 1
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == quote:
+			return i
+		case r == '\\' && quote == '"' && i+1 < len(runes):
+			i++
+			field.WriteRune(runes[i])
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return i
+}
+
+// readJSON reads the balanced json object/array starting at runes[start] verbatim
+// and returns the position of the closing bracket
+func readJSON(runes []rune, start int, field *strings.Builder) int {
+	depth := 0
+	inString := false
+	i := start
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		field.WriteRune(r)
+		switch {
+		case inString && r == '\\' && i+1 < len(runes):
+			i++
+			field.WriteRune(runes[i])
+		case r == '"':
+			inString = !inString
+		case !inString && (r == '{' || r == '['):
+			depth++
+		case !inString && (r == '}' || r == ']'):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
@@ -0,0 +1,114 @@
+package tokenizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "whitespace",
+			input: "  set metadata\tfor api  ",
+			want:  []string{"set", "metadata", "for", "api"},
+		},
+		{
+			name:  "double quoted value",
+			input: `key="some value" other=1`,
+			want:  []string{"key=some value", "other=1"},
+		},
+		{
+			name:  "single quoted value keeps escapes and equals",
+			input: `key='a=b\n c'`,
+			want:  []string{`key=a=b\n c`},
+		},
+		{
+			name:  "quoted field",
+			input: `delete metadata "my key" other`,
+			want:  []string{"delete", "metadata", "my key", "other"},
+		},
+		{
+			name:  "escaped quote in double quotes",
+			input: `key="say \"hi\""`,
+			want:  []string{`key=say "hi"`},
+		},
+		{
+			name:  "escaped space",
+			input: `key=some\ value`,
+			want:  []string{"key=some value"},
+		},
+		{
+			name:  "slack smart quotes",
+			input: "key=“some value” other=‘a b’",
+			want:  []string{"key=some value", "other=a b"},
+		},
+		{
+			name:  "apostrophes are kept",
+			input: "msg=don’t stop",
+			want:  []string{"msg=don't", "stop"},
+		},
+		{
+			name:  "json value",
+			input: `key:=@json{"a": [1, 2], "b": "x } y"} next=1`,
+			want:  []string{`key:=@json{"a": [1, 2], "b": "x } y"}`, "next=1"},
+		},
+		{
+			name:  "connection string",
+			input: "url=jdbc:postgresql://db:5432/app?escapeSyntaxCallMode=callIfNoReturn",
+			want:  []string{"url=jdbc:postgresql://db:5432/app?escapeSyntaxCallMode=callIfNoReturn"},
+		},
+		{
+			name:  "unterminated quote runs to the end",
+			input: `key="some value`,
+			want:  []string{"key=some value"},
+		},
+		{
+			name:  "empty quotes",
+			input: `key=""`,
+			want:  []string{"key="},
+		},
+		{
+			name:  "empty",
+			input: "   ",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{name: "plain", fields: []string{"deploy", "current", "in", "$1"}, want: "deploy current in $1"},
+		{name: "value with spaces", fields: []string{"set", "key=some value"}, want: `set key="some value"`},
+		{name: "field with spaces", fields: []string{"delete", "my key"}, want: `delete "my key"`},
+		{name: "quotes and backslashes", fields: []string{`key=say "hi" \o/`}, want: `key="say \"hi\" \\o/"`},
+		{name: "apostrophe", fields: []string{"msg=don't"}, want: `msg="don't"`},
+		{name: "empty", fields: []string{"key=", ""}, want: `key= ""`},
+		{name: "json", fields: []string{`key:=@json{"a": [1, 2]}`}, want: `key:="@json{\"a\": [1, 2]}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Join(tt.fields)
+			if got != tt.want {
+				t.Errorf("Join() = %q, want %q", got, tt.want)
+			}
+			if split := Split(got); !reflect.DeepEqual(split, tt.fields) {
+				t.Errorf("Split(Join()) = %q, want %q", split, tt.fields)
+			}
+		})
+	}
+}
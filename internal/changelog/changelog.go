@@ -0,0 +1,140 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the git log changelog config (an empty mirror dir disables the release notes)
+// EVEBOT_CHANGELOG_GIT_MIRROR_DIR
+// EVEBOT_CHANGELOG_TAG_FORMAT
+// EVEBOT_CHANGELOG_TICKET_PATTERN
+// EVEBOT_CHANGELOG_MAX_CHANGES
+// EVEBOT_CHANGELOG_TIMEOUT
+type Config struct {
+	// ChangelogGitMirrorDir has a (bare) mirror of the repository of each artifact, named after the artifact (i.e. billing-api or billing-api.git)
+	ChangelogGitMirrorDir string `split_words:"true"`
+	// ChangelogTagFormat is the git tag of a version ({version} is replaced with the version)
+	ChangelogTagFormat string `split_words:"true" default:"v{version}"`
+	// ChangelogTicketPattern matches the ticket ids in the commit summaries
+	ChangelogTicketPattern string `split_words:"true" default:"[A-Z][A-Z0-9]+-[0-9]+"`
+	// ChangelogMaxChanges is the number of changes listed per service (the others are counted)
+	ChangelogMaxChanges int           `split_words:"true" default:"10"`
+	ChangelogTimeout    time.Duration `split_words:"true" default:"5s"`
+}
+
+// Change is a commit of an artifact
+type Change struct {
+	Commit  string
+	Summary string
+	Tickets []string
+}
+
+// Changes are the changes of an artifact between two versions (the most recent first)
+type Changes struct {
+	Changes []Change
+	// More is the number of changes that weren't listed
+	More int
+}
+
+// the artifact names and versions are passed to git (they can't be options or paths)
+var (
+	validArtifact = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	validVersion  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+)
+
+// GitLog lists the commits between the version tags of the local mirror of the artifact repository
+// the mirrors aren't fetched, they are kept up to date outside of eve-bot (i.e. git remote update)
+type GitLog struct {
+	cfg     Config
+	tickets *regexp.Regexp
+}
+
+// NewGitLog creates a GitLog
+func NewGitLog(cfg Config) (*GitLog, error) {
+	tickets, err := regexp.Compile(cfg.ChangelogTicketPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid changelog ticket pattern: %w", err)
+	}
+	return &GitLog{cfg: cfg, tickets: tickets}, nil
+}
+
+// Changelog returns the changes of the artifact from (excluded) to (included) the version
+func (g *GitLog) Changelog(ctx context.Context, artifact, from, to string) (*Changes, error) {
+	if !validArtifact.MatchString(artifact) || strings.Contains(artifact, "..") {
+		return nil, fmt.Errorf("invalid artifact name: %s", artifact)
+	}
+	for _, v := range []string{from, to} {
+		if !validVersion.MatchString(v) {
+			return nil, fmt.Errorf("invalid version: %s", v)
+		}
+	}
+	dir, err := g.mirror(artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.ChangelogTimeout)
+	defer cancel()
+	revisions := g.tag(from) + ".." + g.tag(to)
+	count, err := g.git(ctx, dir, "rev-list", "--no-merges", "--count", revisions, "--")
+	if err != nil {
+		return nil, err
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(string(count)))
+	if err != nil {
+		return nil, err
+	}
+	out, err := g.git(ctx, dir, "log", "--no-merges", "--format=%h%x1f%s", "-n", strconv.Itoa(g.cfg.ChangelogMaxChanges), revisions, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\x1f", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		changes.Changes = append(changes.Changes, Change{Commit: fields[0], Summary: fields[1], Tickets: g.tickets.FindAllString(fields[1], -1)})
+	}
+	changes.More = total - len(changes.Changes)
+	return changes, scanner.Err()
+}
+
+// mirror is the mirror dir of the artifact (with or without the .git suffix)
+func (g *GitLog) mirror(artifact string) (string, error) {
+	for _, name := range []string{artifact + ".git", artifact} {
+		dir := filepath.Join(g.cfg.ChangelogGitMirrorDir, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no git mirror for artifact: %s", artifact)
+}
+
+func (g *GitLog) tag(version string) string {
+	return "refs/tags/" + strings.ReplaceAll(g.cfg.ChangelogTagFormat, "{version}", version)
+}
+
+func (g *GitLog) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	// the mirrors are mounted read-only and owned by the user that fetches them (safe.directory)
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "safe.directory=*", "-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
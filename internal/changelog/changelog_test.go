@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mirror creates a git repository with a commit and a version tag per summary (1.0, 1.1...)
+func mirror(t *testing.T, dir string, summaries ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=eve", "GIT_AUTHOR_EMAIL=eve@example.com", "GIT_COMMITTER_NAME=eve", "GIT_COMMITTER_EMAIL=eve@example.com", "HOME="+dir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	for i, s := range summaries {
+		run("commit", "-q", "--allow-empty", "-m", s)
+		run("tag", fmt.Sprintf("v1.%d", i))
+	}
+}
+
+func TestGitLog_Changelog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir := t.TempDir()
+	mirror(t, filepath.Join(dir, "billing-api"), "initial", "BILL-1 add the invoices", "fix the totals (BILL-2, OPS-3)", "bump the deps")
+
+	g, err := NewGitLog(Config{ChangelogGitMirrorDir: dir, ChangelogTagFormat: "v{version}", ChangelogTicketPattern: "[A-Z][A-Z0-9]+-[0-9]+", ChangelogMaxChanges: 2, ChangelogTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := g.Changelog(context.Background(), "billing-api", "1.0", "1.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Changes) != 2 || changes.More != 1 {
+		t.Fatalf("Changelog() = %+v, want 2 changes and 1 more", changes)
+	}
+	if changes.Changes[0].Summary != "bump the deps" || !reflect.DeepEqual(changes.Changes[1].Tickets, []string{"BILL-2", "OPS-3"}) {
+		t.Errorf("Changelog() = %+v, want the most recent changes with their tickets", changes.Changes)
+	}
+
+	if _, err := g.Changelog(context.Background(), "billing-api", "1.0", "2.0"); err == nil {
+		t.Error("expected an unknown tag error")
+	}
+	if _, err := g.Changelog(context.Background(), "api", "1.0", "1.1"); err == nil {
+		t.Error("expected a missing mirror error")
+	}
+	for _, invalid := range [][]string{{"../billing-api", "1.0", "1.1"}, {"billing-api", "--output=x", "1.1"}} {
+		if _, err := g.Changelog(context.Background(), invalid[0], invalid[1], invalid[2]); err == nil {
+			t.Errorf("Changelog(%v) expected an invalid input error", invalid)
+		}
+	}
+}
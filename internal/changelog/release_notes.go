@@ -0,0 +1,88 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Provider summarizes the changes of an artifact between two versions (i.e. a GitLog)
+type Provider interface {
+	Changelog(ctx context.Context, artifact, from, to string) (*Changes, error)
+}
+
+// ReleaseNotes are the changes of an upgraded service (from the previously deployed version)
+type ReleaseNotes struct {
+	Service  string   `json:"service"`
+	Artifact string   `json:"artifact"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Changes  *Changes `json:"changes"`
+}
+
+// Collect returns the changes of the services upgraded by the namespace deployment plan (the provider is optional)
+// the services without a changelog (i.e. no mirror of the artifact) are skipped
+func Collect(ctx context.Context, provider Provider, plan eve.NSDeploymentPlan) []ReleaseNotes {
+	if provider == nil || (plan.Status != eve.DeploymentPlanStatusComplete && plan.Status != eve.DeploymentPlanStatusErrors) {
+		return nil
+	}
+	var notes []ReleaseNotes
+	changes := make(map[string]*Changes)
+	for _, svc := range plan.Services {
+		if svc == nil || svc.DeployArtifact == nil || svc.Result != eve.DeployArtifactResultSuccess ||
+			len(svc.DeployedVersion) == 0 || svc.DeployedVersion == svc.AvailableVersion {
+			continue
+		}
+		// the services of an artifact have the same changes
+		key := svc.ArtifactName + ":" + svc.DeployedVersion + ":" + svc.AvailableVersion
+		c, ok := changes[key]
+		if !ok {
+			var err error
+			if c, err = provider.Changelog(ctx, svc.ArtifactName, svc.DeployedVersion, svc.AvailableVersion); err != nil {
+				log.Logger.Warn("failed to get the changelog", zap.Error(err), zap.String("artifact", svc.ArtifactName))
+			}
+			changes[key] = c
+		}
+		if c == nil || len(c.Changes) == 0 {
+			continue
+		}
+		notes = append(notes, ReleaseNotes{
+			Service:  svc.ServiceName,
+			Artifact: svc.ArtifactName,
+			From:     svc.DeployedVersion,
+			To:       svc.AvailableVersion,
+			Changes:  c,
+		})
+	}
+	return notes
+}
+
+// Message formats the changes of each upgraded service as a quote (slack collapses the long messages)
+func Message(notes []ReleaseNotes) string {
+	var msg string
+	for _, n := range notes {
+		msg += fmt.Sprintf("\n>*%s* %s → %s", n.Service, n.From, n.To)
+		var tickets []string
+		seen := make(map[string]bool)
+		for _, c := range n.Changes.Changes {
+			msg += fmt.Sprintf("\n>`%s` %s", c.Commit, c.Summary)
+			for _, t := range c.Tickets {
+				if !seen[t] {
+					seen[t] = true
+					tickets = append(tickets, t)
+				}
+			}
+		}
+		if n.Changes.More > 0 {
+			msg += fmt.Sprintf("\n>_and %d more changes_", n.Changes.More)
+		}
+		if len(tickets) > 0 {
+			msg += fmt.Sprintf("\n>Tickets: %s", strings.Join(tickets, ", "))
+		}
+	}
+	return msg
+}
@@ -0,0 +1,68 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// fakeChangelog serves the changes by artifact (and counts the calls)
+type fakeChangelog struct {
+	changes map[string]*Changes
+	calls   int
+}
+
+func (f *fakeChangelog) Changelog(ctx context.Context, artifact, from, to string) (*Changes, error) {
+	f.calls++
+	if c, ok := f.changes[artifact+":"+from+":"+to]; ok {
+		return c, nil
+	}
+	return nil, errors.New("no git mirror")
+}
+
+func TestCollect(t *testing.T) {
+	svc := func(name, artifact, deployed, available string, result eve.DeployArtifactResult) *eve.DeployService {
+		return &eve.DeployService{
+			DeployArtifact: &eve.DeployArtifact{ArtifactName: artifact, DeployedVersion: deployed, AvailableVersion: available, Result: result},
+			ServiceName:    name,
+		}
+	}
+	provider := &fakeChangelog{changes: map[string]*Changes{
+		"billing:1.1:1.2": {Changes: []Change{
+			{Commit: "abc1234", Summary: "fix the totals (BILL-2)", Tickets: []string{"BILL-2"}},
+			{Commit: "def5678", Summary: "BILL-2 round the taxes", Tickets: []string{"BILL-2"}},
+		}, More: 3},
+	}}
+	plan := eve.NSDeploymentPlan{
+		Namespace:       &eve.NamespaceRequest{Alias: "current"},
+		EnvironmentName: "una-int",
+		Status:          eve.DeploymentPlanStatusComplete,
+		Type:            eve.DeploymentPlanTypeApplication,
+		Services: eve.DeployServices{
+			svc("billing", "billing", "1.1", "1.2", eve.DeployArtifactResultSuccess),
+			svc("billing-worker", "billing", "1.1", "1.2", eve.DeployArtifactResultSuccess),
+			svc("api", "api", "2.0", "2.1", eve.DeployArtifactResultSuccess),
+			svc("web", "web", "3.0", "3.1", eve.DeployArtifactResultFailed),
+			svc("docs", "docs", "", "1.0", eve.DeployArtifactResultSuccess),
+		},
+	}
+
+	notes := Collect(context.Background(), provider, plan)
+	if provider.calls != 2 || len(notes) != 2 {
+		t.Fatalf("calls = %d, notes = %+v, want the billing notes for both services", provider.calls, notes)
+	}
+	msg := Message(notes)
+	for _, want := range []string{">*billing* 1.1 → 1.2\n>`abc1234` fix the totals (BILL-2)", ">*billing-worker* 1.1 → 1.2", "_and 3 more changes_", ">Tickets: BILL-2\n"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Message() = %q, want %q", msg, want)
+		}
+	}
+
+	plan.Status = eve.DeploymentPlanStatusPending
+	if notes := append(Collect(context.Background(), provider, plan), Collect(context.Background(), nil, plan)...); len(notes) != 0 {
+		t.Errorf("Collect() = %+v, want none for a pending plan", notes)
+	}
+}
@@ -18,3 +18,22 @@ type Channel struct {
 	ID   string
 	Name string
 }
+
+// File data structure (a file shared in a channel)
+// Channel/Timestamp/ThreadTimestamp and Comment are the message the file was shared with
+type File struct {
+	ID, Name, Filetype, User string
+	Size                     int
+	DownloadURL              string
+	Channel                  string
+	Timestamp                string
+	ThreadTimestamp          string
+	Comment                  string
+}
+
+const (
+	// ConfirmActionID is the action id of the confirm button (the value is the confirmation id)
+	ConfirmActionID = "evebot-confirm"
+	// CancelActionID is the action id of the cancel button (the value is the confirmation id)
+	CancelActionID = "evebot-cancel"
+)
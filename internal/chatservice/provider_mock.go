@@ -188,3 +188,57 @@ func (mr *MockProviderMockRecorder) ReleaseResultsMessageThread(ctx, msg, user,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseResultsMessageThread", reflect.TypeOf((*MockProvider)(nil).ReleaseResultsMessageThread), ctx, msg, user, channel, ts)
 }
+
+// ConfirmationMessageThread mocks base method
+func (m *MockProvider) ConfirmationMessageThread(ctx context.Context, msg, user, channel, ts, confirmationID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConfirmationMessageThread", ctx, msg, user, channel, ts, confirmationID)
+}
+
+// ConfirmationMessageThread indicates an expected call of ConfirmationMessageThread
+func (mr *MockProviderMockRecorder) ConfirmationMessageThread(ctx, msg, user, channel, ts, confirmationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmationMessageThread", reflect.TypeOf((*MockProvider)(nil).ConfirmationMessageThread), ctx, msg, user, channel, ts, confirmationID)
+}
+
+// GetFile mocks base method
+func (m *MockProvider) GetFile(ctx context.Context, fileID string) (*chatmodels.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFile", ctx, fileID)
+	ret0, _ := ret[0].(*chatmodels.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFile indicates an expected call of GetFile
+func (mr *MockProviderMockRecorder) GetFile(ctx, fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockProvider)(nil).GetFile), ctx, fileID)
+}
+
+// DownloadFile mocks base method
+func (m *MockProvider) DownloadFile(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadFile", ctx, url, maxBytes)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadFile indicates an expected call of DownloadFile
+func (mr *MockProviderMockRecorder) DownloadFile(ctx, url, maxBytes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockProvider)(nil).DownloadFile), ctx, url, maxBytes)
+}
+
+// UploadFileThread mocks base method
+func (m *MockProvider) UploadFileThread(ctx context.Context, filename string, content []byte, msg, user, channel, ts string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UploadFileThread", ctx, filename, content, msg, user, channel, ts)
+}
+
+// UploadFileThread indicates an expected call of UploadFileThread
+func (mr *MockProviderMockRecorder) UploadFileThread(ctx, filename, content, msg, user, channel, ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFileThread", reflect.TypeOf((*MockProvider)(nil).UploadFileThread), ctx, filename, content, msg, user, channel, ts)
+}
@@ -0,0 +1,141 @@
+package slackservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// GetFile returns the shared file info with the message (comment) the file was shared with
+func (sp Provider) GetFile(ctx context.Context, fileID string) (*chatmodels.File, error) {
+	slackFile, _, _, err := sp.client.GetFileInfoContext(ctx, fileID, 0, 0)
+	if err != nil {
+		log.Logger.Error("failed to get file info from provider", zap.String("file", fileID), zap.Error(err))
+		return nil, err
+	}
+
+	file := &chatmodels.File{
+		ID:          slackFile.ID,
+		Name:        slackFile.Name,
+		Filetype:    slackFile.Filetype,
+		User:        slackFile.User,
+		Size:        slackFile.Size,
+		DownloadURL: slackFile.URLPrivateDownload,
+		Comment:     slackFile.InitialComment.Comment,
+	}
+	file.Channel, file.Timestamp, file.ThreadTimestamp = firstShare(slackFile.Shares)
+
+	// the initial comment is only set for the legacy uploads, otherwise the comment is the text of the message
+	if len(file.Comment) == 0 && len(file.Channel) > 0 {
+		file.Comment, err = sp.messageText(ctx, file.Channel, file.Timestamp, file.ThreadTimestamp)
+		if err != nil {
+			log.Logger.Error("failed to get the file message from provider", zap.String("file", fileID), zap.Error(err))
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+func firstShare(shares slack.Share) (channel, ts, threadTS string) {
+	for _, s := range []map[string][]slack.ShareFileInfo{shares.Public, shares.Private} {
+		for ch, infos := range s {
+			if len(infos) > 0 {
+				return ch, infos[0].Ts, infos[0].ThreadTs
+			}
+		}
+	}
+	return "", "", ""
+}
+
+// messageText returns the text of the message (threadTS is set when the message is a thread reply)
+func (sp Provider) messageText(ctx context.Context, channel, ts, threadTS string) (string, error) {
+	var msgs []slack.Message
+	if len(threadTS) > 0 {
+		replies, _, _, err := sp.client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+			ChannelID: channel,
+			Timestamp: threadTS,
+			Oldest:    ts,
+			Latest:    ts,
+			Inclusive: true,
+		})
+		if err != nil {
+			return "", err
+		}
+		msgs = replies
+	} else {
+		history, err := sp.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channel,
+			Oldest:    ts,
+			Latest:    ts,
+			Inclusive: true,
+			Limit:     1,
+		})
+		if err != nil {
+			return "", err
+		}
+		msgs = history.Messages
+	}
+	for _, m := range msgs {
+		if m.Timestamp == ts {
+			return m.Text, nil
+		}
+	}
+	return "", nil
+}
+
+// DownloadFile downloads the (private) file, the download fails when the file is larger than the maxBytes
+func (sp Provider) DownloadFile(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	w := &limitedWriter{max: maxBytes}
+	if err := sp.client.GetFile(url, w); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+type limitedWriter struct {
+	buf bytes.Buffer
+	max int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.max > 0 && int64(w.buf.Len()+len(p)) > w.max {
+		return 0, fmt.Errorf("file is larger than %d bytes", w.max)
+	}
+	return w.buf.Write(p)
+}
+
+// ConfirmationMessageThread sends a threaded message with the Confirm/Cancel buttons
+// the buttons values are the confirmation id
+func (sp Provider) ConfirmationMessageThread(ctx context.Context, msg, user, channel, ts, confirmationID string) {
+	confirmBtn := slack.NewButtonBlockElement(chatmodels.ConfirmActionID, confirmationID, slack.NewTextBlockObject(slack.PlainTextType, "Confirm", false, false))
+	confirmBtn.WithStyle(slack.StylePrimary)
+	cancelBtn := slack.NewButtonBlockElement(chatmodels.CancelActionID, confirmationID, slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))
+
+	msgOptionBlocks := slack.MsgOptionBlocks(
+		sectionBlockOpt(fmt.Sprintf("<@%s>! %s", user, msgConfirmation)),
+		slack.NewDividerBlock(),
+		sectionBlockOpt(msg),
+		slack.NewActionBlock(confirmationID, confirmBtn, cancelBtn),
+	)
+	threadOpt := slack.MsgOptionTS(ts)
+	_, _, err := sp.client.PostMessageContext(ctx, channel, msgOptionBlocks, threadOpt)
+	sp.handleDevOpsErrorNotification(ctx, err)
+}
+
+// UploadFileThread uploads the file content to the thread
+func (sp Provider) UploadFileThread(ctx context.Context, filename string, content []byte, msg, user, channel, ts string) {
+	_, err := sp.client.UploadFileContext(ctx, slack.FileUploadParameters{
+		Filename:        filename,
+		Title:           filename,
+		Content:         string(content),
+		InitialComment:  fmt.Sprintf("<@%s>! %s", user, msg),
+		Channels:        []string{channel},
+		ThreadTimestamp: ts,
+	})
+	sp.handleDevOpsErrorNotification(ctx, err)
+}
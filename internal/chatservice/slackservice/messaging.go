@@ -11,6 +11,7 @@ const (
 	msgResultsNotification       = "Here are your results..."
 	msgReleaseNotification       = "Successfully released...."
 	msgAuthLink                  = "Here is your account auth link:"
+	msgConfirmation              = "Please confirm the changes..."
 )
 
 func userErrMessage(user string, err error) string {
@@ -2,14 +2,39 @@ package slackservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/slack-go/slack"
 	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
 )
 
+var statPostFailures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "slack_post_failures_total",
+		Help: "The total number of failed Slack calls by reason (rate_limited, the Slack error code or other)",
+	}, []string{"reason"})
+
+// slackErrorCode matches the Slack api error codes (i.e. channel_not_found)
+var slackErrorCode = regexp.MustCompile(`^[a-z_]{1,64}$`)
+
+// failureReason is the bounded reason of a Slack failure (the network errors are "other")
+func failureReason(err error) string {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return "rate_limited"
+	}
+	if slackErrorCode.MatchString(err.Error()) {
+		return err.Error()
+	}
+	return "other"
+}
+
 // Provider is the Slack provider which wraps the slack the client
 type Provider struct {
 	client            *slack.Client
@@ -47,6 +72,7 @@ func New(c *slack.Client, monitoringChannel string) Provider {
 func (sp Provider) handleDevOpsErrorNotification(ctx context.Context, err error) {
 	if err != nil {
 		log.Logger.Error("critical devops error", zap.Error(err))
+		statPostFailures.WithLabelValues(failureReason(err)).Inc()
 		_, _, _ = sp.client.PostMessageContext(ctx, sp.monitoringChannel, slack.MsgOptionText(errMessage(err), false))
 	}
 }
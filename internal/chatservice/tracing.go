@@ -0,0 +1,123 @@
+package chatservice
+
+import (
+	"context"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/chatservice/chatmodels"
+	"github.com/unanet/eve-bot/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracedProvider decorates a ChatProvider with a span per call (the last span of the command trace)
+type tracedProvider struct {
+	interfaces.ChatProvider
+}
+
+// Traced wraps the ChatProvider calls in spans
+func Traced(p interfaces.ChatProvider) interfaces.ChatProvider {
+	if p == nil {
+		return nil
+	}
+	return tracedProvider{ChatProvider: p}
+}
+
+func (t tracedProvider) GetChannelInfo(ctx context.Context, channelID string) (chatmodels.Channel, error) {
+	ctx, span := tracing.Start(ctx, "chat.GetChannelInfo", attribute.String("chat.channel", channelID))
+	c, err := t.ChatProvider.GetChannelInfo(ctx, channelID)
+	tracing.End(span, err)
+	return c, err
+}
+
+func (t tracedProvider) PostMessage(ctx context.Context, msg, channel string) string {
+	ctx, span := tracing.Start(ctx, "chat.PostMessage", attribute.String("chat.channel", channel))
+	defer span.End()
+	return t.ChatProvider.PostMessage(ctx, msg, channel)
+}
+
+func (t tracedProvider) PostMessageThread(ctx context.Context, msg, channel, ts string) string {
+	ctx, span := tracing.Start(ctx, "chat.PostMessageThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	return t.ChatProvider.PostMessageThread(ctx, msg, channel, ts)
+}
+
+func (t tracedProvider) ErrorNotification(ctx context.Context, user, channel string, err error) {
+	ctx, span := tracing.Start(ctx, "chat.ErrorNotification", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.ErrorNotification(ctx, user, channel, err)
+}
+
+func (t tracedProvider) ErrorNotificationThread(ctx context.Context, user, channel, ts string, err error) {
+	ctx, span := tracing.Start(ctx, "chat.ErrorNotificationThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.ErrorNotificationThread(ctx, user, channel, ts, err)
+}
+
+func (t tracedProvider) UserNotificationThread(ctx context.Context, msg, user, channel, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.UserNotificationThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.UserNotificationThread(ctx, msg, user, channel, ts)
+}
+
+func (t tracedProvider) DeploymentNotificationThread(ctx context.Context, msg, user, channel, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.DeploymentNotificationThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.DeploymentNotificationThread(ctx, msg, user, channel, ts)
+}
+
+func (t tracedProvider) GetUser(ctx context.Context, user string) (*chatmodels.ChatUser, error) {
+	ctx, span := tracing.Start(ctx, "chat.GetUser")
+	u, err := t.ChatProvider.GetUser(ctx, user)
+	tracing.End(span, err)
+	return u, err
+}
+
+func (t tracedProvider) PostLinkMessageThread(ctx context.Context, msg string, user string, channel string, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.PostLinkMessageThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.PostLinkMessageThread(ctx, msg, user, channel, ts)
+}
+
+func (t tracedProvider) ShowResultsMessageThread(ctx context.Context, msg, user, channel, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.ShowResultsMessageThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.ShowResultsMessageThread(ctx, msg, user, channel, ts)
+}
+
+func (t tracedProvider) ReleaseResultsMessageThread(ctx context.Context, msg, user, channel, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.ReleaseResultsMessageThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.ReleaseResultsMessageThread(ctx, msg, user, channel, ts)
+}
+
+func (t tracedProvider) PostPrivateMessage(ctx context.Context, msg string, user string) {
+	ctx, span := tracing.Start(ctx, "chat.PostPrivateMessage")
+	defer span.End()
+	t.ChatProvider.PostPrivateMessage(ctx, msg, user)
+}
+
+func (t tracedProvider) ConfirmationMessageThread(ctx context.Context, msg, user, channel, ts, confirmationID string) {
+	ctx, span := tracing.Start(ctx, "chat.ConfirmationMessageThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.ConfirmationMessageThread(ctx, msg, user, channel, ts, confirmationID)
+}
+
+func (t tracedProvider) GetFile(ctx context.Context, fileID string) (*chatmodels.File, error) {
+	ctx, span := tracing.Start(ctx, "chat.GetFile")
+	f, err := t.ChatProvider.GetFile(ctx, fileID)
+	tracing.End(span, err)
+	return f, err
+}
+
+func (t tracedProvider) DownloadFile(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "chat.DownloadFile")
+	b, err := t.ChatProvider.DownloadFile(ctx, url, maxBytes)
+	tracing.End(span, err)
+	return b, err
+}
+
+func (t tracedProvider) UploadFileThread(ctx context.Context, filename string, content []byte, msg, user, channel, ts string) {
+	ctx, span := tracing.Start(ctx, "chat.UploadFileThread", attribute.String("chat.channel", channel))
+	defer span.End()
+	t.ChatProvider.UploadFileThread(ctx, filename, content, msg, user, channel, ts)
+}
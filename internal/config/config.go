@@ -4,8 +4,15 @@ import (
 	"sync"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/unanet/eve-bot/internal/changelog"
 	"github.com/unanet/eve-bot/internal/chatservice/slackservice"
+	"github.com/unanet/eve-bot/internal/cronrouter"
+	"github.com/unanet/eve-bot/internal/datastore"
 	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/kube"
+	"github.com/unanet/eve-bot/internal/sweeper"
+	"github.com/unanet/eve-bot/internal/tracing"
+	"github.com/unanet/eve-bot/internal/webhooks"
 	"github.com/unanet/go/pkg/identity"
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
@@ -25,6 +32,20 @@ type (
 	EveAPIConfig = eveapi.Config
 	// IdentityConfig is the OIDC (KeyCloak) Config data
 	IdentityConfig = identity.ValidatorConfig
+	// DatastoreConfig is the config for the eve-bot data store (DynamoDB table names)
+	DatastoreConfig = datastore.Config
+	// TracingConfig is the OpenTelemetry tracing config (exporter...)
+	TracingConfig = tracing.Config
+	// SweeperConfig is the overdue deployment sweeper config (interval, deadlines...)
+	SweeperConfig = sweeper.Config
+	// CronRouterConfig is the cron callback routing config (routes, error suppression, digests...)
+	CronRouterConfig = cronrouter.Config
+	// ChangelogConfig is the release notes config (git mirrors, tag format...)
+	ChangelogConfig = changelog.Config
+	// WebhooksConfig is the outbound webhooks config (subscribers, retries...)
+	WebhooksConfig = webhooks.Config
+	// KubernetesConfig is the kubernetes pods and logs config (clusters, limits...)
+	KubernetesConfig = kube.Config
 )
 
 type OIDCConfig struct {
@@ -37,6 +58,13 @@ type Config struct {
 	LogConfig
 	SlackConfig
 	EveAPIConfig
+	DatastoreConfig
+	TracingConfig
+	SweeperConfig
+	CronRouterConfig
+	ChangelogConfig
+	WebhooksConfig
+	KubernetesConfig
 	Identity                IdentityConfig
 	Oidc					OIDCConfig
 	Port                    int    `split_words:"true" default:"8080"`
@@ -46,6 +74,8 @@ type Config struct {
 	LoggingDashboardBaseURL string `split_words:"true" required:"true"`
 	UserTableName           string `split_words:"true" required:"true"`
 	DevopsMonitoringChannel string `split_words:"true" required:"true"`
+	MetadataFileMaxBytes    int64  `split_words:"true" default:"65536"`
+	MetadataFileMaxKeys     int    `split_words:"true" default:"200"`
 }
 
 // Load loads the config reading it from the environment
@@ -0,0 +1,311 @@
+package cronrouter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// flushTimeout bounds the digests flush on shutdown
+const flushTimeout = 10 * time.Second
+
+// mentionID is a user group id (S...) or a user id (U... or W...)
+var mentionID = regexp.MustCompile(`^[SUW][A-Z0-9]+$`)
+
+// Config is the cron callback routing config
+// EVEBOT_CRON_ROUTES
+// EVEBOT_CRON_ERROR_SUPPRESSION_WINDOW
+// EVEBOT_CRON_DIGEST_INTERVAL
+type Config struct {
+	// CronRoutes are the routing rules of the cron results (a json array, the first matching route wins)
+	CronRoutes Routes `split_words:"true"`
+	// CronErrorSuppressionWindow is the window of the repeated identical errors of a channel (0 posts every error)
+	CronErrorSuppressionWindow time.Duration `split_words:"true" default:"1h"`
+	// CronDigestInterval is the interval of the summaries of the digest routes (0 disables the digests)
+	CronDigestInterval time.Duration `split_words:"true" default:"1h"`
+}
+
+// Route is a routing rule of the cron results
+// the environment, namespace and service are (case insensitive) glob patterns, empty matches everything
+type Route struct {
+	Environment string   `json:"environment"`
+	Namespace   string   `json:"namespace"`
+	Service     string   `json:"service"`
+	Channels    []string `json:"channels"`
+	// Mention is only mentioned on errors: a user group id (S...), a user id, "here", "channel"
+	// or a slack mention (i.e. <!subteam^S0123|@team-billing>), a plain @handle isn't a slack mention and is rejected
+	Mention string `json:"mention"`
+	// Digest batches the results (except the errors) in a summary every CronDigestInterval
+	Digest bool `json:"digest"`
+}
+
+// Routes are the routing rules of the cron results
+type Routes []Route
+
+// Decode satisfies the envconfig.Decoder interface (the routes are a json array)
+func (r *Routes) Decode(value string) error {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil
+	}
+	var routes Routes
+	if err := json.Unmarshal([]byte(value), &routes); err != nil {
+		return fmt.Errorf("invalid cron routes: %w", err)
+	}
+	for i, route := range routes {
+		if len(route.Channels) == 0 {
+			return fmt.Errorf("invalid cron route %d: no channels", i)
+		}
+		for _, pattern := range []string{route.Environment, route.Namespace, route.Service} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid cron route %d: %w", i, err)
+			}
+		}
+		if !route.validMention() {
+			return fmt.Errorf("invalid cron route %d: the mention %q isn't a user group id (S...), a user id, here, channel or a slack mention (<!subteam^S...|@handle>)", i, route.Mention)
+		}
+	}
+	*r = routes
+	return nil
+}
+
+// Matches checks if the route applies to the cron result (any service or job of the plan matches the service)
+func (r Route) Matches(plan eve.NSDeploymentPlan) bool {
+	var namespaces, services []string
+	if plan.Namespace != nil {
+		namespaces = append(namespaces, plan.Namespace.Alias, plan.Namespace.Name)
+	}
+	for _, svc := range plan.Services {
+		if svc != nil && svc.DeployArtifact != nil {
+			services = append(services, svc.ServiceName, svc.ArtifactName)
+		}
+	}
+	for _, job := range plan.Jobs {
+		if job != nil && job.DeployArtifact != nil {
+			services = append(services, job.JobName, job.ArtifactName)
+		}
+	}
+	return matchAny(r.Environment, plan.EnvironmentName, plan.EnvironmentAlias) &&
+		matchAny(r.Namespace, namespaces...) &&
+		matchAny(r.Service, services...)
+}
+
+func matchAny(pattern string, values ...string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(v)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validMention checks that the mention renders as a slack mention
+func (r Route) validMention() bool {
+	switch m := strings.TrimSpace(r.Mention); {
+	case len(m) == 0 || m == "channel" || m == "here":
+		return true
+	case strings.HasPrefix(m, "<") && strings.HasSuffix(m, ">"):
+		return len(m) > 2
+	default:
+		return mentionID.MatchString(m)
+	}
+}
+
+// mention is the CallbackState user of the route mention
+func (r Route) mention() string {
+	switch m := strings.TrimSpace(r.Mention); {
+	case len(m) == 0 || m == "channel":
+		return m
+	case m == "here":
+		return "!here"
+	case strings.HasPrefix(m, "<") && strings.HasSuffix(m, ">"):
+		return strings.TrimSuffix(strings.TrimPrefix(m, "<"), ">")
+	case strings.HasPrefix(m, "S"):
+		return "!subteam^" + m
+	default:
+		return m
+	}
+}
+
+// Router posts the cron results to the channels of their route,
+// suppresses the repeated identical errors and batches the results of the digest routes
+// the suppressions and the digests are shared by the eve-bot instances (DynamoDB)
+type Router struct {
+	cfg          Config
+	store        interfaces.CronStateStore
+	chat         interfaces.ChatProvider
+	dashboardURL string
+	now          func() time.Time
+}
+
+// New creates a Router
+func New(cfg Config, store interfaces.CronStateStore, chat interfaces.ChatProvider, dashboardURL string) *Router {
+	return &Router{
+		cfg:          cfg,
+		store:        store,
+		chat:         chat,
+		dashboardURL: dashboardURL,
+		now:          time.Now,
+	}
+}
+
+// route is the first matching route, the channel of the callback (with @channel on errors) when no route matches
+func (r *Router) route(plan eve.NSDeploymentPlan, channel string) Route {
+	for _, route := range r.cfg.CronRoutes {
+		if route.Matches(plan) {
+			return route
+		}
+	}
+	return Route{Channels: []string{channel}, Mention: "channel"}
+}
+
+// Post routes the (non pending) cron result
+func (r *Router) Post(ctx context.Context, plan eve.NSDeploymentPlan, channel string) {
+	route := r.route(plan, channel)
+	failed := plan.Status == eve.DeploymentPlanStatusErrors
+	for _, ch := range route.Channels {
+		if !failed && route.Digest && r.cfg.CronDigestInterval > 0 && r.addDigest(ctx, ch, plan) {
+			continue
+		}
+
+		var suppressed *datastore.CronSuppression
+		if failed {
+			var post bool
+			if suppressed, post = r.suppress(ctx, ch, plan); !post {
+				continue
+			}
+		}
+
+		user := ""
+		if failed {
+			user = route.mention()
+		}
+		cbState := eveapi.CallbackState{User: user, Channel: ch, Payload: plan}
+		msg := cbState.ToChatMsg()
+		if suppressed != nil && suppressed.Count > 0 {
+			msg += fmt.Sprintf("\n_%d identical errors were suppressed since %s_", suppressed.Count, suppressed.Posted.Format("15:04"))
+		}
+		ts := r.chat.PostMessage(ctx, msg, ch)
+		if failed && len(r.dashboardURL) > 0 {
+			r.chat.PostMessageThread(ctx, fmt.Sprintf("<%s|Grafana Logs>", r.dashboardURL), ch, ts)
+		}
+	}
+}
+
+// suppress checks if the error was already posted to the channel within the window
+// the previous post (with the errors suppressed since) is returned when the error is posted again
+// the error is posted when the suppressions can't be read (an identical error is better than a missing one)
+func (r *Router) suppress(ctx context.Context, channel string, plan eve.NSDeploymentPlan) (*datastore.CronSuppression, bool) {
+	if r.cfg.CronErrorSuppressionWindow <= 0 {
+		return nil, true
+	}
+	key := channel + "/" + fingerprint(plan)
+	previous, post, err := r.store.SuppressCronError(ctx, key, r.now(), r.cfg.CronErrorSuppressionWindow)
+	if err != nil {
+		log.Logger.Error("failed to suppress the cron error", zap.Error(err), zap.String("channel", channel))
+		return nil, true
+	}
+	return previous, post
+}
+
+// fingerprint identifies the identical errors (the namespace, the failed artifacts and the messages)
+func fingerprint(plan eve.NSDeploymentPlan) string {
+	result := datastore.NewDeploymentResult(plan)
+	parts := []string{result.Environment, result.Namespace}
+	var failed []string
+	for _, a := range append(result.Services, result.Jobs...) {
+		if a.Result == eve.DeployArtifactResultFailed {
+			failed = append(failed, a.Name+":"+a.AvailableVersion)
+		}
+	}
+	sort.Strings(failed)
+	parts = append(parts, failed...)
+	parts = append(parts, result.Messages...)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// addDigest batches the result in the digest of the channel (false when it isn't batched, the result is posted right away)
+func (r *Router) addDigest(ctx context.Context, channel string, plan eve.NSDeploymentPlan) bool {
+	now := r.now()
+	entry := datastore.CronDigestEntry{At: now, Result: datastore.NewDeploymentResult(plan)}
+	// the digests that no eve-bot instance posted expire after a few intervals
+	if err := r.store.AddCronDigest(ctx, channel, entry, now.Add(3*r.cfg.CronDigestInterval)); err != nil {
+		log.Logger.Error("failed to add the cron digest entry", zap.Error(err), zap.String("channel", channel))
+		return false
+	}
+	return true
+}
+
+// Run posts the digests batched for an interval every interval until the context is done,
+// the batched digests are posted on shutdown (within the flushTimeout)
+func (r *Router) Run(ctx context.Context) {
+	if r.cfg.CronDigestInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.CronDigestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			r.Flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			r.flush(ctx, r.now().Add(-r.cfg.CronDigestInterval))
+		}
+	}
+}
+
+// Flush posts the batched results of each digest channel
+func (r *Router) Flush(ctx context.Context) {
+	r.flush(ctx, r.now())
+}
+
+// flush posts the digests batched since before (or earlier), the other eve-bot instances post the later ones
+func (r *Router) flush(ctx context.Context, before time.Time) {
+	digests, err := r.store.TakeCronDigests(ctx, before)
+	if err != nil {
+		log.Logger.Error("failed to take the cron digests", zap.Error(err))
+	}
+	for _, digest := range digests {
+		if len(digest.Entries) > 0 {
+			r.chat.PostMessage(ctx, digestMsg(digest.Entries), digest.Channel)
+		}
+	}
+}
+
+// digestMsg summarizes the batched results (one line per namespace plan)
+func digestMsg(entries []datastore.CronDigestEntry) string {
+	var lines []string
+	for _, e := range entries {
+		var artifacts []string
+		for _, a := range append(e.Result.Services, e.Result.Jobs...) {
+			if a.Result != eve.DeployArtifactResultNoop {
+				artifacts = append(artifacts, a.Name+":"+a.AvailableVersion)
+			}
+		}
+		if len(artifacts) == 0 {
+			artifacts = append(artifacts, "nothing deployed")
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%s) %s: %s", e.At.Format("15:04"), e.Result.Namespace, e.Result.Environment, e.Result.Status, strings.Join(artifacts, ", ")))
+	}
+	return fmt.Sprintf("*scheduled deployments digest* (%d results since %s)\n```%s```", len(entries), entries[0].At.Format("15:04"), strings.Join(lines, "\n"))
+}
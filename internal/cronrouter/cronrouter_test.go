@@ -0,0 +1,226 @@
+package cronrouter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// fakeChat records the posted messages by channel (and thread)
+type fakeChat struct {
+	interfaces.ChatProvider
+	posts map[string][]string
+}
+
+func (c *fakeChat) PostMessage(ctx context.Context, msg, channel string) string {
+	c.posts[channel] = append(c.posts[channel], msg)
+	return "1.1"
+}
+
+func (c *fakeChat) PostMessageThread(ctx context.Context, msg, channel, ts string) string {
+	c.posts[channel+"/"+ts] = append(c.posts[channel+"/"+ts], msg)
+	return ""
+}
+
+// fakeCronStore keeps the cron state in memory (like the conditional writes of the datastore)
+type fakeCronStore struct {
+	suppressions map[string]*datastore.CronSuppression
+	digests      map[string]*datastore.CronDigest
+}
+
+func newFakeCronStore() *fakeCronStore {
+	return &fakeCronStore{suppressions: map[string]*datastore.CronSuppression{}, digests: map[string]*datastore.CronDigest{}}
+}
+
+func (f *fakeCronStore) SuppressCronError(ctx context.Context, key string, now time.Time, window time.Duration) (*datastore.CronSuppression, bool, error) {
+	previous, ok := f.suppressions[key]
+	if ok && now.Sub(previous.Posted) < window {
+		previous.Count++
+		return nil, false, nil
+	}
+	f.suppressions[key] = &datastore.CronSuppression{Key: key, Posted: now}
+	if !ok || now.Sub(previous.Posted) >= 2*window {
+		return nil, true, nil
+	}
+	return previous, true, nil
+}
+
+func (f *fakeCronStore) AddCronDigest(ctx context.Context, channel string, entry datastore.CronDigestEntry, expiresAt time.Time) error {
+	digest, ok := f.digests[channel]
+	if !ok {
+		digest = &datastore.CronDigest{Channel: channel, FirstAt: entry.At}
+		f.digests[channel] = digest
+	}
+	digest.Entries = append(digest.Entries, entry)
+	return nil
+}
+
+func (f *fakeCronStore) TakeCronDigests(ctx context.Context, before time.Time) ([]datastore.CronDigest, error) {
+	var digests []datastore.CronDigest
+	for channel, digest := range f.digests {
+		if !digest.FirstAt.After(before) {
+			digests = append(digests, *digest)
+			delete(f.digests, channel)
+		}
+	}
+	return digests, nil
+}
+
+func cronPlan(env, ns, svc string, status eve.DeploymentPlanStatus) eve.NSDeploymentPlan {
+	result := eve.DeployArtifactResultSuccess
+	if status == eve.DeploymentPlanStatusErrors {
+		result = eve.DeployArtifactResultFailed
+	}
+	return eve.NSDeploymentPlan{
+		Namespace:       &eve.NamespaceRequest{Alias: ns, Name: env + "-" + ns},
+		EnvironmentName: env,
+		Services: eve.DeployServices{{
+			DeployArtifact: &eve.DeployArtifact{ArtifactName: svc, AvailableVersion: "1.2", Result: result},
+			ServiceName:    svc,
+		}},
+		Status: status,
+		Type:   eve.DeploymentPlanTypeApplication,
+	}
+}
+
+func TestRoutes_Decode(t *testing.T) {
+	var routes Routes
+	if err := routes.Decode(`[{"environment":"prod","service":"billing*","channels":["C1","C2"],"mention":"S0123"}]`); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || len(routes[0].Channels) != 2 || routes[0].mention() != "!subteam^S0123" {
+		t.Errorf("Decode() = %v", routes)
+	}
+	for _, invalid := range []string{`{}`, `[{"environment":"prod"}]`, `[{"service":"[","channels":["C1"]}]`, `[{"channels":["C1"],"mention":"@team-billing"}]`} {
+		if err := routes.Decode(invalid); err == nil {
+			t.Errorf("Decode(%s) expected an error", invalid)
+		}
+	}
+}
+
+func TestRoute_Matches(t *testing.T) {
+	plan := cronPlan("una-prod", "current", "billing-api", eve.DeploymentPlanStatusComplete)
+	tests := []struct {
+		route Route
+		want  bool
+	}{
+		{route: Route{}, want: true},
+		{route: Route{Environment: "UNA-PROD", Namespace: "current"}, want: true},
+		{route: Route{Environment: "*prod", Service: "billing*"}, want: true},
+		{route: Route{Namespace: "una-prod-current"}, want: true},
+		{route: Route{Environment: "una-int"}, want: false},
+		{route: Route{Service: "api"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.route.Matches(plan); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_Post(t *testing.T) {
+	chat := &fakeChat{posts: map[string][]string{}}
+	r := New(Config{CronRoutes: Routes{
+		{Service: "billing", Channels: []string{"billing", "ops"}, Mention: "<!subteam^S0123|@team-billing>"},
+		{Environment: "una-int", Channels: []string{"int"}},
+	}}, newFakeCronStore(), chat, "https://grafana")
+
+	r.Post(context.Background(), cronPlan("una-prod", "current", "billing", eve.DeploymentPlanStatusComplete), "legacy")
+	r.Post(context.Background(), cronPlan("una-prod", "current", "billing", eve.DeploymentPlanStatusErrors), "legacy")
+	r.Post(context.Background(), cronPlan("una-int", "current", "api", eve.DeploymentPlanStatusErrors), "legacy")
+	r.Post(context.Background(), cronPlan("una-qa", "current", "api", eve.DeploymentPlanStatusErrors), "legacy")
+
+	billing := chat.posts["billing"]
+	if len(billing) != 2 || len(chat.posts["ops"]) != 2 {
+		t.Fatalf("posts = %v, want the billing results in both channels", chat.posts)
+	}
+	if strings.Contains(billing[0], "subteam") || !strings.Contains(billing[1], "<!subteam^S0123|@team-billing>") {
+		t.Errorf("billing posts = %v, want the group mentioned on the error only", billing)
+	}
+	if thread := chat.posts["billing/1.1"]; len(thread) != 1 || !strings.Contains(thread[0], "https://grafana") {
+		t.Errorf("billing thread = %v, want the logs link", thread)
+	}
+	if posts := chat.posts["int"]; len(posts) != 1 || strings.Contains(posts[0], "<!") {
+		t.Errorf("int posts = %v, want the error without a mention", posts)
+	}
+	if legacy := chat.posts["legacy"]; len(legacy) != 1 || !strings.Contains(legacy[0], "<!channel>") {
+		t.Errorf("legacy posts = %v, want the unrouted error with @channel", legacy)
+	}
+}
+
+func TestRouter_SuppressesRepeatedErrors(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	chat := &fakeChat{posts: map[string][]string{}}
+	r := New(Config{CronErrorSuppressionWindow: time.Hour}, newFakeCronStore(), chat, "")
+	r.now = func() time.Time { return now }
+
+	failed := cronPlan("una-int", "current", "api", eve.DeploymentPlanStatusErrors)
+	r.Post(context.Background(), failed, "C1")
+	now = now.Add(10 * time.Minute)
+	r.Post(context.Background(), failed, "C1")
+	r.Post(context.Background(), failed, "C1")
+	// another error isn't identical
+	r.Post(context.Background(), cronPlan("una-int", "current", "billing", eve.DeploymentPlanStatusErrors), "C1")
+	if posts := chat.posts["C1"]; len(posts) != 2 {
+		t.Fatalf("posts = %d, want the repeated errors suppressed", len(posts))
+	}
+
+	now = now.Add(time.Hour)
+	r.Post(context.Background(), failed, "C1")
+	posts := chat.posts["C1"]
+	if len(posts) != 3 || !strings.Contains(posts[2], "2 identical errors were suppressed since 12:00") {
+		t.Errorf("posts = %v, want the error posted again with the suppressed count", posts)
+	}
+}
+
+func TestRouter_Digest(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	chat := &fakeChat{posts: map[string][]string{}}
+	r := New(Config{CronDigestInterval: time.Hour, CronRoutes: Routes{{Environment: "una-int", Channels: []string{"int"}, Digest: true}}}, newFakeCronStore(), chat, "")
+	r.now = func() time.Time { return now }
+
+	r.Post(context.Background(), cronPlan("una-int", "current", "api", eve.DeploymentPlanStatusComplete), "C1")
+	now = now.Add(20 * time.Minute)
+	r.Post(context.Background(), cronPlan("una-int", "next", "billing", eve.DeploymentPlanStatusComplete), "C1")
+	r.Post(context.Background(), cronPlan("una-int", "next", "billing", eve.DeploymentPlanStatusErrors), "C1")
+	if posts := chat.posts["int"]; len(posts) != 1 {
+		t.Fatalf("posts = %v, want only the error before the digest", posts)
+	}
+
+	// the digest isn't batched for an interval yet
+	r.flush(context.Background(), now.Add(-time.Hour))
+	if posts := chat.posts["int"]; len(posts) != 1 {
+		t.Fatalf("posts = %v, want the digest batched for an interval", posts)
+	}
+
+	now = now.Add(40 * time.Minute)
+	r.flush(context.Background(), now.Add(-time.Hour))
+	r.Flush(context.Background())
+	posts := chat.posts["int"]
+	if len(posts) != 2 {
+		t.Fatalf("posts = %v, want a single digest", posts)
+	}
+	for _, want := range []string{"2 results since 12:00", "12:00 current (una-int) complete: api:1.2", "12:20 next (una-int) complete: billing:1.2"} {
+		if !strings.Contains(posts[1], want) {
+			t.Errorf("digest = %q, want %q", posts[1], want)
+		}
+	}
+}
+
+func TestRouter_RunFlushesOnShutdown(t *testing.T) {
+	chat := &fakeChat{posts: map[string][]string{}}
+	r := New(Config{CronDigestInterval: time.Hour, CronRoutes: Routes{{Channels: []string{"int"}, Digest: true}}}, newFakeCronStore(), chat, "")
+	r.Post(context.Background(), cronPlan("una-int", "current", "api", eve.DeploymentPlanStatusComplete), "C1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Run(ctx)
+	if posts := chat.posts["int"]; len(posts) != 1 || !strings.Contains(posts[0], "1 results since") {
+		t.Errorf("posts = %v, want the digest posted on shutdown", posts)
+	}
+}
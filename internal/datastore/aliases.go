@@ -0,0 +1,131 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// AliasScope is the owner of an alias (a single user or everyone in a channel)
+type AliasScope string
+
+const (
+	// AliasScopeUser aliases are only visible to the user that created them
+	AliasScopeUser AliasScope = "user"
+	// AliasScopeChannel aliases are visible to everyone in the channel
+	AliasScopeChannel AliasScope = "channel"
+)
+
+// Alias is a user defined shortcut that expands to a full evebot command
+type Alias struct {
+	// Owner is the partition key (ex: user:U123ABC or channel:C123ABC)
+	Owner     string
+	Name      string
+	Command   string
+	Scope     AliasScope
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// AliasOwner returns the partition key for an alias scope
+func AliasOwner(scope AliasScope, id string) string {
+	return fmt.Sprintf("%s:%s", scope, id)
+}
+
+// SaveAlias creates (or replaces) an alias
+func (s *Store) SaveAlias(ctx context.Context, alias Alias) error {
+	if alias.CreatedAt.IsZero() {
+		alias.CreatedAt = time.Now().UTC()
+	}
+	av, err := dynamodbattribute.MarshalMap(alias)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(s.cfg.AliasTableName),
+	})
+	if err != nil {
+		log.Logger.Error("failed to save alias", zap.Error(err), zap.String("owner", alias.Owner), zap.String("name", alias.Name))
+	}
+	return err
+}
+
+// ReadAlias reads a single alias (errs.ErrNotFound when it doesn't exist)
+func (s *Store) ReadAlias(ctx context.Context, owner, name string) (*Alias, error) {
+	result, err := s.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.cfg.AliasTableName),
+		Key:       aliasKey(owner, name),
+	})
+	if err != nil {
+		log.Logger.Error("failed to get alias item", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, errs.ErrNotFound
+	}
+	alias := Alias{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &alias); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+// ListAliases returns all of the aliases for an owner
+func (s *Store) ListAliases(ctx context.Context, owner string) ([]Alias, error) {
+	var aliases []Alias
+	var unmarshalErr error
+	err := s.db.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.AliasTableName),
+		KeyConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]*string{
+			"#owner": aws.String("Owner"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":owner": {S: aws.String(owner)},
+		},
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []Alias
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		aliases = append(aliases, items...)
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list aliases", zap.Error(err), zap.String("owner", owner))
+		return nil, err
+	}
+	return aliases, unmarshalErr
+}
+
+// DeleteAlias deletes an alias (errs.ErrNotFound when it doesn't exist)
+func (s *Store) DeleteAlias(ctx context.Context, owner, name string) error {
+	result, err := s.db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.cfg.AliasTableName),
+		Key:          aliasKey(owner, name),
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if err != nil {
+		log.Logger.Error("failed to delete alias", zap.Error(err))
+		return err
+	}
+	if result == nil || len(result.Attributes) == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func aliasKey(owner, name string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Owner": {S: aws.String(owner)},
+		"Name":  {S: aws.String(name)},
+	}
+}
@@ -0,0 +1,78 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// ChannelContext holds the default namespace and environment for every command in a channel
+type ChannelContext struct {
+	ChannelID   string
+	ChannelName string
+	Namespace   string
+	Environment string
+	UpdatedBy   string
+	UpdatedAt   time.Time
+}
+
+// SaveChannelContext creates (or replaces) the channel context
+func (s *Store) SaveChannelContext(ctx context.Context, cc ChannelContext) error {
+	cc.UpdatedAt = time.Now().UTC()
+	av, err := dynamodbattribute.MarshalMap(cc)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(s.cfg.ChannelContextTableName),
+	})
+	if err != nil {
+		log.Logger.Error("failed to save channel context", zap.Error(err), zap.String("channel", cc.ChannelID))
+	}
+	return err
+}
+
+// ReadChannelContext reads the channel context (errs.ErrNotFound when it doesn't exist)
+func (s *Store) ReadChannelContext(ctx context.Context, channelID string) (*ChannelContext, error) {
+	result, err := s.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.cfg.ChannelContextTableName),
+		Key:       channelContextKey(channelID),
+	})
+	if err != nil {
+		log.Logger.Error("failed to get channel context item", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, errs.ErrNotFound
+	}
+	cc := ChannelContext{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &cc); err != nil {
+		return nil, err
+	}
+	return &cc, nil
+}
+
+// DeleteChannelContext deletes the channel context
+func (s *Store) DeleteChannelContext(ctx context.Context, channelID string) error {
+	_, err := s.db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.cfg.ChannelContextTableName),
+		Key:       channelContextKey(channelID),
+	})
+	if err != nil {
+		log.Logger.Error("failed to delete channel context", zap.Error(err))
+	}
+	return err
+}
+
+func channelContextKey(channelID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ChannelID": {S: aws.String(channelID)},
+	}
+}
@@ -0,0 +1,92 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Confirmation is a pending confirmation (i.e. the Confirm/Cancel buttons of a metadata upload)
+// the Payload is the json of the change applied once the user confirms, the Action tells how to apply it
+type Confirmation struct {
+	ID        string
+	User      string
+	Channel   string
+	Timestamp string
+	Action    string
+	Payload   string
+	CreatedAt time.Time
+	// ExpiresAt is the DynamoDB TTL attribute (the expired confirmations are also rejected before DynamoDB deletes them)
+	ExpiresAt time.Time `dynamodbav:",unixtime"`
+}
+
+// SaveConfirmation creates the pending confirmation
+func (s *Store) SaveConfirmation(ctx context.Context, c Confirmation) error {
+	av, err := dynamodbattribute.MarshalMap(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(s.cfg.ConfirmationTableName),
+	})
+	if err != nil {
+		log.Logger.Error("failed to save confirmation", zap.Error(err), zap.String("confirmation", c.ID))
+	}
+	return err
+}
+
+// ReadConfirmation reads the pending confirmation (errs.ErrNotFound when it doesn't exist)
+func (s *Store) ReadConfirmation(ctx context.Context, id string) (*Confirmation, error) {
+	result, err := s.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.cfg.ConfirmationTableName),
+		Key:            confirmationKey(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		log.Logger.Error("failed to get confirmation item", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, errs.ErrNotFound
+	}
+	c := Confirmation{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// TakeConfirmation deletes and returns the pending confirmation, only one caller takes it
+// (errs.ErrNotFound when it doesn't exist anymore, i.e. it was taken by a double click or another eve-bot instance)
+func (s *Store) TakeConfirmation(ctx context.Context, id string) (*Confirmation, error) {
+	result, err := s.db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.cfg.ConfirmationTableName),
+		Key:          confirmationKey(id),
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if err != nil {
+		log.Logger.Error("failed to delete confirmation", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || len(result.Attributes) == 0 {
+		return nil, errs.ErrNotFound
+	}
+	c := Confirmation{}
+	if err = dynamodbattribute.UnmarshalMap(result.Attributes, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func confirmationKey(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ID": {S: aws.String(id)},
+	}
+}
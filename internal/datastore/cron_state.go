@@ -0,0 +1,183 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// CronSuppression is the last post of a cron error (by channel and fingerprint) and the identical errors suppressed since
+type CronSuppression struct {
+	Key    string
+	Posted time.Time `dynamodbav:",unixtime"`
+	Count  int
+	// ExpiresAt is the DynamoDB TTL attribute (two suppression windows after the post)
+	ExpiresAt time.Time `dynamodbav:",unixtime"`
+}
+
+// CronDigest is the batched cron results of a digest channel
+type CronDigest struct {
+	Channel string
+	// FirstAt is the time of the first batched result (the digest is posted an interval after it)
+	FirstAt time.Time `dynamodbav:",unixtime"`
+	Entries []CronDigestEntry
+	// ExpiresAt is the DynamoDB TTL attribute (the digests that no eve-bot instance posted)
+	ExpiresAt time.Time `dynamodbav:",unixtime"`
+}
+
+// CronDigestEntry is a batched cron result
+type CronDigestEntry struct {
+	At     time.Time
+	Result DeploymentResult
+}
+
+// SuppressCronError records the post of a cron error unless it was posted within the window,
+// the suppressed errors are counted on the previous post (only one eve-bot instance posts the error)
+// the previous post is returned when the error is posted again (nil when it expired or was never posted)
+func (s *Store) SuppressCronError(ctx context.Context, key string, now time.Time, window time.Duration) (*CronSuppression, bool, error) {
+	av, err := dynamodbattribute.MarshalMap(CronSuppression{Key: key, Posted: now, ExpiresAt: now.Add(2 * window)})
+	if err != nil {
+		return nil, false, err
+	}
+	result, err := s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:                av,
+		TableName:           aws.String(s.cfg.CronSuppressionTableName),
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR Posted <= :cutoff"),
+		ExpressionAttributeNames: map[string]*string{
+			"#key": aws.String("Key"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":cutoff": {N: aws.String(strconv.FormatInt(now.Add(-window).Unix(), 10))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil, false, s.countSuppressedCronError(ctx, key)
+	}
+	if err != nil {
+		log.Logger.Error("failed to save cron suppression", zap.Error(err), zap.String("key", key))
+		return nil, false, err
+	}
+	if result == nil || len(result.Attributes) == 0 {
+		return nil, true, nil
+	}
+	previous := CronSuppression{}
+	if err = dynamodbattribute.UnmarshalMap(result.Attributes, &previous); err != nil {
+		return nil, true, err
+	}
+	// the suppressed count of an older post is stale (DynamoDB deletes the expired items lazily)
+	if now.Sub(previous.Posted) >= 2*window {
+		return nil, true, nil
+	}
+	return &previous, true, nil
+}
+
+func (s *Store) countSuppressedCronError(ctx context.Context, key string) error {
+	_, err := s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.cfg.CronSuppressionTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key": {S: aws.String(key)},
+		},
+		UpdateExpression: aws.String("ADD #count :one"),
+		ExpressionAttributeNames: map[string]*string{
+			"#count": aws.String("Count"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {N: aws.String("1")},
+		},
+	})
+	if err != nil {
+		log.Logger.Error("failed to count suppressed cron error", zap.Error(err), zap.String("key", key))
+	}
+	return err
+}
+
+// AddCronDigest appends the cron result to the digest of the channel
+func (s *Store) AddCronDigest(ctx context.Context, channel string, entry CronDigestEntry, expiresAt time.Time) error {
+	av, err := dynamodbattribute.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(s.cfg.CronDigestTableName),
+		Key:              cronDigestKey(channel),
+		UpdateExpression: aws.String("SET Entries = list_append(if_not_exists(Entries, :empty), :entries), FirstAt = if_not_exists(FirstAt, :at), ExpiresAt = :expires"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":empty":   {L: []*dynamodb.AttributeValue{}},
+			":entries": {L: []*dynamodb.AttributeValue{av}},
+			":at":      {N: aws.String(strconv.FormatInt(entry.At.Unix(), 10))},
+			":expires": {N: aws.String(strconv.FormatInt(expiresAt.Unix(), 10))},
+		},
+	})
+	if err != nil {
+		log.Logger.Error("failed to add cron digest entry", zap.Error(err), zap.String("channel", channel))
+	}
+	return err
+}
+
+// TakeCronDigests deletes and returns the digests batched since before (or earlier),
+// each digest is taken by a single caller (i.e. another eve-bot instance doesn't post it again)
+func (s *Store) TakeCronDigests(ctx context.Context, before time.Time) ([]CronDigest, error) {
+	var channels []string
+	var unmarshalErr error
+	err := s.db.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName:            aws.String(s.cfg.CronDigestTableName),
+		FilterExpression:     aws.String("FirstAt <= :before"),
+		ProjectionExpression: aws.String("Channel"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":before": {N: aws.String(strconv.FormatInt(before.Unix(), 10))},
+		},
+	}, func(page *dynamodb.ScanOutput, _ bool) bool {
+		var items []CronDigest
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		for _, item := range items {
+			channels = append(channels, item.Channel)
+		}
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list cron digests", zap.Error(err))
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+
+	var digests []CronDigest
+	for _, channel := range channels {
+		result, err := s.db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+			TableName:    aws.String(s.cfg.CronDigestTableName),
+			Key:          cronDigestKey(channel),
+			ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+		})
+		if err != nil {
+			log.Logger.Error("failed to delete cron digest", zap.Error(err), zap.String("channel", channel))
+			return digests, err
+		}
+		// the digest was taken by another eve-bot instance
+		if result == nil || len(result.Attributes) == 0 {
+			continue
+		}
+		digest := CronDigest{}
+		if err = dynamodbattribute.UnmarshalMap(result.Attributes, &digest); err != nil {
+			return digests, err
+		}
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+func cronDigestKey(channel string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Channel": {S: aws.String(channel)},
+	}
+}
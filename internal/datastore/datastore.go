@@ -0,0 +1,50 @@
+package datastore
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Config data structure for the eve-bot data store (DynamoDB tables)
+// EVEBOT_ALIAS_TABLE_NAME
+// EVEBOT_CHANNEL_CONTEXT_TABLE_NAME
+// EVEBOT_METADATA_HISTORY_TABLE_NAME
+// EVEBOT_DEPLOYMENT_TABLE_NAME
+// EVEBOT_SUBSCRIPTION_TABLE_NAME
+// EVEBOT_CONFIRMATION_TABLE_NAME
+// EVEBOT_CRON_SUPPRESSION_TABLE_NAME
+// EVEBOT_CRON_DIGEST_TABLE_NAME
+// EVEBOT_DEPLOYMENT_SWEEP_INDEX_NAME
+// EVEBOT_DEPLOYMENT_ENVIRONMENT_INDEX_NAME
+// EVEBOT_DEPLOYMENT_RETENTION
+type Config struct {
+	AliasTableName           string `split_words:"true" default:"eve-bot-aliases"`
+	ChannelContextTableName  string `split_words:"true" default:"eve-bot-channel-contexts"`
+	MetadataHistoryTableName string `split_words:"true" default:"eve-bot-metadata-history"`
+	DeploymentTableName      string `split_words:"true" default:"eve-bot-deployments"`
+	SubscriptionTableName    string `split_words:"true" default:"eve-bot-subscriptions"`
+	ConfirmationTableName    string `split_words:"true" default:"eve-bot-confirmations"`
+	CronSuppressionTableName string `split_words:"true" default:"eve-bot-cron-suppressions"`
+	CronDigestTableName      string `split_words:"true" default:"eve-bot-cron-digests"`
+	// DeploymentSweepIndexName is the sparse index of the pending deployments (Sweep partition key, Deadline sort key)
+	DeploymentSweepIndexName string `split_words:"true" default:"Sweep-Deadline-index"`
+	// DeploymentEnvironmentIndexName is the index of the deployment history (Environment partition key, CreatedAtUnix sort key)
+	DeploymentEnvironmentIndexName string `split_words:"true" default:"Environment-CreatedAtUnix-index"`
+	// DeploymentRetention is the time to live of the deployment records (the ExpiresAt TTL attribute, 0 keeps them)
+	DeploymentRetention time.Duration `split_words:"true" default:"2160h"`
+}
+
+// Store persists the eve-bot owned records (aliases, channel contexts, metadata history, deployments, subscriptions, confirmations, cron state, etc.) in DynamoDB
+type Store struct {
+	cfg Config
+	db  *dynamodb.DynamoDB
+}
+
+// New creates a new data store
+func New(cfg Config, db *dynamodb.DynamoDB) *Store {
+	return &Store{
+		cfg: cfg,
+		db:  db,
+	}
+}
@@ -0,0 +1,454 @@
+package datastore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/unanet/eve/pkg/eve"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Deployment is the record of a deployment request (deploy, restart, run) correlated with its eve-api callbacks
+// only the ID is passed in the callback URL, the callbacks look up the user and the thread of the command
+type Deployment struct {
+	// ID is the partition key, the correlation id of the callbacks
+	ID string
+	// Command is the originating command (deploy, restart, run)
+	Command string
+	// Plan are the deployment plan options sent to the eve-api
+	Plan    eve.DeploymentPlanOptions
+	User    string
+	Channel string
+	TS      string
+	// PlanIDs are the eve-api deployment ids (one namespace deployment plan and callback per id)
+	PlanIDs []string
+	// Plans are the statuses of the namespace deployment plans by deployment id (from the callbacks)
+	Plans map[string]eve.DeploymentPlanStatus
+	// Results are the (final) callbacks of the namespace deployment plans by deployment id
+	Results map[string]DeploymentResult
+	// Status is the status of the latest callback (empty until the first callback)
+	Status eve.DeploymentPlanStatus
+	// Deadline is the expected completion of the deployment (zero when the deployment isn't swept)
+	Deadline time.Time `dynamodbav:",unixtime"`
+	// OverdueAt is when the missing callbacks were reported (see ListOverdueDeployments)
+	OverdueAt *time.Time `dynamodbav:",omitempty"`
+	// Environment is the (lowercase) requested environment, the partition key of the history index
+	Environment string `dynamodbav:",omitempty"`
+	// Sweep is the partition key of the sparse sweep index (set while a deployment with a deadline is pending)
+	Sweep string `dynamodbav:",omitempty"`
+	// ExpiresAt is the DynamoDB TTL attribute (see DeploymentRetention)
+	ExpiresAt *time.Time `dynamodbav:",omitempty,unixtime"`
+	// CreatedAtUnix is the sort key of the history index (the epoch seconds of the CreatedAt, the RFC3339Nano
+	// strings don't sort by time: the trailing zeros of the fraction are trimmed)
+	CreatedAtUnix int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// sweepPending is the Sweep value of the deployments waiting for their callbacks
+const sweepPending = "pending"
+
+// DeploymentResult is the callback of a namespace deployment plan (without the metadata and the definitions)
+type DeploymentResult struct {
+	Namespace   string
+	Environment string
+	Cluster     string
+	Type        eve.PlanType
+	Status      eve.DeploymentPlanStatus
+	Messages    []string
+	Services    []DeployedArtifact
+	Jobs        []DeployedArtifact
+}
+
+// DeployedArtifact is the result of a service (or job) in a namespace deployment plan
+type DeployedArtifact struct {
+	Name             string
+	Artifact         string
+	RequestedVersion string
+	DeployedVersion  string
+	AvailableVersion string
+	Result           eve.DeployArtifactResult
+}
+
+// NewDeploymentResult trims the callback payload of a namespace deployment plan
+func NewDeploymentResult(plan eve.NSDeploymentPlan) DeploymentResult {
+	r := DeploymentResult{
+		Environment: plan.EnvironmentName,
+		Type:        plan.Type,
+		Status:      plan.Status,
+		Messages:    plan.Messages,
+	}
+	if plan.Namespace != nil {
+		r.Namespace = plan.Namespace.Alias
+		r.Cluster = plan.Namespace.ClusterName
+	}
+	for _, svc := range plan.Services {
+		if svc != nil && svc.DeployArtifact != nil {
+			r.Services = append(r.Services, newDeployedArtifact(svc.ServiceName, svc.DeployArtifact))
+		}
+	}
+	for _, job := range plan.Jobs {
+		if job != nil && job.DeployArtifact != nil {
+			r.Jobs = append(r.Jobs, newDeployedArtifact(job.JobName, job.DeployArtifact))
+		}
+	}
+	return r
+}
+
+func newDeployedArtifact(name string, a *eve.DeployArtifact) DeployedArtifact {
+	return DeployedArtifact{
+		Name:             name,
+		Artifact:         a.ArtifactName,
+		RequestedVersion: a.RequestedVersion,
+		DeployedVersion:  a.DeployedVersion,
+		AvailableVersion: a.AvailableVersion,
+		Result:           a.Result,
+	}
+}
+
+// Pending checks if the deployment is still waiting for a callback (a namespace plan without a terminal status)
+func (d Deployment) Pending() bool {
+	if len(d.PlanIDs) == 0 {
+		return len(d.Status) == 0 || d.Status == eve.DeploymentPlanStatusPending
+	}
+	for _, id := range d.PlanIDs {
+		if status, ok := d.Plans[id]; !ok || status == eve.DeploymentPlanStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// DeploymentFilter selects the deployments of the history (see ListDeployments)
+type DeploymentFilter struct {
+	// Since is the oldest creation time
+	Since time.Time
+	// Namespace is the namespace alias
+	Namespace string
+	// Environments are the name and the alias of the environment (the requested environment of the deployments)
+	Environments []string
+	// Service is the service or artifact name (optional)
+	Service string
+}
+
+// Matches checks if the deployment targets the namespace (and the service) of the filter
+// the deployment plan options are matched until the callbacks have the resolved namespaces and services
+func (f DeploymentFilter) Matches(d Deployment) bool {
+	envs, namespaces, services := []string{d.Plan.Environment}, []string(d.Plan.NamespaceAliases), make([]string, 0, len(d.Plan.Artifacts))
+	for _, a := range d.Plan.Artifacts {
+		services = append(services, a.Name)
+	}
+	for _, r := range d.Results {
+		envs, namespaces = append(envs, r.Environment), append(namespaces, r.Namespace)
+		for _, a := range append(r.Services, r.Jobs...) {
+			services = append(services, a.Name, a.Artifact)
+		}
+	}
+	if !containsFold(envs, f.Environments...) {
+		return false
+	}
+	// no namespaces (or artifacts) is the whole environment (or namespace)
+	if len(namespaces) > 0 && !containsFold(namespaces, f.Namespace) {
+		return false
+	}
+	return len(f.Service) == 0 || len(services) == 0 || containsFold(services, f.Service)
+}
+
+func containsFold(values []string, candidates ...string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if len(c) > 0 && strings.EqualFold(v, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewDeploymentID generates a random deployment correlation id
+func NewDeploymentID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SaveDeployment creates (or replaces) the deployment record
+func (s *Store) SaveDeployment(ctx context.Context, d Deployment) error {
+	now := time.Now().UTC()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	d.CreatedAtUnix = d.CreatedAt.Unix()
+	d.UpdatedAt = now
+	if d.Plans == nil {
+		// the callbacks set the statuses in the map (it has to exist)
+		d.Plans = make(map[string]eve.DeploymentPlanStatus)
+	}
+	if d.Results == nil {
+		d.Results = make(map[string]DeploymentResult)
+	}
+	// the eve-api assigns the deployment ids (see UpdateDeploymentPlanIDs)
+	d.Plan.DeploymentIDs = nil
+	d.Environment = strings.ToLower(d.Plan.Environment)
+	d.Sweep = ""
+	if !d.Deadline.IsZero() && d.OverdueAt == nil && d.Pending() {
+		d.Sweep = sweepPending
+	}
+	if s.cfg.DeploymentRetention > 0 {
+		expiresAt := d.CreatedAt.Add(s.cfg.DeploymentRetention)
+		d.ExpiresAt = &expiresAt
+	}
+	av, err := dynamodbattribute.MarshalMap(d)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(s.cfg.DeploymentTableName),
+	})
+	if err != nil {
+		log.Logger.Error("failed to save deployment", zap.Error(err), zap.String("id", d.ID))
+	}
+	return err
+}
+
+// ReadDeployment reads the deployment record (errs.ErrNotFound when it doesn't exist)
+func (s *Store) ReadDeployment(ctx context.Context, id string) (*Deployment, error) {
+	result, err := s.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.cfg.DeploymentTableName),
+		Key:       deploymentKey(id),
+	})
+	if err != nil {
+		log.Logger.Error("failed to get deployment item", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, errs.ErrNotFound
+	}
+	d := Deployment{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// UpdateDeploymentPlanIDs sets the eve-api deployment ids of the deployment (the callbacks may already be recorded),
+// the deployment is removed from the sweep index when every plan already called back (see RecordDeploymentCallback)
+func (s *Store) UpdateDeploymentPlanIDs(ctx context.Context, id string, planIDs []string) error {
+	ids, err := dynamodbattribute.Marshal(planIDs)
+	if err != nil {
+		return err
+	}
+	output, err := s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.cfg.DeploymentTableName),
+		Key:                 deploymentKey(id),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+		UpdateExpression:    aws.String("SET PlanIDs = :ids, UpdatedAt = :now"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":ids": ids,
+			":now": {S: aws.String(time.Now().UTC().Format(time.RFC3339Nano))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		log.Logger.Error("failed to update deployment plan ids", zap.Error(err), zap.String("id", id))
+		return notFound(err)
+	}
+	d := Deployment{}
+	if err = dynamodbattribute.UnmarshalMap(output.Attributes, &d); err != nil {
+		return err
+	}
+	if len(d.Sweep) > 0 && !d.Pending() {
+		s.removeSweep(ctx, id)
+	}
+	return nil
+}
+
+// RecordDeploymentCallback records the status (and the result) of a namespace deployment plan and returns the updated deployment
+// (errs.ErrNotFound when the deployment doesn't exist)
+func (s *Store) RecordDeploymentCallback(ctx context.Context, id string, plan eve.NSDeploymentPlan) (*Deployment, error) {
+	update := "SET Plans.#plan = :status, #status = :status, UpdatedAt = :now"
+	values := map[string]*dynamodb.AttributeValue{
+		":status": {S: aws.String(string(plan.Status))},
+		":now":    {S: aws.String(time.Now().UTC().Format(time.RFC3339Nano))},
+	}
+	// the message callbacks only have the messages (they would replace the services of the result)
+	if plan.Status != eve.DeploymentPlanStatusMessage {
+		result, err := dynamodbattribute.Marshal(NewDeploymentResult(plan))
+		if err != nil {
+			return nil, err
+		}
+		update += ", Results.#plan = :result"
+		values[":result"] = result
+	}
+	output, err := s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.cfg.DeploymentTableName),
+		Key:                 deploymentKey(id),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+		UpdateExpression:    aws.String(update),
+		ExpressionAttributeNames: map[string]*string{
+			"#plan":   aws.String(plan.DeploymentID.String()),
+			"#status": aws.String("Status"),
+		},
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		log.Logger.Error("failed to record deployment callback", zap.Error(err), zap.String("id", id))
+		return nil, notFound(err)
+	}
+	d := Deployment{}
+	if err = dynamodbattribute.UnmarshalMap(output.Attributes, &d); err != nil {
+		return nil, err
+	}
+	// the plan ids are written once the eve-api accepted the deployment, a callback can land before:
+	// the deployment stays in the sweep index until UpdateDeploymentPlanIDs (the other plans may still be pending)
+	if len(d.Sweep) > 0 && len(d.PlanIDs) > 0 && !d.Pending() {
+		s.removeSweep(ctx, id)
+	}
+	return &d, nil
+}
+
+// removeSweep removes the deployment from the sweep index (every namespace plan called back)
+func (s *Store) removeSweep(ctx context.Context, id string) {
+	_, err := s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.cfg.DeploymentTableName),
+		Key:                 deploymentKey(id),
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+		UpdateExpression:    aws.String("REMOVE Sweep"),
+	})
+	if err != nil {
+		// the sweeper skips the deployments that aren't pending anyway
+		log.Logger.Warn("failed to remove the deployment from the sweep index", zap.Error(err), zap.String("id", id))
+	}
+}
+
+// ListDeployments returns the deployments of the filter (the most recent first)
+// the deployments of each environment of the filter are queried from the history index
+func (s *Store) ListDeployments(ctx context.Context, filter DeploymentFilter) ([]Deployment, error) {
+	var result []Deployment
+	seen := make(map[string]bool)
+	for _, env := range filter.Environments {
+		env = strings.ToLower(env)
+		if len(env) == 0 || seen[env] {
+			continue
+		}
+		seen[env] = true
+		deployments, err := s.listEnvironmentDeployments(ctx, env, filter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, deployments...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (s *Store) listEnvironmentDeployments(ctx context.Context, env string, filter DeploymentFilter) ([]Deployment, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.DeploymentTableName),
+		IndexName:              aws.String(s.cfg.DeploymentEnvironmentIndexName),
+		KeyConditionExpression: aws.String("Environment = :env AND CreatedAtUnix >= :since"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":env":   {S: aws.String(env)},
+			":since": {N: aws.String(strconv.FormatInt(filter.Since.Unix(), 10))},
+		},
+	}
+	var result []Deployment
+	var unmarshalErr error
+	err := s.db.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, _ bool) bool {
+		var deployments []Deployment
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &deployments); unmarshalErr != nil {
+			return false
+		}
+		for _, d := range deployments {
+			if filter.Matches(d) {
+				result = append(result, d)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list deployments", zap.Error(err), zap.String("environment", env))
+		return nil, err
+	}
+	return result, unmarshalErr
+}
+
+// ListOverdueDeployments returns the pending deployments past their deadline that weren't reported yet (from the sweep index)
+func (s *Store) ListOverdueDeployments(ctx context.Context, now time.Time) ([]Deployment, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.DeploymentTableName),
+		IndexName:              aws.String(s.cfg.DeploymentSweepIndexName),
+		KeyConditionExpression: aws.String("Sweep = :pending AND Deadline <= :now"),
+		FilterExpression:       aws.String("attribute_not_exists(OverdueAt)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pending": {S: aws.String(sweepPending)},
+			":now":     {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+		},
+	}
+	var overdue []Deployment
+	var unmarshalErr error
+	err := s.db.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, _ bool) bool {
+		var deployments []Deployment
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &deployments); unmarshalErr != nil {
+			return false
+		}
+		for _, d := range deployments {
+			if d.Pending() {
+				overdue = append(overdue, d)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list overdue deployments", zap.Error(err))
+		return nil, err
+	}
+	return overdue, unmarshalErr
+}
+
+// MarkDeploymentOverdue records that the missing callbacks were reported
+// errs.ErrNotFound is returned when the deployment was already reported (i.e. by another eve-bot instance)
+func (s *Store) MarkDeploymentOverdue(ctx context.Context, id string, at time.Time) error {
+	_, err := s.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.cfg.DeploymentTableName),
+		Key:                 deploymentKey(id),
+		ConditionExpression: aws.String("attribute_exists(ID) AND attribute_not_exists(OverdueAt)"),
+		UpdateExpression:    aws.String("SET OverdueAt = :at REMOVE Sweep"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":at": {S: aws.String(at.UTC().Format(time.RFC3339Nano))},
+		},
+	})
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// notFound maps the failed attribute_exists conditions to errs.ErrNotFound
+func notFound(err error) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return errs.ErrNotFound
+	}
+	return err
+}
+
+func deploymentKey(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ID": {S: aws.String(id)},
+	}
+}
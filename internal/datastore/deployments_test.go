@@ -0,0 +1,99 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestDeployment_Pending(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment Deployment
+		want       bool
+	}{
+		{name: "no callback", deployment: Deployment{}, want: true},
+		{name: "failed request", deployment: Deployment{Status: eve.DeploymentPlanStatusErrors}, want: false},
+		{name: "waiting for a namespace", deployment: Deployment{
+			PlanIDs: []string{"a", "b"},
+			Plans:   map[string]eve.DeploymentPlanStatus{"a": eve.DeploymentPlanStatusComplete},
+			Status:  eve.DeploymentPlanStatusComplete,
+		}, want: true},
+		{name: "namespace pending", deployment: Deployment{
+			PlanIDs: []string{"a"},
+			Plans:   map[string]eve.DeploymentPlanStatus{"a": eve.DeploymentPlanStatusPending},
+		}, want: true},
+		{name: "every namespace done", deployment: Deployment{
+			PlanIDs: []string{"a", "b"},
+			Plans:   map[string]eve.DeploymentPlanStatus{"a": eve.DeploymentPlanStatusComplete, "b": eve.DeploymentPlanStatusErrors},
+		}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.deployment.Pending(); got != tt.want {
+				t.Errorf("Pending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeploymentFilter_Matches(t *testing.T) {
+	requested := Deployment{Plan: eve.DeploymentPlanOptions{
+		Environment:      "int",
+		NamespaceAliases: eve.StringList{"current"},
+		Artifacts:        eve.ArtifactDefinitions{{Name: "api"}},
+	}}
+	completed := Deployment{
+		Plan: eve.DeploymentPlanOptions{Environment: "int"},
+		Results: map[string]DeploymentResult{"a": NewDeploymentResult(eve.NSDeploymentPlan{
+			Namespace:       &eve.NamespaceRequest{Alias: "current", ClusterName: "c1"},
+			EnvironmentName: "una-int",
+			Services: eve.DeployServices{{
+				DeployArtifact: &eve.DeployArtifact{ArtifactName: "billing-api", AvailableVersion: "1.2", Metadata: eve.MetadataField{"secret": "x"}},
+				ServiceName:    "billing",
+			}},
+			Status: eve.DeploymentPlanStatusComplete,
+		})},
+	}
+	tests := []struct {
+		name       string
+		deployment Deployment
+		filter     DeploymentFilter
+		want       bool
+	}{
+		{name: "requested namespace", deployment: requested, filter: DeploymentFilter{Namespace: "CURRENT", Environments: []string{"int"}}, want: true},
+		{name: "requested service", deployment: requested, filter: DeploymentFilter{Namespace: "current", Environments: []string{"int"}, Service: "api"}, want: true},
+		{name: "other service", deployment: requested, filter: DeploymentFilter{Namespace: "current", Environments: []string{"int"}, Service: "billing"}, want: false},
+		{name: "other namespace", deployment: requested, filter: DeploymentFilter{Namespace: "next", Environments: []string{"int"}}, want: false},
+		{name: "other environment", deployment: requested, filter: DeploymentFilter{Namespace: "current", Environments: []string{"qa", "una-qa"}}, want: false},
+		{name: "whole environment", deployment: Deployment{Plan: eve.DeploymentPlanOptions{Environment: "int"}}, filter: DeploymentFilter{Namespace: "next", Environments: []string{"int"}, Service: "api"}, want: true},
+		{name: "callback environment name", deployment: completed, filter: DeploymentFilter{Namespace: "current", Environments: []string{"una-int"}}, want: true},
+		{name: "callback artifact", deployment: completed, filter: DeploymentFilter{Namespace: "current", Environments: []string{"int"}, Service: "billing-api"}, want: true},
+		{name: "callback other service", deployment: completed, filter: DeploymentFilter{Namespace: "current", Environments: []string{"int"}, Service: "api"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.deployment); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployment_TTLAttributes(t *testing.T) {
+	expiresAt := time.Unix(1700000000, 0)
+	av, err := dynamodbattribute.MarshalMap(Deployment{ID: "1", ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the TTL attribute is a number (epoch seconds) and the sweep index is sparse
+	if av["ExpiresAt"] == nil || av["ExpiresAt"].N == nil || *av["ExpiresAt"].N != "1700000000" {
+		t.Errorf("ExpiresAt = %v, want the epoch seconds", av["ExpiresAt"])
+	}
+	if _, ok := av["Sweep"]; ok {
+		t.Errorf("Sweep = %v, want the attribute to be omitted", av["Sweep"])
+	}
+}
@@ -0,0 +1,116 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// saveRevisionAttempts is the number of attempts to save a revision when the revision number is taken (concurrent saves)
+const saveRevisionAttempts = 3
+
+// MetadataRevision is a snapshot of a metadata value before it was changed
+type MetadataRevision struct {
+	// Key is the partition key, the metadata description (ex: eve-bot:api:cloud-int)
+	Key string
+	// Revision is the sort key, the revisions of a key are numbered from 1
+	Revision   int
+	MetadataID int
+	Value      map[string]interface{}
+	// Action is the change that followed the snapshot (set, delete, restore...)
+	Action    string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// SaveMetadataRevision saves the snapshot as the next revision of the key and returns the saved revision
+func (s *Store) SaveMetadataRevision(ctx context.Context, rev MetadataRevision) (MetadataRevision, error) {
+	rev.CreatedAt = time.Now().UTC()
+	for attempt := 1; ; attempt++ {
+		latest, err := s.ListMetadataRevisions(ctx, rev.Key, 1)
+		if err != nil {
+			return rev, err
+		}
+		rev.Revision = 1
+		if len(latest) > 0 {
+			rev.Revision = latest[0].Revision + 1
+		}
+
+		av, err := dynamodbattribute.MarshalMap(rev)
+		if err != nil {
+			return rev, err
+		}
+		_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			Item:                av,
+			TableName:           aws.String(s.cfg.MetadataHistoryTableName),
+			ConditionExpression: aws.String("attribute_not_exists(Revision)"),
+		})
+		if err == nil {
+			return rev, nil
+		}
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException && attempt < saveRevisionAttempts {
+			continue
+		}
+		log.Logger.Error("failed to save metadata revision", zap.Error(err), zap.String("key", rev.Key), zap.Int("revision", rev.Revision))
+		return rev, err
+	}
+}
+
+// ListMetadataRevisions returns the latest revisions of the key (newest first)
+func (s *Store) ListMetadataRevisions(ctx context.Context, key string, limit int) ([]MetadataRevision, error) {
+	result, err := s.db.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.MetadataHistoryTableName),
+		KeyConditionExpression: aws.String("#key = :key"),
+		ExpressionAttributeNames: map[string]*string{
+			"#key": aws.String("Key"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":key": {S: aws.String(key)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		log.Logger.Error("failed to list metadata revisions", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+	var revs []MetadataRevision
+	if err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// ReadMetadataRevision reads a single revision of the key (errs.ErrNotFound when it doesn't exist)
+func (s *Store) ReadMetadataRevision(ctx context.Context, key string, revision int) (*MetadataRevision, error) {
+	av, err := dynamodbattribute.Marshal(revision)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.cfg.MetadataHistoryTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key":      {S: aws.String(key)},
+			"Revision": av,
+		},
+	})
+	if err != nil {
+		log.Logger.Error("failed to get metadata revision item", zap.Error(err))
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, errs.ErrNotFound
+	}
+	rev := MetadataRevision{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,149 @@
+package datastore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/unanet/eve/pkg/eve"
+	errs "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Subscription notifies a user (in a private message) or a channel of the deployments
+// in a namespace or an environment and/or of a service
+type Subscription struct {
+	// Owner is the partition key, the user that subscribed
+	Owner string
+	// Key is the sort key (see SubscriptionKey)
+	Key string
+	// Namespace is the namespace alias (empty for every namespace of the environment)
+	Namespace string
+	// Environment is the environment name (empty for every environment)
+	Environment string
+	// Service is the service or artifact name (empty for every service)
+	Service string
+	// Channel is the channel of the notifications (empty for a private message)
+	Channel   string
+	CreatedAt time.Time
+}
+
+// SubscriptionKey returns the sort key of a subscription (one subscription per channel and scope)
+func SubscriptionKey(channel, namespace, environment, service string) string {
+	if len(channel) == 0 {
+		channel = "dm"
+	}
+	return strings.ToLower(strings.Join([]string{channel, environment, namespace, service}, "/"))
+}
+
+// Matches checks if the namespace deployment plan result notifies the subscription
+// only the deployed (or failed) services and jobs are matched, the plans that didn't change anything are ignored
+func (s Subscription) Matches(r DeploymentResult) bool {
+	if len(s.Environment) > 0 && !strings.EqualFold(s.Environment, r.Environment) {
+		return false
+	}
+	if len(s.Namespace) > 0 && !strings.EqualFold(s.Namespace, r.Namespace) {
+		return false
+	}
+	for _, a := range append(r.Services, r.Jobs...) {
+		if a.Result == eve.DeployArtifactResultNoop {
+			continue
+		}
+		if len(s.Service) == 0 || strings.EqualFold(s.Service, a.Name) || strings.EqualFold(s.Service, a.Artifact) {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveSubscription creates (or replaces) a subscription
+func (s *Store) SaveSubscription(ctx context.Context, sub Subscription) error {
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now().UTC()
+	}
+	av, err := dynamodbattribute.MarshalMap(sub)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(s.cfg.SubscriptionTableName),
+	})
+	if err != nil {
+		log.Logger.Error("failed to save subscription", zap.Error(err), zap.String("owner", sub.Owner), zap.String("key", sub.Key))
+	}
+	return err
+}
+
+// ListSubscriptions returns the subscriptions of a user
+func (s *Store) ListSubscriptions(ctx context.Context, owner string) ([]Subscription, error) {
+	var subscriptions []Subscription
+	var unmarshalErr error
+	err := s.db.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.cfg.SubscriptionTableName),
+		KeyConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]*string{
+			"#owner": aws.String("Owner"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":owner": {S: aws.String(owner)},
+		},
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		var items []Subscription
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		subscriptions = append(subscriptions, items...)
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list subscriptions", zap.Error(err), zap.String("owner", owner))
+		return nil, err
+	}
+	return subscriptions, unmarshalErr
+}
+
+// ListAllSubscriptions returns the subscriptions of every user (the deployment callbacks match them)
+func (s *Store) ListAllSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	var unmarshalErr error
+	err := s.db.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(s.cfg.SubscriptionTableName),
+	}, func(page *dynamodb.ScanOutput, _ bool) bool {
+		var items []Subscription
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false
+		}
+		subscriptions = append(subscriptions, items...)
+		return true
+	})
+	if err != nil {
+		log.Logger.Error("failed to list all subscriptions", zap.Error(err))
+		return nil, err
+	}
+	return subscriptions, unmarshalErr
+}
+
+// DeleteSubscription deletes a subscription (errs.ErrNotFound when it doesn't exist)
+func (s *Store) DeleteSubscription(ctx context.Context, owner, key string) error {
+	result, err := s.db.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.cfg.SubscriptionTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Owner": {S: aws.String(owner)},
+			"Key":   {S: aws.String(key)},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if err != nil {
+		log.Logger.Error("failed to delete subscription", zap.Error(err))
+		return err
+	}
+	if result == nil || len(result.Attributes) == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	result := NewDeploymentResult(eve.NSDeploymentPlan{
+		Namespace:       &eve.NamespaceRequest{Alias: "current"},
+		EnvironmentName: "una-prod",
+		Services: eve.DeployServices{
+			{DeployArtifact: &eve.DeployArtifact{ArtifactName: "billing-api", Result: eve.DeployArtifactResultSuccess}, ServiceName: "billing"},
+			{DeployArtifact: &eve.DeployArtifact{ArtifactName: "api", Result: eve.DeployArtifactResultNoop}, ServiceName: "api"},
+		},
+		Status: eve.DeploymentPlanStatusComplete,
+	})
+	tests := []struct {
+		name string
+		sub  Subscription
+		want bool
+	}{
+		{name: "namespace", sub: Subscription{Namespace: "Current", Environment: "una-prod"}, want: true},
+		{name: "environment", sub: Subscription{Environment: "una-prod"}, want: true},
+		{name: "service", sub: Subscription{Service: "billing"}, want: true},
+		{name: "artifact", sub: Subscription{Environment: "una-prod", Service: "billing-api"}, want: true},
+		{name: "unchanged service", sub: Subscription{Service: "api"}, want: false},
+		{name: "other namespace", sub: Subscription{Namespace: "next", Environment: "una-prod"}, want: false},
+		{name: "other environment", sub: Subscription{Environment: "una-int", Service: "billing"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.Matches(result); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionKey(t *testing.T) {
+	if got := SubscriptionKey("", "Current", "una-prod", "Billing"); got != "dm/una-prod/current/billing" {
+		t.Errorf("SubscriptionKey() = %q", got)
+	}
+	if SubscriptionKey("C1", "", "una-prod", "") == SubscriptionKey("", "", "una-prod", "") {
+		t.Errorf("expected distinct channel and direct message keys")
+	}
+}
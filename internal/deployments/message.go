@@ -0,0 +1,115 @@
+// Package deployments formats the deployment records (see datastore.Deployment) in the chat messages
+package deployments
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// deploymentStatus is the status of the whole deployment (pending until every namespace plan called back)
+func deploymentStatus(d datastore.Deployment) string {
+	if d.Pending() {
+		if d.OverdueAt != nil {
+			return "overdue"
+		}
+		return string(eve.DeploymentPlanStatusPending)
+	}
+	return string(d.Status)
+}
+
+// deploymentArtifacts are the deployed services and jobs (name:version) of the callbacks,
+// or the requested artifacts until the callbacks have the results
+func deploymentArtifacts(d datastore.Deployment) []string {
+	var artifacts []string
+	for _, id := range deploymentPlanIDs(d) {
+		for _, a := range append(d.Results[id].Services, d.Results[id].Jobs...) {
+			artifacts = append(artifacts, a.Name+":"+a.AvailableVersion)
+		}
+	}
+	if len(artifacts) > 0 {
+		return artifacts
+	}
+	for _, a := range d.Plan.Artifacts {
+		if len(a.RequestedVersion) > 0 {
+			artifacts = append(artifacts, a.Name+":"+a.RequestedVersion)
+		} else {
+			artifacts = append(artifacts, a.Name)
+		}
+	}
+	if len(artifacts) == 0 {
+		artifacts = append(artifacts, "all")
+	}
+	return artifacts
+}
+
+// deploymentPlanIDs are the namespace plans of the deployment in order (the results without a plan id last)
+func deploymentPlanIDs(d datastore.Deployment) []string {
+	ids := append([]string{}, d.PlanIDs...)
+	for id := range d.Results {
+		found := false
+		for _, planID := range d.PlanIDs {
+			found = found || planID == id
+		}
+		if !found {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ListMessage formats the deployment history (a line per deployment)
+func ListMessage(v []datastore.Deployment) string {
+	if len(v) == 0 {
+		return "no deployments"
+	}
+	msg := ""
+	for _, d := range v {
+		msg += fmt.Sprintf("`%s` %s *%s* by <@%s> _%s_ ( %s )\n",
+			d.ID, d.CreatedAt.UTC().Format("2006-01-02 15:04"), d.Command, d.User, deploymentStatus(d), strings.Join(deploymentArtifacts(d), ", "))
+	}
+	return msg
+}
+
+// Message formats the deployment request with the result of each namespace plan
+func Message(d *datastore.Deployment) string {
+	if d == nil {
+		return ""
+	}
+	msg := fmt.Sprintf("*%s* by <@%s> in <#%s> at %s UTC\n", d.Command, d.User, d.Channel, d.CreatedAt.UTC().Format("2006-01-02 15:04"))
+	msg += fmt.Sprintf("```Deployment: %s\nType: %s\nEnvironment: %s\nNamespaces: %s\nArtifacts: %s\nStatus: %s```",
+		d.ID, d.Plan.Type, d.Plan.Environment, strings.Join(d.Plan.NamespaceAliases, ", "), strings.Join(deploymentArtifacts(*d), ", "), deploymentStatus(*d))
+	for _, id := range deploymentPlanIDs(*d) {
+		r, ok := d.Results[id]
+		if !ok {
+			status := d.Plans[id]
+			if len(status) == 0 {
+				status = eve.DeploymentPlanStatusPending
+			}
+			msg += fmt.Sprintf("\n`%s` _%s_ (no result)", id, status)
+			continue
+		}
+		msg += "\n" + ResultMessage(r)
+	}
+	return msg
+}
+
+// ResultMessage formats the result of a namespace deployment plan
+func ResultMessage(r datastore.DeploymentResult) string {
+	msg := fmt.Sprintf("*%s* ( _%s_ ) %s\n", r.Namespace, r.Environment, r.Status)
+	var lines []string
+	for _, a := range append(r.Services, r.Jobs...) {
+		version := a.AvailableVersion
+		if len(a.DeployedVersion) > 0 && a.DeployedVersion != a.AvailableVersion {
+			version = a.DeployedVersion + " -> " + a.AvailableVersion
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s (%s)", a.Name, version, a.Result))
+	}
+	lines = append(lines, r.Messages...)
+	if len(lines) > 0 {
+		msg += "```" + strings.Join(lines, "\n") + "```"
+	}
+	return msg
+}
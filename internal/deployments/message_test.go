@@ -0,0 +1,52 @@
+package deployments
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestMessage(t *testing.T) {
+	d := &datastore.Deployment{
+		ID:      "d1",
+		Command: "deploy",
+		User:    "U1",
+		Channel: "C1",
+		Plan: eve.DeploymentPlanOptions{
+			Environment:      "int",
+			NamespaceAliases: eve.StringList{"current"},
+			Artifacts:        eve.ArtifactDefinitions{{Name: "api", RequestedVersion: "1.2"}},
+			Type:             eve.DeploymentPlanTypeApplication,
+		},
+		PlanIDs: []string{"p1", "p2"},
+		Plans:   map[string]eve.DeploymentPlanStatus{"p1": eve.DeploymentPlanStatusErrors},
+		Results: map[string]datastore.DeploymentResult{"p1": {
+			Namespace:   "current",
+			Environment: "una-int",
+			Status:      eve.DeploymentPlanStatusErrors,
+			Messages:    []string{"api failed to start"},
+			Services:    []datastore.DeployedArtifact{{Name: "api", DeployedVersion: "1.1", AvailableVersion: "1.2", Result: eve.DeployArtifactResultFailed}},
+		}},
+		Status:    eve.DeploymentPlanStatusErrors,
+		CreatedAt: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	detail := Message(d)
+	for _, want := range []string{"*deploy* by <@U1> in <#C1> at 2021-06-01 12:00 UTC", "Status: pending", "Artifacts: api:1.2",
+		"*current* ( _una-int_ ) errors", "api: 1.1 -> 1.2 (failed)\napi failed to start", "`p2` _pending_ (no result)"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("Message() = %q, want %q", detail, want)
+		}
+	}
+
+	list := ListMessage([]datastore.Deployment{*d})
+	if want := "`d1` 2021-06-01 12:00 *deploy* by <@U1> _pending_ ( api:1.2 )\n"; list != want {
+		t.Errorf("ListMessage() = %q, want %q", list, want)
+	}
+	if got := ListMessage([]datastore.Deployment{}); got != "no deployments" {
+		t.Errorf("ListMessage() = %q, want no deployments", got)
+	}
+}
@@ -0,0 +1,96 @@
+package eveapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the eve-api circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets the calls through (the eve-api is available)
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails the calls without calling the eve-api until a health probe succeeds
+	BreakerOpen BreakerState = "open"
+)
+
+// UnavailableError is returned (without calling the eve-api) while the circuit breaker is open
+type UnavailableError struct {
+	Since time.Time
+}
+
+func (e UnavailableError) Error() string {
+	return fmt.Sprintf("eve-api unavailable since %s", e.Since.Format("15:04"))
+}
+
+// Breaker is the eve-api circuit breaker: it opens after threshold consecutive transient failures
+// and closes on the next success (i.e. a health probe)
+type Breaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	since     time.Time
+	threshold int
+	onChange  func(state BreakerState, since time.Time)
+	now       func() time.Time
+}
+
+// NewBreaker creates a closed Breaker, the breaker never opens when the threshold is 0
+func NewBreaker(threshold int, onChange func(state BreakerState, since time.Time)) *Breaker {
+	return &Breaker{state: BreakerClosed, threshold: threshold, onChange: onChange, now: time.Now}
+}
+
+// Allow returns an UnavailableError while the breaker is open
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen {
+		return UnavailableError{Since: b.since}
+	}
+	return nil
+}
+
+// Success closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	b.failures = 0
+	if b.state == BreakerClosed {
+		b.mu.Unlock()
+		return
+	}
+	b.state = BreakerClosed
+	since := b.since
+	b.mu.Unlock()
+	b.notify(BreakerClosed, since)
+}
+
+// Failure records a transient failure and returns true when it opened the breaker
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	b.failures++
+	if b.state == BreakerOpen || b.threshold <= 0 || b.failures < b.threshold {
+		b.mu.Unlock()
+		return false
+	}
+	b.state = BreakerOpen
+	b.since = b.now()
+	since := b.since
+	b.mu.Unlock()
+	b.notify(BreakerOpen, since)
+	return true
+}
+
+// UnavailableSince returns the time the breaker opened (false when it is closed)
+func (b *Breaker) UnavailableSince() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.since, b.state == BreakerOpen
+}
+
+func (b *Breaker) notify(state BreakerState, since time.Time) {
+	if b.onChange != nil {
+		b.onChange(state, since)
+	}
+}
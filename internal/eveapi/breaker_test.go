@@ -0,0 +1,46 @@
+package eveapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	var states []BreakerState
+	b := NewBreaker(2, func(state BreakerState, since time.Time) { states = append(states, state) })
+	opened := time.Date(2021, 3, 4, 15, 4, 0, 0, time.UTC)
+	b.now = func() time.Time { return opened }
+
+	if b.Failure() {
+		t.Fatalf("Failure() opened the breaker before the threshold")
+	}
+	b.Success()
+	if b.Failure() || !b.Failure() {
+		t.Fatalf("Failure() didn't open the breaker after 2 consecutive failures")
+	}
+	err := b.Allow()
+	if e, ok := err.(UnavailableError); !ok || e.Error() != "eve-api unavailable since 15:04" {
+		t.Fatalf("Allow() = %v, want the UnavailableError", err)
+	}
+	if since, open := b.UnavailableSince(); !open || !since.Equal(opened) {
+		t.Errorf("UnavailableSince() = %v %v, want %v true", since, open, opened)
+	}
+
+	b.Success()
+	if err = b.Allow(); err != nil {
+		t.Errorf("Allow() = %v after a success, want nil", err)
+	}
+	if want := []BreakerState{BreakerOpen, BreakerClosed}; len(states) != 2 || states[0] != want[0] || states[1] != want[1] {
+		t.Errorf("state changes = %v, want %v", states, want)
+	}
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := NewBreaker(0, nil)
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() = %v, want a disabled breaker", err)
+	}
+}
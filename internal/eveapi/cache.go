@@ -0,0 +1,236 @@
+package eveapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve-bot/internal/tracing"
+	"github.com/unanet/eve/pkg/eve"
+	"golang.org/x/sync/singleflight"
+)
+
+var statCacheRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "eveapi_cache_requests_total",
+		Help: "The total number of cached eve-api lookups by resource and result (hit/miss)",
+	}, []string{"resource", "result"})
+
+// cacheLoadTimeout bounds a (shared) load of the cache, it doesn't depend on the caller that started it
+const cacheLoadTimeout = time.Minute
+
+// CacheTTLs are the cache TTLs of the eve-api resources (0 disables the cache of the resource)
+type CacheTTLs struct {
+	Environments time.Duration
+	Namespaces   time.Duration
+	Services     time.Duration
+}
+
+// CachedClient is a read-through cache of the environments, namespaces and services in front of an EveAPI
+// the concurrent identical lookups are collapsed into a single eve-api call
+type CachedClient struct {
+	interfaces.EveAPI
+	ttls    CacheTTLs
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// generations are bumped by Invalidate, the loads started before don't cache their (outdated) value
+	generations map[string]uint64
+	group       singleflight.Group
+	now         func() time.Time
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// cacheLoad is the result of a shared load (with the stale reads of the eve-api calls)
+type cacheLoad struct {
+	value interface{}
+	stale *StaleReads
+}
+
+// NewCachedClient creates a CachedClient that decorates the EveAPI
+func NewCachedClient(api interfaces.EveAPI, ttls CacheTTLs) *CachedClient {
+	return &CachedClient{EveAPI: api, ttls: ttls, entries: make(map[string]cacheEntry), generations: make(map[string]uint64), now: time.Now}
+}
+
+func (c *CachedClient) ttl(resource string) time.Duration {
+	switch resource {
+	case resources.EnvironmentName:
+		return c.ttls.Environments
+	case resources.NamespaceName:
+		return c.ttls.Namespaces
+	case resources.ServiceName:
+		return c.ttls.Services
+	}
+	return 0
+}
+
+// get returns the cached value of the key or loads (and caches) it, the keys are prefixed by the resource
+// the load is shared by the concurrent callers: it runs with a detached context (the span of the ctx, the cacheLoadTimeout)
+// so that a cancelled caller doesn't fail the others, the stale values (the last known good responses) aren't cached
+func (c *CachedClient) get(ctx context.Context, resource, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ttl := c.ttl(resource)
+	if ttl <= 0 {
+		return load(ctx)
+	}
+	key = resource + ":" + key
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	generation := c.generations[resource]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		statCacheRequests.WithLabelValues(resource, "hit").Inc()
+		return e.value, nil
+	}
+	statCacheRequests.WithLabelValues(resource, "miss").Inc()
+
+	// the callers after an Invalidate don't share the load started before
+	v, err, _ := c.group.Do(fmt.Sprintf("%s@%d", key, generation), func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(WithStaleReads(tracing.Detach(ctx)), cacheLoadTimeout)
+		defer cancel()
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		stale := StaleReadsFrom(loadCtx)
+		if _, isStale := stale.Banner(); isStale {
+			return cacheLoad{value: v, stale: stale}, nil
+		}
+		c.mu.Lock()
+		if c.generations[resource] == generation {
+			c.entries[key] = cacheEntry{value: v, expires: c.now().Add(ttl)}
+		}
+		c.mu.Unlock()
+		return cacheLoad{value: v}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	loaded := v.(cacheLoad)
+	StaleReadsFrom(ctx).merge(loaded.stale)
+	return loaded.value, nil
+}
+
+// Invalidate drops the cached values of the resources (environments, namespaces, services)
+// and the values of the loads in progress
+func (c *CachedClient) Invalidate(resourceNames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range resourceNames {
+		c.generations[r]++
+	}
+	for key := range c.entries {
+		for _, r := range resourceNames {
+			if strings.HasPrefix(key, r+":") {
+				delete(c.entries, key)
+			}
+		}
+	}
+}
+
+// UnavailableSince forwards the eve-api availability (see interfaces.EveAPIStatus)
+func (c *CachedClient) UnavailableSince() (time.Time, bool) {
+	if status, ok := c.EveAPI.(interfaces.EveAPIStatus); ok {
+		return status.UnavailableSince()
+	}
+	return time.Time{}, false
+}
+
+// GetEnvironments returns all of the environments (cached)
+func (c *CachedClient) GetEnvironments(ctx context.Context) ([]eve.Environment, error) {
+	v, err := c.get(ctx, resources.EnvironmentName, "", func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetEnvironments(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]eve.Environment(nil), v.([]eve.Environment)...), nil
+}
+
+// GetEnvironmentByID returns an environment by ID (cached)
+func (c *CachedClient) GetEnvironmentByID(ctx context.Context, id string) (*eve.Environment, error) {
+	v, err := c.get(ctx, resources.EnvironmentName, id, func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetEnvironmentByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	env := *v.(*eve.Environment)
+	return &env, nil
+}
+
+// GetNamespacesByEnvironment returns all of the namespaces for an environment (cached)
+func (c *CachedClient) GetNamespacesByEnvironment(ctx context.Context, environmentName string) ([]eve.Namespace, error) {
+	v, err := c.get(ctx, resources.NamespaceName, "environment="+environmentName, func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetNamespacesByEnvironment(ctx, environmentName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]eve.Namespace(nil), v.([]eve.Namespace)...), nil
+}
+
+// GetNamespaceByID returns the namespace by an ID (cached)
+func (c *CachedClient) GetNamespaceByID(ctx context.Context, id int) (eve.Namespace, error) {
+	v, err := c.get(ctx, resources.NamespaceName, fmt.Sprint(id), func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetNamespaceByID(ctx, id)
+	})
+	if err != nil {
+		return eve.Namespace{}, err
+	}
+	return v.(eve.Namespace), nil
+}
+
+// GetServicesByNamespace returns all of the services for a given namespace (cached)
+func (c *CachedClient) GetServicesByNamespace(ctx context.Context, namespace string) ([]eve.Service, error) {
+	v, err := c.get(ctx, resources.ServiceName, "namespace="+namespace, func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetServicesByNamespace(ctx, namespace)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]eve.Service(nil), v.([]eve.Service)...), nil
+}
+
+// GetServiceByName returns a service by name and namespace name (cached)
+func (c *CachedClient) GetServiceByName(ctx context.Context, namespace, service string) (eve.Service, error) {
+	v, err := c.get(ctx, resources.ServiceName, namespace+"/"+service, func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetServiceByName(ctx, namespace, service)
+	})
+	if err != nil {
+		return eve.Service{}, err
+	}
+	return v.(eve.Service), nil
+}
+
+// GetServiceByID returns a service by an ID (cached)
+func (c *CachedClient) GetServiceByID(ctx context.Context, id int) (eve.Service, error) {
+	v, err := c.get(ctx, resources.ServiceName, fmt.Sprint(id), func(ctx context.Context) (interface{}, error) {
+		return c.EveAPI.GetServiceByID(ctx, id)
+	})
+	if err != nil {
+		return eve.Service{}, err
+	}
+	return v.(eve.Service), nil
+}
+
+// SetServiceVersion sets the version on the service and invalidates the cached services
+func (c *CachedClient) SetServiceVersion(ctx context.Context, version string, id int) (eve.Service, error) {
+	defer c.Invalidate(resources.ServiceName)
+	return c.EveAPI.SetServiceVersion(ctx, version, id)
+}
+
+// SetNamespaceVersion sets the version on the namespace and invalidates the cached namespaces
+func (c *CachedClient) SetNamespaceVersion(ctx context.Context, version string, id int) (eve.Namespace, error) {
+	defer c.Invalidate(resources.NamespaceName)
+	return c.EveAPI.SetNamespaceVersion(ctx, version, id)
+}
@@ -0,0 +1,165 @@
+package eveapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/unanet/eve-bot/internal/botcommander/resources"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestCachedClient_GetNamespacesByEnvironment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	api.EXPECT().GetNamespacesByEnvironment(gomock.Any(), "int").Return([]eve.Namespace{{ID: 1, Alias: "current"}}, nil).Times(2)
+
+	c := NewCachedClient(api, CacheTTLs{Namespaces: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ns, err := c.GetNamespacesByEnvironment(context.Background(), "int")
+		if err != nil || len(ns) != 1 || ns[0].Alias != "current" {
+			t.Fatalf("GetNamespacesByEnvironment() = %v, %v", ns, err)
+		}
+	}
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	if _, err := c.GetNamespacesByEnvironment(context.Background(), "int"); err != nil {
+		t.Fatalf("GetNamespacesByEnvironment() error = %v", err)
+	}
+}
+
+func TestCachedClient_Invalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	api.EXPECT().GetServiceByID(gomock.Any(), 1).Return(eve.Service{ID: 1, OverrideVersion: "1.0"}, nil)
+	api.EXPECT().SetServiceVersion(gomock.Any(), "2.0", 1).Return(eve.Service{ID: 1, OverrideVersion: "2.0"}, nil)
+	api.EXPECT().GetServiceByID(gomock.Any(), 1).Return(eve.Service{ID: 1, OverrideVersion: "2.0"}, nil)
+	api.EXPECT().GetEnvironments(gomock.Any()).Return([]eve.Environment{{ID: 1, Name: "int"}}, nil).Times(1)
+
+	c := NewCachedClient(api, CacheTTLs{Environments: time.Minute, Services: time.Minute})
+	ctx := context.Background()
+	_, _ = c.GetEnvironments(ctx)
+	_, _ = c.GetServiceByID(ctx, 1)
+	if _, err := c.SetServiceVersion(ctx, "2.0", 1); err != nil {
+		t.Fatalf("SetServiceVersion() error = %v", err)
+	}
+	if svc, _ := c.GetServiceByID(ctx, 1); svc.OverrideVersion != "2.0" {
+		t.Errorf("GetServiceByID() = %v after SetServiceVersion, want the new version", svc)
+	}
+	// the environments aren't invalidated by the services changes
+	_, _ = c.GetEnvironments(ctx)
+
+	c.Invalidate(resources.EnvironmentName)
+	if len(c.entries) != 1 {
+		t.Errorf("Invalidate() left %d entries, want only the service", len(c.entries))
+	}
+}
+
+func TestCachedClient_Singleflight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	release := make(chan struct{})
+	api.EXPECT().GetServicesByNamespace(gomock.Any(), "current-int").DoAndReturn(func(ctx context.Context, ns string) ([]eve.Service, error) {
+		<-release
+		return []eve.Service{{ID: 1, Name: "api"}}, nil
+	}).Times(1)
+
+	c := NewCachedClient(api, CacheTTLs{Services: time.Minute})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if svcs, err := c.GetServicesByNamespace(context.Background(), "current-int"); err != nil || len(svcs) != 1 {
+				t.Errorf("GetServicesByNamespace() = %v, %v", svcs, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func TestCachedClient_StaleNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	cachedAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	// the first load is served from the last known good responses (the eve-api is unavailable)
+	api.EXPECT().GetEnvironments(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]eve.Environment, error) {
+		StaleReadsFrom(ctx).add(cachedAt, cachedAt)
+		return []eve.Environment{{ID: 1, Name: "int"}}, nil
+	})
+	api.EXPECT().GetEnvironments(gomock.Any()).Return([]eve.Environment{{ID: 1, Name: "int"}}, nil)
+
+	c := NewCachedClient(api, CacheTTLs{Environments: time.Minute})
+	ctx := WithStaleReads(context.Background())
+	if _, err := c.GetEnvironments(ctx); err != nil {
+		t.Fatalf("GetEnvironments() error = %v", err)
+	}
+	if _, stale := StaleReadsFrom(ctx).Banner(); !stale {
+		t.Errorf("GetEnvironments() didn't record the stale read of the load")
+	}
+	_, _ = c.GetEnvironments(context.Background())
+	_, _ = c.GetEnvironments(context.Background())
+}
+
+func TestCachedClient_InvalidateDuringLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	loading, release := make(chan struct{}), make(chan struct{})
+	api.EXPECT().GetServiceByID(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, id int) (eve.Service, error) {
+		close(loading)
+		<-release
+		return eve.Service{ID: 1, OverrideVersion: "1.0"}, nil
+	})
+	api.EXPECT().GetServiceByID(gomock.Any(), 1).Return(eve.Service{ID: 1, OverrideVersion: "2.0"}, nil)
+
+	c := NewCachedClient(api, CacheTTLs{Services: time.Minute})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = c.GetServiceByID(context.Background(), 1)
+	}()
+	<-loading
+	c.Invalidate(resources.ServiceName)
+	close(release)
+	<-done
+
+	if svc, _ := c.GetServiceByID(context.Background(), 1); svc.OverrideVersion != "2.0" {
+		t.Errorf("GetServiceByID() = %v, want the value loaded after the Invalidate", svc)
+	}
+}
+
+func TestCachedClient_CancelledCaller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockClient(ctrl)
+	loading, release := make(chan struct{}), make(chan struct{})
+	api.EXPECT().GetNamespaceByID(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, id int) (eve.Namespace, error) {
+		close(loading)
+		<-release
+		return eve.Namespace{ID: 1}, ctx.Err()
+	})
+
+	c := NewCachedClient(api, CacheTTLs{Namespaces: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _, _ = c.GetNamespaceByID(ctx, 1) }()
+	<-loading
+	cancel()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.GetNamespaceByID(context.Background(), 1)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("GetNamespaceByID() error = %v, want the load of the cancelled caller to succeed", err)
+	}
+}
@@ -0,0 +1,65 @@
+package eveapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the query params of the signed callback URL
+const (
+	// CallbackIDParam is the deployment (correlation) id of the callback
+	CallbackIDParam        = "id"
+	callbackExpiresParam   = "expires"
+	callbackSignatureParam = "sig"
+)
+
+// the callback verification errors
+var (
+	ErrCallbackUnsigned  = errors.New("the callback isn't signed")
+	ErrCallbackExpired   = errors.New("the callback signature expired")
+	ErrCallbackSignature = errors.New("the callback signature is invalid")
+)
+
+// callbackSignedParams are the callback params covered by the signature (in order),
+// the trace context params (see tracing.InjectQuery) are signed so that the callback can't be attached to another trace
+var callbackSignedParams = []string{CallbackIDParam, callbackExpiresParam, "traceparent", "tracestate"}
+
+// callbackSignature is the hex HMAC-SHA256 of the signed params (newline separated)
+func callbackSignature(query url.Values, secret string) string {
+	values := make([]string, 0, len(callbackSignedParams))
+	for _, p := range callbackSignedParams {
+		values = append(values, query.Get(p))
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strings.Join(values, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignCallback adds the expiry and the signature of the deployment id to the callback query params
+func SignCallback(query url.Values, secret string, expires time.Time) {
+	query.Set(callbackExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(callbackSignatureParam, callbackSignature(query, secret))
+}
+
+// VerifyCallback checks the signature and the expiry of the callback query params (see SignCallback)
+func VerifyCallback(query url.Values, secret string, now time.Time) error {
+	sig, err := hex.DecodeString(query.Get(callbackSignatureParam))
+	if err != nil || len(sig) == 0 || len(secret) == 0 {
+		return ErrCallbackUnsigned
+	}
+	want, _ := hex.DecodeString(callbackSignature(query, secret))
+	if !hmac.Equal(sig, want) {
+		return ErrCallbackSignature
+	}
+	expires, err := strconv.ParseInt(query.Get(callbackExpiresParam), 10, 64)
+	if err != nil || now.After(time.Unix(expires, 0)) {
+		return ErrCallbackExpired
+	}
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 
 const allCaughtUpMsg = "We're all caught up! There is nothing to deploy..."
 
+// releaseNotesMaxLines is the number of release notes lines of the callback message (the full notes follow in the thread)
+const releaseNotesMaxLines = 15
+
 // CallbackState data structure
 type CallbackState struct {
 	User    string               `json:"user"`
 	Channel string               `json:"channel"`
 	TS      string               `json:"ts"`
 	Payload eve.NSDeploymentPlan `json:"payload"`
+	// ReleaseNotes are the changes of the upgraded services (see changelog.Message)
+	ReleaseNotes string `json:"release_notes,omitempty"`
 }
 
 // ToChatMsg converts the eve-api callback payload to a Chat Message (string with formatting/proper messaging)
@@ -37,6 +42,8 @@ func (cbs *CallbackState) ToChatMsg() string {
 
 	cbs.appendDeployJobsResult(&result)
 
+	cbs.appendReleaseNotes(&result)
+
 	return cbs.appendAPIMessages(&result)
 }
 
@@ -62,12 +69,15 @@ func headerMsg(val string) string {
 }
 
 func (cbs *CallbackState) cleanUser() {
-	switch cbs.User {
-	case "":
+	switch {
+	case cbs.User == "":
 		return
-	case "channel":
+	case cbs.User == "channel":
 		cbs.User = "!channel"
 		return
+	case strings.HasPrefix(cbs.User, "!"):
+		// the special mentions (i.e. !here, !subteam^ID) are already escaped
+		return
 	default:
 		cbs.User = "@" + cbs.User
 		return
@@ -180,3 +190,24 @@ func (cbs *CallbackState) appendAPIMessages(result *string) string {
 
 	return *result + headerMsg("Messages") + "\n```" + messages(cbs.Payload.Messages) + "```"
 }
+
+// appendReleaseNotes appends the release notes of the upgraded services (the first releaseNotesMaxLines lines)
+func (cbs *CallbackState) appendReleaseNotes(result *string) {
+	if len(cbs.ReleaseNotes) == 0 {
+		return
+	}
+	notes := cbs.ReleaseNotes
+	if lines := strings.Split(strings.TrimPrefix(notes, "\n"), "\n"); len(lines) > releaseNotesMaxLines {
+		notes = "\n" + strings.Join(lines[:releaseNotesMaxLines], "\n") +
+			fmt.Sprintf("\n>_%d more lines, the full release notes follow in the thread_", len(lines)-releaseNotesMaxLines)
+	}
+	*result = *result + "\n" + headerMsg("Release Notes") + notes
+}
+
+// FullReleaseNotesMsg is the message of the full release notes when the callback message truncates them (false when it doesn't)
+func (cbs *CallbackState) FullReleaseNotesMsg() (string, bool) {
+	if strings.Count(strings.TrimPrefix(cbs.ReleaseNotes, "\n"), "\n") < releaseNotesMaxLines {
+		return "", false
+	}
+	return headerMsg("Release Notes") + cbs.ReleaseNotes, true
+}
@@ -0,0 +1,38 @@
+package eveapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestCallbackState_ReleaseNotes(t *testing.T) {
+	var notes string
+	for i := 0; i < releaseNotesMaxLines+5; i++ {
+		notes += fmt.Sprintf("\n>`abc%d` change %d", i, i)
+	}
+	plan := eve.NSDeploymentPlan{
+		Status: eve.DeploymentPlanStatusComplete,
+		Services: eve.DeployServices{{
+			DeployArtifact: &eve.DeployArtifact{ArtifactName: "api", DeployedVersion: "1.0", AvailableVersion: "1.1", Result: eve.DeployArtifactResultSuccess},
+			ServiceName:    "api",
+		}},
+	}
+	cbs := CallbackState{Payload: plan, ReleaseNotes: notes}
+
+	msg := cbs.ToChatMsg()
+	if !strings.Contains(msg, "change 14") || strings.Contains(msg, "change 15") || !strings.Contains(msg, "5 more lines") {
+		t.Errorf("ToChatMsg() = %q, want the release notes truncated", msg)
+	}
+	full, truncated := cbs.FullReleaseNotesMsg()
+	if !truncated || !strings.Contains(full, "change 19") {
+		t.Errorf("FullReleaseNotesMsg() = %q, %v, want the full release notes", full, truncated)
+	}
+
+	cbs.ReleaseNotes = "\n>*api* 1.0 → 1.1\n>`abc` change"
+	if _, truncated := cbs.FullReleaseNotesMsg(); truncated {
+		t.Errorf("FullReleaseNotesMsg() truncated the short release notes")
+	}
+}
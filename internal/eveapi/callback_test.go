@@ -0,0 +1,52 @@
+package eveapi
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestVerifyCallback(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	signed := func() url.Values {
+		q := url.Values{CallbackIDParam: {"d1"}}
+		SignCallback(q, "secret", now.Add(time.Hour))
+		return q
+	}
+
+	tests := []struct {
+		name   string
+		query  func() url.Values
+		secret string
+		now    time.Time
+		want   error
+	}{
+		{name: "signed", query: signed, secret: "secret", now: now},
+		{name: "unsigned", query: func() url.Values { return url.Values{CallbackIDParam: {"d1"}} }, secret: "secret", now: now, want: ErrCallbackUnsigned},
+		{name: "another secret", query: signed, secret: "another", now: now, want: ErrCallbackSignature},
+		{name: "expired", query: signed, secret: "secret", now: now.Add(2 * time.Hour), want: ErrCallbackExpired},
+		{name: "id changed", query: func() url.Values {
+			q := signed()
+			q.Set(CallbackIDParam, "d2")
+			return q
+		}, secret: "secret", now: now, want: ErrCallbackSignature},
+		{name: "expiry extended", query: func() url.Values {
+			q := signed()
+			q.Set(callbackExpiresParam, "99999999999")
+			return q
+		}, secret: "secret", now: now.Add(2 * time.Hour), want: ErrCallbackSignature},
+		{name: "trace attached", query: func() url.Values {
+			q := signed()
+			q.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			return q
+		}, secret: "secret", now: now, want: ErrCallbackSignature},
+		{name: "no secret", query: signed, secret: "", now: now, want: ErrCallbackUnsigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyCallback(tt.query(), tt.secret, tt.now); err != tt.want {
+				t.Errorf("VerifyCallback() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -7,8 +7,18 @@ import (
 	"github.com/unanet/eve/pkg/eve"
 )
 
+// UnmaskedChatMessage is the ChatMessage without the secret metadata values masked
+// it must only be sent in a private message
+func UnmaskedChatMessage(model interface{}) string {
+	if v, ok := model.(eve.Metadata); ok {
+		return metadataMsg(v)
+	}
+	return ChatMessage(model)
+}
+
 // ChatMessage takes in an eve API Model (as an interface)
 // and returns a formatted string for a Chat Message response/result
+// the metadata is formatted by the Masker.ChatMessage (the secret values masked) or the UnmaskedChatMessage
 func ChatMessage(model interface{}) string {
 	if model == nil {
 		return ""
@@ -29,8 +39,6 @@ func ChatMessage(model interface{}) string {
 		return nsDeployPlanMsg(v)
 	case eve.Release:
 		return releaseMsg(v)
-	case eve.Metadata:
-		return metadataMsg(v)
 	case []eve.Service:
 		return servicesMsg(v)
 	case []eve.Namespace:
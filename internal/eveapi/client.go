@@ -13,8 +13,8 @@ import (
 
 	"github.com/dghubble/sling"
 	"github.com/unanet/eve-bot/internal/botcommander/params"
+	"github.com/unanet/eve-bot/internal/tracing"
 	"github.com/unanet/eve/pkg/eve"
-	eveerror "github.com/unanet/go/pkg/errors"
 	evehttp "github.com/unanet/go/pkg/http"
 	evejson "github.com/unanet/go/pkg/json"
 	"github.com/unanet/go/pkg/log"
@@ -26,21 +26,73 @@ import (
 // EVEBOT_EVEAPI_TIMEOUT
 // EVEBOT_EVEAPI_CALLBACK_URL
 // EVEBOT_EVEAPI_ADMIN_TOKEN
+// EVEBOT_EVEAPI_CALLBACK_SECRET
+// EVEBOT_EVEAPI_CALLBACK_TTL
+// EVEBOT_EVEAPI_CRON_CALLBACK_TOKEN
+// EVEBOT_EVEAPI_RETRY_ATTEMPTS
+// EVEBOT_EVEAPI_RETRY_BACKOFF
+// EVEBOT_EVEAPI_RETRY_MAX_BACKOFF
+// EVEBOT_EVEAPI_BREAKER_THRESHOLD
+// EVEBOT_EVEAPI_BREAKER_PROBE_INTERVAL
+// EVEBOT_EVEAPI_CACHE_ENVIRONMENTS_TTL
+// EVEBOT_EVEAPI_CACHE_NAMESPACES_TTL
+// EVEBOT_EVEAPI_CACHE_SERVICES_TTL
+// EVEBOT_METADATA_MASK_PATTERNS
+// EVEBOT_METADATA_MASK_ENTROPY
+// EVEBOT_METADATA_MASK_MIN_LENGTH
 type Config struct {
 	EveapiBaseURL     string        `split_words:"true" required:"true"`
 	EveapiTimeout     time.Duration `split_words:"true" default:"20s"`
 	EveapiCallbackURL string        `split_words:"true" required:"true"`
 	EveapiAdminToken  string        `split_words:"true" required:"true"`
+	// EveapiCallbackSecret signs the deployment callback URLs (the unsigned callbacks are rejected)
+	EveapiCallbackSecret string        `split_words:"true" required:"true"`
+	EveapiCallbackTTL    time.Duration `split_words:"true" default:"24h"`
+	// EveapiCronCallbackToken is the bearer token of the cron callbacks (the Authorization header of the eve-api cron jobs)
+	EveapiCronCallbackToken string `split_words:"true" required:"true"`
+	// EveapiRetryAttempts is the max number of attempts of the idempotent calls (GET/PUT/DELETE) on the transient failures
+	EveapiRetryAttempts   int           `split_words:"true" default:"3"`
+	EveapiRetryBackoff    time.Duration `split_words:"true" default:"200ms"`
+	EveapiRetryMaxBackoff time.Duration `split_words:"true" default:"2s"`
+	// EveapiBreakerThreshold is the number of consecutive failed calls that open the circuit breaker (0 disables it)
+	EveapiBreakerThreshold int `split_words:"true" default:"3"`
+	// EveapiBreakerProbeInterval is the interval of the health probes while the circuit breaker is open
+	EveapiBreakerProbeInterval time.Duration `split_words:"true" default:"15s"`
+	// the TTLs of the cached environments, namespaces and services (0 disables the cache, see CachedClient)
+	EveapiCacheEnvironmentsTTL time.Duration `split_words:"true" default:"10m"`
+	EveapiCacheNamespacesTTL   time.Duration `split_words:"true" default:"2m"`
+	EveapiCacheServicesTTL     time.Duration `split_words:"true" default:"1m"`
+	// MetadataMaskPatterns are the key patterns of the secret metadata values (masked in the chat messages)
+	MetadataMaskPatterns []string `split_words:"true" default:"*password*,*passwd*,*secret*,*token*,*apikey*,*api_key*,*private_key*,*credential*"`
+	// MetadataMaskEntropy is the normalised entropy of the random (secret) values (about 1 for a random value, 0 disables it)
+	MetadataMaskEntropy   float64 `split_words:"true" default:"0.9"`
+	MetadataMaskMinLength int     `split_words:"true" default:"20"`
 }
 
+// defaultProbeInterval is the health probe interval when EveapiBreakerProbeInterval isn't set
+const defaultProbeInterval = 15 * time.Second
+
 // Client data structure
 type Client struct {
-	cfg   *Config
-	sling *sling.Sling
+	cfg           *Config
+	sling         *sling.Sling
+	breaker       *Breaker
+	lastKnownGood *lastKnownGood
+	onStateChange func(state BreakerState, since time.Time)
+}
+
+// Option is a functional option of the Client
+type Option func(*Client)
+
+// StateChangeOpt sets the func called when the circuit breaker opens or closes
+func StateChangeOpt(fn func(state BreakerState, since time.Time)) Option {
+	return func(c *Client) {
+		c.onStateChange = fn
+	}
 }
 
 // New creates a new eve api Client
-func New(cfg Config) interfaces.EveAPI {
+func New(cfg Config, opts ...Option) interfaces.EveAPI {
 	var httpClient = &http.Client{
 		Timeout:   cfg.EveapiTimeout,
 		Transport: evehttp.LoggingTransport,
@@ -50,7 +102,7 @@ func New(cfg Config) interfaces.EveAPI {
 		cfg.EveapiBaseURL += "/"
 	}
 
-	return &Client{
+	c := &Client{
 		cfg: &cfg,
 		sling: sling.New().
 			Base(cfg.EveapiBaseURL).
@@ -58,177 +110,123 @@ func New(cfg Config) interfaces.EveAPI {
 			Add("User-Agent", "eve-bot").
 			Add("Authorization", fmt.Sprintf("Bearer %s", cfg.EveapiAdminToken)).
 			ResponseDecoder(evejson.NewJsonDecoder()),
+		lastKnownGood: newLastKnownGood(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.breaker = NewBreaker(cfg.EveapiBreakerThreshold, c.breakerStateChange)
+	return c
 }
 
-func (c *Client) GetNamespaceJobs(ctx context.Context, ns *eve.Namespace) ([]eve.Job, error) {
-	var success []eve.Job
-	var failure eveerror.RestError
+// UnavailableSince returns the time the eve-api became unavailable (false when it is available)
+func (c *Client) UnavailableSince() (time.Time, bool) {
+	return c.breaker.UnavailableSince()
+}
 
-	r, err := c.sling.New().Get(fmt.Sprintf("namespaces/%v/jobs", ns.ID)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetNamespaceJobs request", zap.Error(err))
-		return success, err
+func (c *Client) breakerStateChange(state BreakerState, since time.Time) {
+	log.Logger.Warn("eve-api circuit breaker state change", zap.String("state", string(state)), zap.Time("since", since))
+	if state == BreakerOpen {
+		go c.probe()
 	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
+	if c.onStateChange != nil {
+		c.onStateChange(state, since)
 	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
+}
+
+// probe calls the eve-api ping until it succeeds (i.e. closes the circuit breaker)
+func (c *Client) probe() {
+	interval := c.cfg.EveapiBreakerProbeInterval
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, open := c.breaker.UnavailableSince(); !open {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.cfg.EveapiTimeout)
+		_, err := c.send(ctx, http.MethodGet, "ping", nil, nil, nil)
+		cancel()
+		if err == nil {
+			c.breaker.Success()
+			return
+		}
+		log.Logger.Debug("eve-api health probe failed", zap.Error(err))
 	}
 }
 
+// GetNamespaceJobs returns the jobs of the namespace
+func (c *Client) GetNamespaceJobs(ctx context.Context, ns *eve.Namespace) ([]eve.Job, error) {
+	var success []eve.Job
+	err := c.Get(ctx, fmt.Sprintf("namespaces/%v/jobs", ns.ID), nil, &success)
+	return success, err
+}
+
 // DeleteMetadataKey calls the API to delete the metadata KEY (leaves empty {} is no metadata)
 func (c *Client) DeleteMetadataKey(ctx context.Context, id int, key string) (eve.Metadata, error) {
 	var success eve.Metadata
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Delete(fmt.Sprintf("metadata/%s/%s", strconv.Itoa(id), key)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api DeleteMetadataKey request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err := c.Delete(ctx, fmt.Sprintf("metadata/%s/%s", strconv.Itoa(id), key), &success)
+	return success, err
 }
 
 // UpsertMetadataServiceMap calls the API to upsert (insert/update) the metadata service map record
 func (c *Client) UpsertMetadataServiceMap(ctx context.Context, payload eve.MetadataServiceMap) (eve.MetadataServiceMap, error) {
 	var success eve.MetadataServiceMap
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Put(fmt.Sprintf("metadata/%s/service-maps", strconv.Itoa(payload.MetadataID))).BodyJSON(payload).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api UpsertMetadataServiceMap request", zap.Error(err))
-		return success, err
-	}
+	err := c.Put(ctx, fmt.Sprintf("metadata/%s/service-maps", strconv.Itoa(payload.MetadataID)), payload, &success)
+	return success, err
+}
 
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
+// GetMetadataServiceMaps calls the API to retrieve the service maps of the metadata record
+func (c *Client) GetMetadataServiceMaps(ctx context.Context, metadataID int) ([]eve.MetadataServiceMap, error) {
+	var success []eve.MetadataServiceMap
+	err := c.Get(ctx, fmt.Sprintf("metadata/%s/service-maps", strconv.Itoa(metadataID)), nil, &success)
+	return success, err
+}
 
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+// GetServiceMetadataMaps calls the API to retrieve every metadata map that applies to the service
+// (service, namespace, environment... maps) ordered by the stacking order
+func (c *Client) GetServiceMetadataMaps(ctx context.Context, serviceID int) ([]eve.MetadataServiceMap, error) {
+	var success []eve.MetadataServiceMap
+	err := c.Get(ctx, fmt.Sprintf("services/%s/metadata-maps", strconv.Itoa(serviceID)), nil, &success)
+	return success, err
 }
 
 // UpsertMergeMetadata calls the API to upsert (insert/update) the metadata record
 func (c *Client) UpsertMergeMetadata(ctx context.Context, payload eve.Metadata) (eve.Metadata, error) {
 	var success eve.Metadata
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Patch(fmt.Sprintf("metadata")).BodyJSON(payload).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api UpsertMergeMetadata request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err := c.Patch(ctx, "metadata", payload, &success)
+	return success, err
 }
 
 // GetMetadata calls the API to retrieve metadata by key
 func (c *Client) GetMetadata(ctx context.Context, key string) (eve.Metadata, error) {
 	var success eve.Metadata
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Get(fmt.Sprintf("metadata/%s", key)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetMetadata request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return success, failure
-	}
+	err := c.Get(ctx, fmt.Sprintf("metadata/%s", key), nil, &success)
+	return success, err
 }
 
 // Release method calls the API to move artifacts in feeds
 func (c *Client) Release(ctx context.Context, payload eve.Release) ([]eve.Release, error) {
 	var success []eve.Release
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Post(fmt.Sprintf("release")).BodyJSON(payload).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api Release request", zap.Error(err))
+	if err := c.Post(ctx, "release", payload, &success); err != nil {
+		log.Logger.Warn("failed to release artifact", zap.Error(err))
 		return success, err
 	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		log.Logger.Warn("failed to release artifact", zap.Error(err), zap.Any("failure", failure))
-		return success, fmt.Errorf(failure.Message)
-	}
+	return success, nil
 }
 
 // GetServiceByName returns a service by name and namespace name
 func (c *Client) GetServiceByName(ctx context.Context, namespace, service string) (eve.Service, error) {
 	var success eve.Service
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Get(fmt.Sprintf("namespaces/%s/services/%s", namespace, service)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetServiceByName request", zap.Error(err))
-		return success, err
-	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err := c.Get(ctx, fmt.Sprintf("namespaces/%s/services/%s", namespace, service), nil, &success)
+	return success, err
 }
 
 // SetNamespaceVersion sets the version on the namespace
 func (c *Client) SetNamespaceVersion(ctx context.Context, version string, id int) (eve.Namespace, error) {
 	var success eve.Namespace
-	var failure eveerror.RestError
 
 	fullNS, err := c.GetNamespaceByID(ctx, id)
 	if err != nil {
@@ -238,29 +236,13 @@ func (c *Client) SetNamespaceVersion(ctx context.Context, version string, id int
 	// Update the Version
 	fullNS.RequestedVersion = version
 
-	r, err := c.sling.New().Post(fmt.Sprintf("namespaces/%v", fullNS.ID)).BodyJSON(fullNS).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api SetNamespaceVersion request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err = c.Post(ctx, fmt.Sprintf("namespaces/%v", fullNS.ID), fullNS, &success)
+	return success, err
 }
 
 // SetServiceVersion sets the version on the service
 func (c *Client) SetServiceVersion(ctx context.Context, version string, id int) (eve.Service, error) {
 	var success eve.Service
-	var failure eveerror.RestError
 
 	fullSvc, err := c.GetServiceByID(ctx, id)
 	if err != nil {
@@ -270,29 +252,13 @@ func (c *Client) SetServiceVersion(ctx context.Context, version string, id int)
 	// Update the Version
 	fullSvc.OverrideVersion = version
 
-	r, err := c.sling.New().Post(fmt.Sprintf("services/%v", fullSvc.ID)).BodyJSON(fullSvc).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api SetServiceVersion request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err = c.Post(ctx, fmt.Sprintf("services/%v", fullSvc.ID), fullSvc, &success)
+	return success, err
 }
 
 // DeleteServiceMetadata deletes a metadata key on a service
 func (c *Client) DeleteServiceMetadata(ctx context.Context, m string, id int) (params.MetadataMap, error) {
 	var success params.MetadataMap
-	var failure eveerror.RestError
 
 	// Guard against the user sending key=value
 	// we only want to send the key to the API
@@ -305,190 +271,77 @@ func (c *Client) DeleteServiceMetadata(ctx context.Context, m string, id int) (p
 		return nil, fmt.Errorf("invalid metadata key: %s", metadatakey)
 	}
 
-	r, err := c.sling.New().Delete(fmt.Sprintf("services/%v/metadata/%s", id, metadatakey)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api DeleteServiceMetadata request", zap.Error(err))
-		return nil, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api DeleteServiceMetadata", zap.Error(err))
+	if err := c.Delete(ctx, fmt.Sprintf("services/%v/metadata/%s", id, metadatakey), &success); err != nil {
 		return nil, err
 	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
-	}
+	return success, nil
 }
 
 // GetServiceByID returns a service by an ID
 func (c *Client) GetServiceByID(ctx context.Context, id int) (eve.Service, error) {
 	var success eve.Service
-	var failure eveerror.RestError
-	r, err := c.sling.New().Get(fmt.Sprintf("services/%v", id)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetServiceByID request", zap.Error(err))
-		return success, eveerror.Wrap(err)
-	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetServiceByID", zap.Error(err))
-		return success, eveerror.Wrap(err)
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err := c.Get(ctx, fmt.Sprintf("services/%v", id), nil, &success)
+	return success, err
 }
 
 // GetServicesByNamespace returns all of the services for a given namespace
 func (c *Client) GetServicesByNamespace(ctx context.Context, namespace string) ([]eve.Service, error) {
 	var success []eve.Service
-	var failure eveerror.RestError
-	r, err := c.sling.New().Get(fmt.Sprintf("namespaces/%s/services", namespace)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetServicesByNamespace request", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetServicesByNamespace", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
+	if err := c.Get(ctx, fmt.Sprintf("namespaces/%s/services", namespace), nil, &success); err != nil {
+		return nil, err
 	}
+	return success, nil
 }
 
 // GetEnvironmentByID returns an environment by ID
 func (c *Client) GetEnvironmentByID(ctx context.Context, id string) (*eve.Environment, error) {
 	var success eve.Environment
-	var failure eveerror.RestError
-	r, err := c.sling.New().Get(fmt.Sprintf("environments/%s", id)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetEnvironment request", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetEnvironment", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return &success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
+	if err := c.Get(ctx, fmt.Sprintf("environments/%s", id), nil, &success); err != nil {
+		return nil, err
 	}
+	return &success, nil
 }
 
 // GetEnvironments returns all of the environments
 func (c *Client) GetEnvironments(ctx context.Context) ([]eve.Environment, error) {
 	var success []eve.Environment
-	var failure eveerror.RestError
-	r, err := c.sling.New().Get("environments").Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetEnvironments request", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetEnvironments", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
+	if err := c.Get(ctx, "environments", nil, &success); err != nil {
+		return nil, err
 	}
+	return success, nil
 }
 
 // GetNamespacesByEnvironment returns all of the namespaces for an environment
 func (c *Client) GetNamespacesByEnvironment(ctx context.Context, environmentName string) ([]eve.Namespace, error) {
 	var success []eve.Namespace
-	var failure eveerror.RestError
-	r, err := c.sling.New().Get("namespaces").Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetNamespacesByEnvironment request", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	r.URL.RawQuery = fmt.Sprintf("environment=%s", environmentName)
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetNamespacesByEnvironment", zap.Error(err))
-		return nil, eveerror.Wrap(err)
-	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
+	if err := c.Get(ctx, "namespaces", url.Values{"environment": []string{environmentName}}, &success); err != nil {
+		return nil, err
 	}
+	return success, nil
 }
 
 // Deploy calls the eve api to deploy resources
-func (c *Client) Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, user, channel, ts string) (*eve.DeploymentPlanOptions, error) {
+// the callback URL only carries the deployment (correlation) id, the callbacks look up the deployment record
+func (c *Client) Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, deploymentID string) (*eve.DeploymentPlanOptions, error) {
 	var success eve.DeploymentPlanOptions
-	var failure eveerror.RestError
 
 	cbURLVals := url.Values{}
-	cbURLVals.Set("user", user)
-	cbURLVals.Add("channel", channel)
-	cbURLVals.Add("ts", ts)
+	cbURLVals.Set(CallbackIDParam, deploymentID)
+	// the callback continues the trace of the deploy command
+	tracing.InjectQuery(ctx, cbURLVals)
+	SignCallback(cbURLVals, c.cfg.EveapiCallbackSecret, time.Now().Add(c.cfg.EveapiCallbackTTL))
 
 	dp.CallbackURL = c.cfg.EveapiCallbackURL + "?" + cbURLVals.Encode()
 
-	r, err := c.sling.New().Post("deployment-plans").BodyJSON(dp).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api Deploy request", zap.Error(err))
-		return nil, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api Deploy", zap.Error(err))
+	if err := c.Post(ctx, "deployment-plans", dp, &success); err != nil {
 		return nil, err
 	}
-
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusPartialContent:
-		return &success, nil
-	default:
-		return nil, fmt.Errorf(failure.Message)
-	}
+	return &success, nil
 }
 
 // GetNamespaceByID returns the namespace by an ID
 func (c *Client) GetNamespaceByID(ctx context.Context, id int) (eve.Namespace, error) {
 	var success eve.Namespace
-	var failure eveerror.RestError
-
-	r, err := c.sling.New().Get(fmt.Sprintf("namespaces/%v", id)).Request()
-	if err != nil {
-		log.Logger.Error("error preparing eve-api GetNamespaceByID request", zap.Error(err))
-		return success, err
-	}
-
-	resp, err := c.sling.Do(r.WithContext(ctx), &success, &failure)
-	if err != nil {
-		log.Logger.Error("error calling eve-api GetNamespaceByID", zap.Error(err))
-		return success, err
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return success, nil
-	default:
-		return success, fmt.Errorf(failure.Message)
-	}
+	err := c.Get(ctx, fmt.Sprintf("namespaces/%v", id), nil, &success)
+	return success, err
 }
@@ -37,18 +37,18 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // Deploy mocks base method
-func (m *MockClient) Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, slackUser, slackChannel, ts string) (*eve.DeploymentPlanOptions, error) {
+func (m *MockClient) Deploy(ctx context.Context, dp eve.DeploymentPlanOptions, deploymentID string) (*eve.DeploymentPlanOptions, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Deploy", ctx, dp, slackUser, slackChannel, ts)
+	ret := m.ctrl.Call(m, "Deploy", ctx, dp, deploymentID)
 	ret0, _ := ret[0].(*eve.DeploymentPlanOptions)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Deploy indicates an expected call of Deploy
-func (mr *MockClientMockRecorder) Deploy(ctx, dp, slackUser, slackChannel, ts interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) Deploy(ctx, dp, deploymentID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deploy", reflect.TypeOf((*MockClient)(nil).Deploy), ctx, dp, slackUser, slackChannel, ts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deploy", reflect.TypeOf((*MockClient)(nil).Deploy), ctx, dp, deploymentID)
 }
 
 // GetEnvironmentByID mocks base method
@@ -202,10 +202,10 @@ func (mr *MockClientMockRecorder) GetNamespaceByID(ctx, id interface{}) *gomock.
 }
 
 // Release mocks base method
-func (m *MockClient) Release(ctx context.Context, payload eve.Release) (eve.Release, error) {
+func (m *MockClient) Release(ctx context.Context, payload eve.Release) ([]eve.Release, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Release", ctx, payload)
-	ret0, _ := ret[0].(eve.Release)
+	ret0, _ := ret[0].([]eve.Release)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -231,6 +231,36 @@ func (mr *MockClientMockRecorder) GetMetadata(ctx, key interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadata", reflect.TypeOf((*MockClient)(nil).GetMetadata), ctx, key)
 }
 
+// GetMetadataServiceMaps mocks base method
+func (m *MockClient) GetMetadataServiceMaps(ctx context.Context, metadataID int) ([]eve.MetadataServiceMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetadataServiceMaps", ctx, metadataID)
+	ret0, _ := ret[0].([]eve.MetadataServiceMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetadataServiceMaps indicates an expected call of GetMetadataServiceMaps
+func (mr *MockClientMockRecorder) GetMetadataServiceMaps(ctx, metadataID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadataServiceMaps", reflect.TypeOf((*MockClient)(nil).GetMetadataServiceMaps), ctx, metadataID)
+}
+
+// GetServiceMetadataMaps mocks base method
+func (m *MockClient) GetServiceMetadataMaps(ctx context.Context, serviceID int) ([]eve.MetadataServiceMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceMetadataMaps", ctx, serviceID)
+	ret0, _ := ret[0].([]eve.MetadataServiceMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceMetadataMaps indicates an expected call of GetServiceMetadataMaps
+func (mr *MockClientMockRecorder) GetServiceMetadataMaps(ctx, serviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceMetadataMaps", reflect.TypeOf((*MockClient)(nil).GetServiceMetadataMaps), ctx, serviceID)
+}
+
 // UpsertMergeMetadata mocks base method
 func (m *MockClient) UpsertMergeMetadata(arg0 context.Context, arg1 eve.Metadata) (eve.Metadata, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,133 @@
+package eveapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+func TestMasker_Mask(t *testing.T) {
+	m := NewMasker(DefaultMaskPatterns, DefaultMaskEntropy, DefaultMaskMinLength)
+	tests := []struct {
+		name  string
+		field eve.MetadataField
+		want  eve.MetadataField
+	}{
+		{
+			name:  "key patterns",
+			field: eve.MetadataField{"DB_PASSWORD": "hunter2", "github_token": "abc", "ClientSecret": float64(1), "log_level": "debug"},
+			want:  eve.MetadataField{"DB_PASSWORD": MaskedValue, "github_token": MaskedValue, "ClientSecret": MaskedValue, "log_level": "debug"},
+		},
+		{
+			name:  "entropy",
+			field: eve.MetadataField{"key": "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", "url": "https://billing-api.int.unanet.io/v1/billing", "msg": "the quick brown fox jumps over the lazy dog"},
+			want:  eve.MetadataField{"key": MaskedValue, "url": "https://billing-api.int.unanet.io/v1/billing", "msg": "the quick brown fox jumps over the lazy dog"},
+		},
+		{
+			name:  "url credentials",
+			field: eve.MetadataField{"db": "postgres://eve:s3cr3t@db:5432/eve"},
+			want:  eve.MetadataField{"db": MaskedValue},
+		},
+		{
+			name:  "nested",
+			field: eve.MetadataField{"db": map[string]interface{}{"host": "db", "password": "hunter2"}, "hosts": []interface{}{"a", "postgres://eve:s3cr3t@db"}},
+			want:  eve.MetadataField{"db": map[string]interface{}{"host": "db", "password": MaskedValue}, "hosts": []interface{}{"a", MaskedValue}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Mask(tt.field); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMasker_isSecretValue(t *testing.T) {
+	m := NewMasker(nil, DefaultMaskEntropy, DefaultMaskMinLength)
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "hex", value: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b", want: true},
+		{name: "hex min length", value: "3a7bd3e2360a3d29eea4", want: true},
+		{name: "base64", value: "c2VjcmV0LWtleS0xMjM0NTY3ODkwYWJjZGVm", want: true},
+		{name: "base64 padded", value: "XC2F9vNLsDlALVJUSNr+dg==", want: true},
+		{name: "random min length", value: "aB3xK9mQ2pL7vR4tY8nW", want: true},
+		{name: "random with repeats", value: "k7Jx2k7Jx2Qm9Pz4Qm9P", want: false},
+		{name: "pod name", value: "una-int-current-billing-api-7f9c8d6b5-x2k4p", want: false},
+		{name: "too short", value: "9f86d081884c7d659a2", want: false},
+		{name: "uuid", value: "123e4567-e89b-12d3-a456-426614174000", want: false},
+		{name: "words", value: "billing-api-service-production", want: false},
+		{name: "lowercase", value: "abcdefghijklmnopqrstuvwxyz", want: false},
+		{name: "repeated", value: "aaaaaaaaaaaaaaaaaaaaaaaa", want: false},
+		{name: "path", value: "/var/lib/eve-bot/mirrors/billing-api.git", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.isSecretValue(tt.value); got != tt.want {
+				t.Errorf("isSecretValue(%q) = %v (entropy %.2f), want %v", tt.value, got, normalisedEntropy(tt.value), tt.want)
+			}
+		})
+	}
+}
+
+func TestMasker_Disabled(t *testing.T) {
+	m := NewMasker(nil, 0, 0)
+	field := eve.MetadataField{"password": "hunter2", "key": "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"}
+	if got := m.Mask(field); !reflect.DeepEqual(got, field) {
+		t.Errorf("Mask() = %v, want %v", got, field)
+	}
+}
+
+func TestIsMasked(t *testing.T) {
+	if !IsMasked(map[string]interface{}{"a": []interface{}{MaskedValue}}) {
+		t.Errorf("IsMasked() = false, want true")
+	}
+	if IsMasked("value") {
+		t.Errorf("IsMasked() = true, want false")
+	}
+}
+
+func TestChatMessage_Metadata(t *testing.T) {
+	m := NewMasker(DefaultMaskPatterns, DefaultMaskEntropy, DefaultMaskMinLength)
+	md := eve.Metadata{ID: 1, Value: eve.MetadataField{"api_token": "abc", "url": "https://api"}}
+	if got := m.ChatMessage(md); strings.Contains(got, "abc") || !strings.Contains(got, MaskedValue) {
+		t.Errorf("ChatMessage() = %v, want the token masked", got)
+	}
+	if got := ChatMessage(md); len(got) > 0 {
+		t.Errorf("ChatMessage() = %v, want the metadata only formatted by a Masker", got)
+	}
+	if got := UnmaskedChatMessage(md); !strings.Contains(got, "abc") {
+		t.Errorf("UnmaskedChatMessage() = %v, want the token", got)
+	}
+	if md.Value["api_token"] != "abc" {
+		t.Errorf("ChatMessage() changed the metadata value")
+	}
+}
+
+func TestMasker_MaskText(t *testing.T) {
+	m := NewMasker(DefaultMaskPatterns, DefaultMaskEntropy, DefaultMaskMinLength)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "2021-06-01T12:00:00Z INFO request completed status=200 path=/api/v1/users", want: "2021-06-01T12:00:00Z INFO request completed status=200 path=/api/v1/users"},
+		{name: "key value", text: "connecting with password=hunter2 user=api", want: "connecting with password=******** user=api"},
+		{name: "json", text: `{"level":"info","api_token":"abc123","msg":"ok"}`, want: `{"level":"info","api_token":"********","msg":"ok"}`},
+		{name: "yaml", text: "client_secret: abc123", want: "client_secret: ********"},
+		{name: "url credentials", text: "dial postgres://api:hunter2@db:5432/api failed", want: "dial postgres://api:********@db:5432/api failed"},
+		{name: "random", text: "using key Zx8Qp2Lr7Wv4Kt9Yb3Nm6Hc1 for signing", want: "using key ******** for signing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.MaskText(tt.text); got != tt.want {
+				t.Errorf("MaskText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package eveapi
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/sling"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unanet/eve-bot/internal/tracing"
+	eveerror "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+var statRequestDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name: "eveapi_request_duration_seconds",
+		Help: "The duration of the eve-api requests by method, endpoint and status code (error when there was no response)",
+	}, []string{"method", "endpoint", "status"})
+
+// Get calls the eve-api and decodes the response into the result (a pointer)
+// the failures are returned as an eveerror.RestError (with the status code)
+func (c *Client) Get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, result)
+}
+
+// Post calls the eve-api with the json body and decodes the response into the result (a pointer)
+func (c *Client) Post(ctx context.Context, path string, body, result interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, result)
+}
+
+// Put calls the eve-api with the json body and decodes the response into the result (a pointer)
+func (c *Client) Put(ctx context.Context, path string, body, result interface{}) error {
+	return c.do(ctx, http.MethodPut, path, nil, body, result)
+}
+
+// Patch calls the eve-api with the json body and decodes the response into the result (a pointer)
+func (c *Client) Patch(ctx context.Context, path string, body, result interface{}) error {
+	return c.do(ctx, http.MethodPatch, path, nil, body, result)
+}
+
+// Delete calls the eve-api and decodes the response into the result (a pointer)
+func (c *Client) Delete(ctx context.Context, path string, result interface{}) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil, result)
+}
+
+// idempotent are the methods retried on the transient failures (the other calls could be applied twice)
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus are the status codes of the transient eve-api failures
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// withRequestID adds a request id to the context (when it doesn't have one already)
+// the retries of a call (and the calls of a command) share the same X-Request-Id
+func withRequestID(ctx context.Context) context.Context {
+	if len(log.GetReqID(ctx)) > 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, log.RequestIDKey, log.GetNextRequestID())
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) (err error) {
+	ctx = withRequestID(ctx)
+	ctx, span := tracing.Start(ctx, "eveapi "+method, attribute.String("http.method", method), attribute.String("eveapi.path", path))
+	defer func() { tracing.End(span, err) }()
+
+	// the eve-api is unavailable: fail fast (the reads are served from the last known good responses)
+	if err := c.breaker.Allow(); err != nil {
+		return c.stale(ctx, err, method, path, query, result)
+	}
+
+	err = c.retry(ctx, method, path, query, body, result)
+	switch e := err.(type) {
+	case nil:
+		c.breaker.Success()
+		if method == http.MethodGet && result != nil {
+			c.lastKnownGood.store(path, query, result)
+		}
+		return nil
+	case transientError:
+		c.breaker.Failure()
+		if _, open := c.breaker.UnavailableSince(); open {
+			return c.stale(ctx, e.error, method, path, query, result)
+		}
+		return e.error
+	case localError:
+		// the request didn't reach the eve-api (i.e. an invalid body or a canceled context), nothing is known of its availability
+		return e.error
+	default:
+		// the eve-api replied (i.e. a 404), it is available
+		c.breaker.Success()
+		return err
+	}
+}
+
+// transientError is a failure of the eve-api itself (a network error or a 5xx/429 status)
+type transientError struct {
+	error
+}
+
+// localError is a failure before (or without) a round trip to the eve-api
+type localError struct {
+	error
+}
+
+// stale decodes the last known good response into the result (returns the err when there isn't one)
+// and records the stale read in the context StaleReads
+func (c *Client) stale(ctx context.Context, err error, method, path string, query url.Values, result interface{}) error {
+	if method != http.MethodGet || result == nil {
+		return err
+	}
+	cachedAt, ok := c.lastKnownGood.load(path, query, result)
+	if !ok {
+		return err
+	}
+	since, _ := c.breaker.UnavailableSince()
+	StaleReadsFrom(ctx).add(since, cachedAt)
+	log.Logger.Warn("serving a stale eve-api response", zap.String("path", path), zap.Time("cached_at", cachedAt))
+	return nil
+}
+
+// retry sends the request (and retries the idempotent calls), the transient failures are a transientError
+func (c *Client) retry(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	attempts := 1
+	if idempotent(method) && c.cfg.EveapiRetryAttempts > 1 {
+		attempts = c.cfg.EveapiRetryAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := c.send(ctx, method, path, query, body, result)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= attempts {
+			log.Logger.Error("eve-api request failed", zap.Error(err), zap.String("method", method), zap.String("path", path),
+				zap.String("req_id", log.GetReqID(ctx)), zap.Int("attempts", attempt))
+			if retry {
+				return transientError{err}
+			}
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		log.Logger.Warn("retrying eve-api request", zap.Error(err), zap.String("method", method), zap.String("path", path),
+			zap.String("req_id", log.GetReqID(ctx)), zap.Int("attempt", attempt), zap.Duration("wait", wait))
+		select {
+		case <-ctx.Done():
+			return transientError{err}
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send sends a single request and returns true when the failure is transient
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body, result interface{}) (bool, error) {
+	s, err := c.request(method, path)
+	if err != nil {
+		return false, localError{err}
+	}
+	if body != nil {
+		s = s.BodyJSON(body)
+	}
+	r, err := s.Request()
+	if err != nil {
+		return false, localError{eveerror.Wrap(err)}
+	}
+	if len(query) > 0 {
+		r.URL.RawQuery = query.Encode()
+	}
+	// the eve-api spans are children of the command span (traceparent header)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	var failure eveerror.RestError
+	start := time.Now()
+	resp, err := c.sling.Do(r.WithContext(ctx), result, &failure)
+	status := "error"
+	if resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	statRequestDuration.WithLabelValues(method, endpoint(path), status).Observe(time.Since(start).Seconds())
+	if resp != nil && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		// the failure isn't decoded when the body isn't an eve-api error (i.e. a proxy error page)
+		if failure.Code == 0 {
+			failure.Code = resp.StatusCode
+		}
+		if len(failure.Message) == 0 {
+			failure.Message = fmt.Sprintf("eve-api %s %s: %s", method, path, http.StatusText(resp.StatusCode))
+		}
+		failure.OriginalError = err
+		return retryableStatus(resp.StatusCode), failure
+	}
+	if err != nil && resp == nil {
+		// the network errors (and the timeouts) are transient unless the context is done
+		if ctx.Err() != nil {
+			return false, localError{eveerror.Wrap(err)}
+		}
+		return true, eveerror.Wrap(err)
+	}
+	if err != nil {
+		// the eve-api replied but the body isn't the expected result (a retry gets the same body)
+		return false, eveerror.Wrap(fmt.Errorf("eve-api %s %s: invalid response: %w", method, path, err))
+	}
+	return false, nil
+}
+
+// endpoint is the path with the identifiers replaced (the bounded metric label)
+// the eve-api paths alternate resources and identifiers (i.e. namespaces/{id}/services/{id})
+func endpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i += 2 {
+		segments[i] = "{id}"
+	}
+	return strings.Join(segments, "/")
+}
+
+func (c *Client) request(method, path string) (*sling.Sling, error) {
+	s := c.sling.New()
+	switch method {
+	case http.MethodGet:
+		return s.Get(path), nil
+	case http.MethodPost:
+		return s.Post(path), nil
+	case http.MethodPut:
+		return s.Put(path), nil
+	case http.MethodPatch:
+		return s.Patch(path), nil
+	case http.MethodDelete:
+		return s.Delete(path), nil
+	}
+	return nil, fmt.Errorf("unsupported eve-api method: %s", method)
+}
+
+// backoff is the exponential backoff of the attempt with jitter (between half and the full backoff)
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.EveapiRetryBackoff << uint(attempt-1)
+	if d <= 0 || (c.cfg.EveapiRetryMaxBackoff > 0 && d > c.cfg.EveapiRetryMaxBackoff) {
+		d = c.cfg.EveapiRetryMaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,247 @@
+package eveapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/tracing"
+	"github.com/unanet/eve/pkg/eve"
+	eveerror "github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// testServer replies with the statuses in order (the last one is repeated) and records the request ids
+type testServer struct {
+	sync.Mutex
+	statuses   []int
+	body       string
+	requests   int
+	requestIDs []string
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	status := s.statuses[len(s.statuses)-1]
+	if s.requests < len(s.statuses) {
+		status = s.statuses[s.requests]
+	}
+	s.requests++
+	s.requestIDs = append(s.requestIDs, r.Header.Get(log.RequestIDHeader))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		_, _ = w.Write([]byte(s.body))
+		return
+	}
+	if status == http.StatusNotFound {
+		_, _ = w.Write([]byte(`{"code":404,"message":"metadata not found"}`))
+	}
+}
+
+func newTestClient(t *testing.T, s *testServer) *Client {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return New(Config{
+		EveapiBaseURL:         srv.URL,
+		EveapiTimeout:         time.Second,
+		EveapiRetryAttempts:   3,
+		EveapiRetryBackoff:    time.Millisecond,
+		EveapiRetryMaxBackoff: 5 * time.Millisecond,
+	}).(*Client)
+}
+
+func TestClient_GetRetries(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}, body: `{"id":1,"description":"eve-bot:api:current"}`}
+	c := newTestClient(t, s)
+
+	md, err := c.GetMetadata(context.Background(), "eve-bot:api:current")
+	if err != nil {
+		t.Fatalf("GetMetadata() error = %v", err)
+	}
+	if md.ID != 1 || s.requests != 3 {
+		t.Errorf("GetMetadata() = %v after %d requests, want the metadata after 3 requests", md, s.requests)
+	}
+	if len(s.requestIDs[0]) == 0 || s.requestIDs[0] != s.requestIDs[1] || s.requestIDs[1] != s.requestIDs[2] {
+		t.Errorf("the retries don't share the request id: %v", s.requestIDs)
+	}
+}
+
+func TestClient_GetRetriesExhausted(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusServiceUnavailable}}
+	c := newTestClient(t, s)
+
+	_, err := c.GetEnvironments(context.Background())
+	restErr, ok := err.(eveerror.RestError)
+	if !ok || restErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GetEnvironments() error = %#v, want a 503 RestError", err)
+	}
+	if s.requests != 3 {
+		t.Errorf("GetEnvironments() sent %d requests, want 3", s.requests)
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusNotFound}}
+	c := newTestClient(t, s)
+
+	_, err := c.GetMetadata(context.WithValue(context.Background(), log.RequestIDKey, "req-1"), "missing")
+	restErr, ok := err.(eveerror.RestError)
+	if !ok || restErr.Code != http.StatusNotFound || restErr.Message != "metadata not found" {
+		t.Fatalf("GetMetadata() error = %#v, want the 404 RestError", err)
+	}
+	if s.requests != 1 || s.requestIDs[0] != "req-1" {
+		t.Errorf("GetMetadata() sent %d requests with the ids %v, want a single request with the context id", s.requests, s.requestIDs)
+	}
+}
+
+func TestClient_PostIsNotRetried(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, body: `[]`}
+	c := newTestClient(t, s)
+
+	_, err := c.Release(context.Background(), eve.Release{})
+	if restErr, ok := err.(eveerror.RestError); !ok || restErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Release() error = %#v, want the 503 RestError", err)
+	}
+	if s.requests != 1 {
+		t.Errorf("Release() sent %d requests, want 1", s.requests)
+	}
+}
+
+func TestClient_InvalidResponseIsNotRetried(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusOK}, body: `{"id":`}
+	c := newTestClient(t, s)
+	c.breaker = NewBreaker(1, nil)
+
+	if _, err := c.GetEnvironments(context.Background()); err == nil {
+		t.Fatalf("GetEnvironments() error = nil, want the decode error")
+	}
+	if s.requests != 1 {
+		t.Errorf("GetEnvironments() sent %d requests, want 1", s.requests)
+	}
+	if _, open := c.breaker.UnavailableSince(); open {
+		t.Errorf("the invalid response opened the breaker")
+	}
+}
+
+func TestClient_LocalFailureIsNotRecorded(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusServiceUnavailable}}
+	c := newTestClient(t, s)
+	c.cfg.EveapiRetryAttempts = 1
+	c.cfg.EveapiBreakerProbeInterval = time.Hour
+	c.breaker = NewBreaker(2, nil)
+
+	_, _ = c.GetEnvironments(context.Background())
+	// the body can't be marshaled, the request isn't sent (and doesn't reset the failures)
+	if err := c.Post(context.Background(), "releases", make(chan int), nil); err == nil {
+		t.Fatalf("Post() error = nil, want the marshal error")
+	}
+	_, _ = c.GetEnvironments(context.Background())
+	if _, open := c.breaker.UnavailableSince(); !open || s.requests != 2 {
+		t.Errorf("the breaker isn't open after %d failed requests, want the local failure to be ignored", s.requests)
+	}
+}
+
+func TestClient_backoff(t *testing.T) {
+	c := &Client{cfg: &Config{EveapiRetryBackoff: 100 * time.Millisecond, EveapiRetryMaxBackoff: 300 * time.Millisecond}}
+	for attempt, limit := range []time.Duration{100, 200, 300, 300} {
+		limit *= time.Millisecond
+		if got := c.backoff(attempt + 1); got < limit/2 || got > limit {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempt+1, got, limit/2, limit)
+		}
+	}
+}
+
+func TestClient_BreakerServesLastKnownGood(t *testing.T) {
+	s := &testServer{statuses: []int{http.StatusOK, http.StatusServiceUnavailable}, body: `[{"id":1,"name":"int"}]`}
+	c := newTestClient(t, s)
+	c.cfg.EveapiRetryAttempts = 1
+	c.cfg.EveapiBreakerProbeInterval = time.Hour
+	c.breaker = NewBreaker(1, nil)
+
+	if _, err := c.GetEnvironments(context.Background()); err != nil {
+		t.Fatalf("GetEnvironments() error = %v", err)
+	}
+
+	// the failure opens the breaker, the environments are served from the last known good response
+	ctx := WithStaleReads(context.Background())
+	envs, err := c.GetEnvironments(ctx)
+	if err != nil || len(envs) != 1 || envs[0].Name != "int" {
+		t.Fatalf("GetEnvironments() = %v, %v, want the last known good environments", envs, err)
+	}
+	if _, stale := StaleReadsFrom(ctx).Banner(); !stale {
+		t.Errorf("GetEnvironments() didn't record the stale read")
+	}
+
+	// the calls fail fast while the breaker is open
+	requests := s.requests
+	if _, err = c.GetNamespaceByID(context.Background(), 1); err == nil {
+		t.Fatalf("GetNamespaceByID() error = nil, want the UnavailableError")
+	}
+	if _, err = c.Release(context.Background(), eve.Release{}); err == nil {
+		t.Fatalf("Release() error = nil, want the UnavailableError")
+	}
+	if _, ok := err.(UnavailableError); !ok || s.requests != requests {
+		t.Errorf("Release() error = %#v after %d requests, want the UnavailableError without a request", err, s.requests-requests)
+	}
+}
+
+func TestClient_DeployCallbackURL(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tp := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	var plan eve.DeploymentPlanOptions
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&plan)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := New(Config{EveapiBaseURL: srv.URL, EveapiTimeout: time.Second, EveapiCallbackURL: "http://eve-bot/eve-callback", EveapiCallbackSecret: "secret", EveapiCallbackTTL: time.Hour})
+
+	ctx, span := tracing.Start(context.Background(), "deploy")
+	defer span.End()
+	if _, err := c.Deploy(ctx, eve.DeploymentPlanOptions{}, "d1"); err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	u, err := url.Parse(plan.CallbackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent := u.Query().Get("traceparent"); !strings.Contains(parent, span.SpanContext().TraceID().String()) {
+		t.Errorf("CallbackURL = %s, want the traceparent of the deploy trace", plan.CallbackURL)
+	}
+	if u.Query().Get(CallbackIDParam) != "d1" || len(u.Query().Get("user")) > 0 {
+		t.Errorf("CallbackURL = %s, want only the deployment id", plan.CallbackURL)
+	}
+	if err := VerifyCallback(u.Query(), "secret", time.Now()); err != nil {
+		t.Errorf("CallbackURL = %s, want a signed callback: %v", plan.CallbackURL, err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"environments":                       "environments",
+		"namespaces/current/services/api":    "namespaces/{id}/services/{id}",
+		"metadata/eve-bot:api:current":       "metadata/{id}",
+		"metadata/12/service-maps":           "metadata/{id}/service-maps",
+		"services/7/metadata/eve-bot:api:ns": "services/{id}/metadata/{id}",
+	}
+	for path, want := range tests {
+		if got := endpoint(path); got != want {
+			t.Errorf("endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
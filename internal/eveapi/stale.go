@@ -0,0 +1,123 @@
+package eveapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// lastKnownGoodMaxEntries bounds the last known good responses (the oldest response is dropped)
+const lastKnownGoodMaxEntries = 1000
+
+// lastKnownGood keeps the last successful GET response of every path (served while the eve-api is unavailable)
+type lastKnownGood struct {
+	mu      sync.Mutex
+	entries map[string]lastKnownGoodEntry
+}
+
+type lastKnownGoodEntry struct {
+	body     []byte
+	cachedAt time.Time
+}
+
+func newLastKnownGood() *lastKnownGood {
+	return &lastKnownGood{entries: make(map[string]lastKnownGoodEntry)}
+}
+
+func lastKnownGoodKey(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+func (c *lastKnownGood) store(path string, query url.Values, result interface{}) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := lastKnownGoodKey(path, query)
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= lastKnownGoodMaxEntries {
+		var oldest string
+		for k, e := range c.entries {
+			if len(oldest) == 0 || e.cachedAt.Before(c.entries[oldest].cachedAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = lastKnownGoodEntry{body: body, cachedAt: time.Now()}
+}
+
+// load decodes the last known good response into the result and returns the time it was cached
+func (c *lastKnownGood) load(path string, query url.Values, result interface{}) (time.Time, bool) {
+	c.mu.Lock()
+	e, ok := c.entries[lastKnownGoodKey(path, query)]
+	c.mu.Unlock()
+	if !ok || json.Unmarshal(e.body, result) != nil {
+		return time.Time{}, false
+	}
+	return e.cachedAt, true
+}
+
+type staleReadsKey struct{}
+
+// StaleReads records the responses served from the last known good cache during a command
+type StaleReads struct {
+	mu       sync.Mutex
+	since    time.Time
+	cachedAt time.Time
+}
+
+// WithStaleReads adds a StaleReads to the context (see StaleReadsFrom)
+func WithStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsKey{}, &StaleReads{})
+}
+
+// StaleReadsFrom returns the StaleReads of the context (nil when the context doesn't have one)
+func StaleReadsFrom(ctx context.Context) *StaleReads {
+	s, _ := ctx.Value(staleReadsKey{}).(*StaleReads)
+	return s
+}
+
+func (s *StaleReads) add(since, cachedAt time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = since
+	if s.cachedAt.IsZero() || cachedAt.Before(s.cachedAt) {
+		s.cachedAt = cachedAt
+	}
+}
+
+// merge records the stale reads of another StaleReads (i.e. of a shared load)
+func (s *StaleReads) merge(other *StaleReads) {
+	if s == nil || other == nil {
+		return
+	}
+	other.mu.Lock()
+	since, cachedAt := other.since, other.cachedAt
+	other.mu.Unlock()
+	if !cachedAt.IsZero() {
+		s.add(since, cachedAt)
+	}
+}
+
+// Banner returns the staleness banner (false when every response came from the eve-api)
+func (s *StaleReads) Banner() (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cachedAt.IsZero() {
+		return "", false
+	}
+	return ":warning: " + UnavailableError{Since: s.since}.Error() + ", the results above are from " + s.cachedAt.Format("15:04") + " and may be stale", true
+}
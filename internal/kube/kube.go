@@ -0,0 +1,252 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/unanet/eve/pkg/eve"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ErrUnknownCluster is returned when the cluster of the namespace isn't configured
+var ErrUnknownCluster = errors.New("the cluster of the namespace isn't configured")
+
+// Config is the kubernetes (pods and logs) config
+// EVEBOT_KUBERNETES_CLUSTERS
+// EVEBOT_KUBERNETES_SERVICE_LABEL
+// EVEBOT_KUBERNETES_TIMEOUT
+// EVEBOT_KUBERNETES_LOGS_MAX_TAIL
+// EVEBOT_KUBERNETES_LOGS_MAX_BYTES
+type Config struct {
+	// KubernetesClusters are the clusters of the eve namespaces (a json array)
+	KubernetesClusters Clusters `split_words:"true"`
+	// KubernetesServiceLabel is the pod label with the eve service name
+	KubernetesServiceLabel string        `split_words:"true" default:"app"`
+	KubernetesTimeout      time.Duration `split_words:"true" default:"10s"`
+	// KubernetesLogsMaxTail is the max number of log lines of a pod
+	KubernetesLogsMaxTail int `split_words:"true" default:"1000"`
+	// KubernetesLogsMaxBytes is the max size of the logs of a pod
+	KubernetesLogsMaxBytes int64 `split_words:"true" default:"1048576"`
+}
+
+// Cluster is the API server of an eve cluster and the token of its read-only service account
+// (get/list pods and get pods/log)
+type Cluster struct {
+	// ID is the eve cluster id (eve.Namespace.ClusterID)
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Server is the API server url
+	Server string `json:"server"`
+	// TokenFile is the service account token file (i.e. a mounted secret)
+	TokenFile string `json:"token_file"`
+	// CAFile is the API server certificate authority (empty uses the system roots)
+	CAFile string `json:"ca_file"`
+}
+
+// Clusters are the configured clusters
+type Clusters []Cluster
+
+// Decode satisfies the envconfig.Decoder interface (the clusters are a json array)
+func (c *Clusters) Decode(value string) error {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil
+	}
+	var clusters Clusters
+	if err := json.Unmarshal([]byte(value), &clusters); err != nil {
+		return fmt.Errorf("invalid kubernetes clusters: %w", err)
+	}
+	for i, cluster := range clusters {
+		if cluster.ID == 0 || len(cluster.Server) == 0 || len(cluster.TokenFile) == 0 {
+			return fmt.Errorf("invalid kubernetes cluster %d: the id, server and token_file are required", i)
+		}
+	}
+	*c = clusters
+	return nil
+}
+
+// Pod is the status of a pod of a service
+type Pod struct {
+	Name     string
+	Phase    string
+	Ready    string
+	Restarts int32
+	Age      time.Duration
+	Version  string
+	Node     string
+	// Reason is the waiting/terminated reason of a container (i.e. CrashLoopBackOff)
+	Reason string
+}
+
+// LogOptions are the options of the service logs
+type LogOptions struct {
+	// Tail is the number of the most recent lines of each pod (capped by KubernetesLogsMaxTail)
+	Tail int
+	// Since only returns the lines newer than the duration (0 doesn't limit the age)
+	Since time.Duration
+}
+
+// Client reads the pods and the logs of the eve services (read-only)
+type Client struct {
+	cfg        Config
+	clientsets map[int]kubernetes.Interface
+	now        func() time.Time
+}
+
+// New creates a Client with a clientset per configured cluster
+func New(cfg Config) (*Client, error) {
+	clientsets := make(map[int]kubernetes.Interface, len(cfg.KubernetesClusters))
+	for _, cluster := range cfg.KubernetesClusters {
+		cs, err := kubernetes.NewForConfig(&rest.Config{
+			Host:            cluster.Server,
+			BearerTokenFile: cluster.TokenFile,
+			TLSClientConfig: rest.TLSClientConfig{CAFile: cluster.CAFile},
+			Timeout:         cfg.KubernetesTimeout,
+			UserAgent:       "eve-bot",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubernetes cluster %s: %w", cluster.Name, err)
+		}
+		clientsets[cluster.ID] = cs
+	}
+	return newClient(cfg, clientsets), nil
+}
+
+func newClient(cfg Config, clientsets map[int]kubernetes.Interface) *Client {
+	return &Client{cfg: cfg, clientsets: clientsets, now: time.Now}
+}
+
+// Pods returns the pods of the service in the namespace
+func (c *Client) Pods(ctx context.Context, ns eve.Namespace, service string) ([]Pod, error) {
+	pods, err := c.listPods(ctx, ns, service)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Pod, 0, len(pods))
+	for _, p := range pods {
+		result = append(result, c.pod(p))
+	}
+	return result, nil
+}
+
+// Logs returns the logs of the pods of the service (after a header line per pod), the logs of each pod are truncated
+// after KubernetesLogsMaxBytes and the failures of a pod are written in its logs
+func (c *Client) Logs(ctx context.Context, ns eve.Namespace, service string, opts LogOptions) ([]byte, error) {
+	pods, err := c.listPods(ctx, ns, service)
+	if err != nil {
+		return nil, err
+	}
+	cs := c.clientsets[ns.ClusterID]
+	tail := int64(opts.Tail)
+	if tail <= 0 || (c.cfg.KubernetesLogsMaxTail > 0 && tail > int64(c.cfg.KubernetesLogsMaxTail)) {
+		tail = int64(c.cfg.KubernetesLogsMaxTail)
+	}
+
+	var logs strings.Builder
+	for _, p := range pods {
+		logOpts := &corev1.PodLogOptions{Container: container(p, service)}
+		if tail > 0 {
+			logOpts.TailLines = &tail
+		}
+		if opts.Since > 0 {
+			since := int64(opts.Since.Seconds())
+			logOpts.SinceSeconds = &since
+		}
+		fmt.Fprintf(&logs, "==> %s (%s) <==\n", p.Name, logOpts.Container)
+		stream, err := cs.CoreV1().Pods(ns.Name).GetLogs(p.Name, logOpts).Stream(ctx)
+		if err != nil {
+			fmt.Fprintf(&logs, "failed to get the logs: %s\n\n", err)
+			continue
+		}
+		c.copyLogs(&logs, stream)
+		_ = stream.Close()
+		logs.WriteString("\n")
+	}
+	return []byte(logs.String()), nil
+}
+
+// copyLogs copies the log lines up to KubernetesLogsMaxBytes
+func (c *Client) copyLogs(w *strings.Builder, r io.Reader) {
+	var size int64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		size += int64(len(line)) + 1
+		if c.cfg.KubernetesLogsMaxBytes > 0 && size > c.cfg.KubernetesLogsMaxBytes {
+			w.WriteString("... (truncated)\n")
+			return
+		}
+		w.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(w, "failed to read the logs: %s\n", err)
+	}
+}
+
+// listPods lists the pods with the service label in the cluster of the namespace (by name)
+func (c *Client) listPods(ctx context.Context, ns eve.Namespace, service string) ([]corev1.Pod, error) {
+	cs, ok := c.clientsets[ns.ClusterID]
+	if !ok {
+		return nil, ErrUnknownCluster
+	}
+	list, err := cs.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{c.cfg.KubernetesServiceLabel: service}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods := list.Items
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+func (c *Client) pod(p corev1.Pod) Pod {
+	result := Pod{
+		Name:    p.Name,
+		Phase:   string(p.Status.Phase),
+		Version: p.Labels["version"],
+		Node:    p.Spec.NodeName,
+	}
+	if p.Status.StartTime != nil {
+		result.Age = c.now().Sub(p.Status.StartTime.Time).Round(time.Second)
+	}
+	ready := 0
+	for _, s := range p.Status.ContainerStatuses {
+		if s.Ready {
+			ready++
+		}
+		result.Restarts += s.RestartCount
+		switch {
+		case s.State.Waiting != nil && len(s.State.Waiting.Reason) > 0:
+			result.Reason = s.State.Waiting.Reason
+		case s.State.Terminated != nil && len(s.State.Terminated.Reason) > 0:
+			result.Reason = s.State.Terminated.Reason
+		}
+	}
+	result.Ready = fmt.Sprintf("%d/%d", ready, len(p.Spec.Containers))
+	return result
+}
+
+// container is the container of the service (the first container when none is named after the service)
+func container(p corev1.Pod, service string) string {
+	for _, c := range p.Spec.Containers {
+		if c.Name == service {
+			return c.Name
+		}
+	}
+	if len(p.Spec.Containers) > 0 {
+		return p.Spec.Containers[0].Name
+	}
+	return ""
+}
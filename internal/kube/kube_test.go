@@ -0,0 +1,99 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve/pkg/eve"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var now = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func pod(name, app string, ready bool, restarts int32, waiting string) *corev1.Pod {
+	status := corev1.ContainerStatus{Name: app, Ready: ready, RestartCount: restarts}
+	if len(waiting) > 0 {
+		status.State.Waiting = &corev1.ContainerStateWaiting{Reason: waiting}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "una-int-current", Labels: map[string]string{"app": app, "version": "1.2"}},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "istio-proxy"}, {Name: app}}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			StartTime:         &metav1.Time{Time: now.Add(-90 * time.Minute)},
+			ContainerStatuses: []corev1.ContainerStatus{status},
+		},
+	}
+}
+
+func testClient() *Client {
+	cs := fake.NewSimpleClientset(
+		pod("api-7d9f-b", "api", false, 4, "CrashLoopBackOff"),
+		pod("api-7d9f-a", "api", true, 0, ""),
+		pod("billing-5c6d-a", "billing", true, 0, ""),
+	)
+	c := newClient(Config{KubernetesServiceLabel: "app", KubernetesLogsMaxTail: 1000, KubernetesLogsMaxBytes: 1024},
+		map[int]kubernetes.Interface{1: cs})
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestClient_Pods(t *testing.T) {
+	pods, err := testClient().Pods(context.Background(), eve.Namespace{Name: "una-int-current", ClusterID: 1}, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 2 || pods[0].Name != "api-7d9f-a" || pods[1].Name != "api-7d9f-b" {
+		t.Fatalf("pods = %+v, want the api pods by name", pods)
+	}
+	want := Pod{Name: "api-7d9f-b", Phase: "Running", Ready: "0/2", Restarts: 4, Age: 90 * time.Minute, Version: "1.2", Node: "node-1", Reason: "CrashLoopBackOff"}
+	if pods[1] != want {
+		t.Errorf("pod = %+v, want %+v", pods[1], want)
+	}
+}
+
+func TestClient_UnknownCluster(t *testing.T) {
+	_, err := testClient().Pods(context.Background(), eve.Namespace{Name: "una-int-current", ClusterID: 2}, "api")
+	if !errors.Is(err, ErrUnknownCluster) {
+		t.Errorf("err = %v, want ErrUnknownCluster", err)
+	}
+}
+
+func TestClient_Logs(t *testing.T) {
+	logs, err := testClient().Logs(context.Background(), eve.Namespace{Name: "una-int-current", ClusterID: 1}, "billing", LogOptions{Tail: 50, Since: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fake clientset logs are "fake logs"
+	if want := "==> billing-5c6d-a (billing) <==\nfake logs\n\n"; string(logs) != want {
+		t.Errorf("logs = %q, want %q", logs, want)
+	}
+}
+
+func TestClient_copyLogs(t *testing.T) {
+	c := newClient(Config{KubernetesLogsMaxBytes: 10}, nil)
+	var w strings.Builder
+	c.copyLogs(&w, strings.NewReader("line 1\nline 2\nline 3\n"))
+	if want := "line 1\n... (truncated)\n"; w.String() != want {
+		t.Errorf("logs = %q, want %q", w.String(), want)
+	}
+}
+
+func TestClusters_Decode(t *testing.T) {
+	var clusters Clusters
+	if err := clusters.Decode(`[{"id": 1, "name": "una-int", "server": "https://k8s.int:6443", "token_file": "/var/run/secrets/eve-bot/token"}]`); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].ID != 1 {
+		t.Errorf("clusters = %+v", clusters)
+	}
+	if err := clusters.Decode(`[{"id": 1, "server": "https://k8s.int:6443"}]`); err == nil {
+		t.Error("expected an error without a token file")
+	}
+}
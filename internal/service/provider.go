@@ -9,9 +9,14 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 
+	"github.com/unanet/eve-bot/internal/botcommander/confirm"
 	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
 
 	"github.com/unanet/eve-bot/internal/config"
+	"github.com/unanet/eve-bot/internal/cronrouter"
+	"github.com/unanet/eve-bot/internal/eveapi"
+	"github.com/unanet/eve-bot/internal/subscriptions"
+	"github.com/unanet/eve-bot/internal/webhooks"
 )
 
 // Provider provides access to the Common Deps/Services required for this project
@@ -19,6 +24,18 @@ type Provider struct {
 	ChatService     interfaces.ChatProvider
 	CommandResolver interfaces.CommandResolver
 	EveAPI          interfaces.EveAPI
+	AliasStore      interfaces.AliasStore
+	ContextStore    interfaces.ChannelContextStore
+	MetadataHistory interfaces.MetadataHistoryStore
+	Deployments     interfaces.DeploymentStore
+	CronRouter      *cronrouter.Router
+	Changelog       interfaces.ChangelogProvider
+	Subscriptions   interfaces.SubscriptionStore
+	Notifier        *subscriptions.Notifier
+	Webhooks        *webhooks.Dispatcher
+	Kubernetes      interfaces.KubernetesProvider
+	Confirmations   *confirm.Store
+	Masker          *eveapi.Masker
 	Cfg             *config.Config
 	oidc            *identity.Validator
 	userDB          *dynamodb.DynamoDB
@@ -42,7 +59,7 @@ func OpenIDConnectParam(cfg *config.Config, id *identity.Validator) Option {
 			ClientID: cfg.Identity.ClientID,
 		})
 
-		svc.oauth.config = oauth2.Config {
+		svc.oauth.config = oauth2.Config{
 			ClientID:     cfg.Identity.ClientID,
 			ClientSecret: cfg.Oidc.ClientSecret,
 			RedirectURL:  cfg.Oidc.RedirectURL,
@@ -71,6 +88,72 @@ func EveAPIParam(e interfaces.EveAPI) Option {
 	}
 }
 
+func AliasStoreParam(a interfaces.AliasStore) Option {
+	return func(svc *Provider) {
+		svc.AliasStore = a
+	}
+}
+
+func ChannelContextStoreParam(c interfaces.ChannelContextStore) Option {
+	return func(svc *Provider) {
+		svc.ContextStore = c
+	}
+}
+
+func MetadataHistoryStoreParam(m interfaces.MetadataHistoryStore) Option {
+	return func(svc *Provider) {
+		svc.MetadataHistory = m
+	}
+}
+
+func DeploymentStoreParam(d interfaces.DeploymentStore) Option {
+	return func(svc *Provider) {
+		svc.Deployments = d
+	}
+}
+
+func CronRouterParam(r *cronrouter.Router) Option {
+	return func(svc *Provider) {
+		svc.CronRouter = r
+	}
+}
+
+func SubscriptionStoreParam(s interfaces.SubscriptionStore) Option {
+	return func(svc *Provider) {
+		svc.Subscriptions = s
+	}
+}
+
+func NotifierParam(n *subscriptions.Notifier) Option {
+	return func(svc *Provider) {
+		svc.Notifier = n
+	}
+}
+
+func WebhooksParam(d *webhooks.Dispatcher) Option {
+	return func(svc *Provider) {
+		svc.Webhooks = d
+	}
+}
+
+func ConfirmationStoreParam(c interfaces.ConfirmationStore) Option {
+	return func(svc *Provider) {
+		svc.Confirmations = confirm.NewStore(c, confirm.DefaultTTL)
+	}
+}
+
+func KubernetesProviderParam(k interfaces.KubernetesProvider) Option {
+	return func(svc *Provider) {
+		svc.Kubernetes = k
+	}
+}
+
+func ChangelogProviderParam(c interfaces.ChangelogProvider) Option {
+	return func(svc *Provider) {
+		svc.Changelog = c
+	}
+}
+
 func ChatProviderParam(c interfaces.ChatProvider) Option {
 	return func(svc *Provider) {
 		svc.ChatService = c
@@ -79,9 +162,18 @@ func ChatProviderParam(c interfaces.ChatProvider) Option {
 
 type Option func(*Provider)
 
+// MaskerParam sets the Masker of the secret metadata values in the chat messages (i.e. with the configured patterns),
+// the Masker of the default patterns is used without it
+func MaskerParam(m *eveapi.Masker) Option {
+	return func(svc *Provider) {
+		svc.Masker = m
+	}
+}
+
 func New(cfg *config.Config, opts ...Option) *Provider {
 	svc := &Provider{
-		Cfg: cfg,
+		Cfg:    cfg,
+		Masker: eveapi.NewMasker(eveapi.DefaultMaskPatterns, eveapi.DefaultMaskEntropy, eveapi.DefaultMaskMinLength),
 	}
 
 	for _, opt := range opts {
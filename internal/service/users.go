@@ -10,12 +10,22 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unanet/eve-bot/internal/botcommander/args"
 	"github.com/unanet/eve-bot/internal/botcommander/commands"
+	"github.com/unanet/eve-bot/internal/webhooks"
 	errs "github.com/unanet/go/pkg/errors"
 	"github.com/unanet/go/pkg/log"
 	"go.uber.org/zap"
 )
 
+var statAuthorizationDenials = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "authorization_denials_total",
+		Help: "The total number of commands denied by requested role (i.e. eve-deploy-prod)",
+	}, []string{"role"})
+
 type UserStore interface {
 	SaveUserAuth(ctx context.Context, state string, code string) error
 	ReadUser(userID string) (*UserEntry, error)
@@ -130,13 +140,31 @@ func (p *Provider) IsAuthorized(cmd commands.EvebotCommand, userEntry *UserEntry
 		return true
 	}
 	// Check if admin or cmd is Help, Root or Auth command
-	return (cmd.Info().IsHelpRequest || cmd.Info().IsRootCmd || cmd.Info().IsAuthCmd || userEntry.IsAdmin)
+	if cmd.Info().IsHelpRequest || cmd.Info().IsRootCmd || cmd.Info().IsAuthCmd || userEntry.IsAdmin {
+		return true
+	}
+	statAuthorizationDenials.WithLabelValues(reqRole).Inc()
+	p.Webhooks.Publish(context.Background(), webhooks.EventAuthDenied, webhooks.AuthDeniedData{
+		User:    cmd.Info().User,
+		Command: cmd.Info().CommandName,
+		Role:    reqRole,
+	})
+	return false
 }
 
 func requestedRole(cmd commands.EvebotCommand) string {
 	tmpRequestedRoleCmd := fmt.Sprintf("eve-%s", cmd.Info().CommandName)
-	if strings.Contains(strings.ToLower(extractEnv(cmd.Options())), "prod") {
+	// revealing the secret metadata values requires its own role (i.e. eve-show-reveal-prod)
+	if reveal, ok := cmd.Options()[args.RevealName].(bool); ok && reveal {
+		tmpRequestedRoleCmd = tmpRequestedRoleCmd + "-" + args.RevealName
+	}
+	// copy/diff also read the source environment (i.e. copying from prod requires the prod role)
+	if isProd(extractEnv(cmd.Options())) || isProd(commands.ExtractStringOpt(commands.SourceEnvironmentOpt, cmd.Options())) {
 		tmpRequestedRoleCmd = tmpRequestedRoleCmd + "-prod"
 	}
 	return tmpRequestedRoleCmd
 }
+
+func isProd(env string) bool {
+	return strings.Contains(strings.ToLower(env), "prod")
+}
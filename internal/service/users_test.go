@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/unanet/eve-bot/internal/botcommander/commands"
+)
+
+func Test_requestedRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "show",
+			input: "show metadata for api in current int",
+			want:  "eve-show",
+		},
+		{
+			name:  "show prod",
+			input: "show metadata for api in current prod",
+			want:  "eve-show-prod",
+		},
+		{
+			name:  "reveal prod",
+			input: "show metadata for api in current prod reveal=true",
+			want:  "eve-show-reveal-prod",
+		},
+		{
+			name:  "copy from prod",
+			input: "copy metadata for api from current prod to current int",
+			want:  "eve-copy-prod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := strings.Fields(tt.input)
+			cmd := commands.NewFactory().Items()[fields[0]](fields, "channel", "user")
+			if got := requestedRole(cmd); got != tt.want {
+				t.Errorf("requestedRole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_IsAuthorizedCountsDenials(t *testing.T) {
+	p := &Provider{}
+	denied := testutil.ToFloat64(statAuthorizationDenials.WithLabelValues("eve-show-prod"))
+
+	fields := strings.Fields("show metadata for api in current prod")
+	cmd := commands.NewFactory().Items()[fields[0]](fields, "channel", "user")
+	if p.IsAuthorized(cmd, &UserEntry{Roles: map[string]bool{"eve-show": true}}) {
+		t.Fatal("IsAuthorized() = true, want the prod show denied")
+	}
+	if !p.IsAuthorized(cmd, &UserEntry{Roles: map[string]bool{"eve-show-prod": true}}) {
+		t.Fatal("IsAuthorized() = false, want the prod show authorized")
+	}
+	if got := testutil.ToFloat64(statAuthorizationDenials.WithLabelValues("eve-show-prod")) - denied; got != 1 {
+		t.Errorf("authorization denials = %v, want 1", got)
+	}
+}
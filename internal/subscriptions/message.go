@@ -0,0 +1,43 @@
+package subscriptions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unanet/eve-bot/internal/datastore"
+)
+
+// ListMessage formats the subscriptions of a user
+func ListMessage(v []datastore.Subscription) string {
+	if len(v) == 0 {
+		return "no subscriptions"
+	}
+	msg := ""
+	for _, s := range v {
+		msg += fmt.Sprintf("deployments %s -> %s\n", Scope(s), Delivery(s))
+	}
+	return msg
+}
+
+// Scope describes the namespace, environment and service of a subscription
+func Scope(s datastore.Subscription) string {
+	var scope []string
+	switch {
+	case len(s.Namespace) > 0:
+		scope = append(scope, fmt.Sprintf("in *%s* ( _%s_ )", s.Namespace, s.Environment))
+	case len(s.Environment) > 0:
+		scope = append(scope, fmt.Sprintf("across _%s_", s.Environment))
+	}
+	if len(s.Service) > 0 {
+		scope = append(scope, fmt.Sprintf("of `%s`", s.Service))
+	}
+	return strings.Join(scope, " ")
+}
+
+// Delivery describes where the notifications of a subscription are posted
+func Delivery(s datastore.Subscription) string {
+	if len(s.Channel) > 0 {
+		return fmt.Sprintf("<#%s>", s.Channel)
+	}
+	return "direct message"
+}
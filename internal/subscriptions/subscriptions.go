@@ -0,0 +1,90 @@
+package subscriptions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve-bot/internal/deployments"
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+var (
+	statNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evebot_subscription_notifications_total",
+		Help: "Number of deployment subscription notifications by delivery",
+	}, []string{"delivery"})
+)
+
+// Notifier notifies the subscribers of the deployment results (whoever ran the deployment)
+type Notifier struct {
+	store interfaces.SubscriptionStore
+	chat  interfaces.ChatProvider
+}
+
+// New creates a Notifier
+func New(store interfaces.SubscriptionStore, chat interfaces.ChatProvider) *Notifier {
+	return &Notifier{store: store, chat: chat}
+}
+
+// Notify posts the result of a namespace deployment plan to the matching subscriptions
+// once the plan has a terminal status, the actor (the user that ran the deployment, empty for the cron jobs)
+// isn't notified in a direct message since the result is already posted in the thread of the command
+func (n *Notifier) Notify(ctx context.Context, plan eve.NSDeploymentPlan, actor string) {
+	if n == nil {
+		return
+	}
+	if plan.Status != eve.DeploymentPlanStatusComplete && plan.Status != eve.DeploymentPlanStatusErrors {
+		return
+	}
+	if plan.NothingToDeploy() {
+		return
+	}
+	subscriptions, err := n.store.ListAllSubscriptions(ctx)
+	if err != nil {
+		log.Logger.Error("failed to list the deployment subscriptions", zap.Error(err))
+		return
+	}
+
+	result := datastore.NewDeploymentResult(plan)
+	msg := notificationMsg(result, actor)
+	// a single notification per user (direct message) or channel
+	notified := make(map[string]bool)
+	for _, s := range subscriptions {
+		if !s.Matches(result) {
+			continue
+		}
+		if len(s.Channel) > 0 {
+			if notified[s.Channel] {
+				continue
+			}
+			notified[s.Channel] = true
+			statNotifications.WithLabelValues("channel").Inc()
+			n.chat.PostMessage(ctx, msg, s.Channel)
+			continue
+		}
+		if s.Owner == actor || notified["@"+s.Owner] {
+			continue
+		}
+		notified["@"+s.Owner] = true
+		statNotifications.WithLabelValues("dm").Inc()
+		n.chat.PostPrivateMessage(ctx, msg, s.Owner)
+	}
+}
+
+func notificationMsg(result datastore.DeploymentResult, actor string) string {
+	icon := ":white_check_mark:"
+	if result.Status == eve.DeploymentPlanStatusErrors {
+		icon = ":x:"
+	}
+	by := "a scheduled deployment"
+	if len(actor) > 0 {
+		by = fmt.Sprintf("a deployment by <@%s>", actor)
+	}
+	return fmt.Sprintf("%s %s %s (subscription)\n%s", icon, by, result.Status, deployments.ResultMessage(result))
+}
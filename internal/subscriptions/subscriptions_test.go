@@ -0,0 +1,98 @@
+package subscriptions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// fakeStore serves the subscriptions (the other calls aren't used by the notifier)
+type fakeStore struct {
+	interfaces.SubscriptionStore
+	subscriptions []datastore.Subscription
+}
+
+func (s *fakeStore) ListAllSubscriptions(ctx context.Context) ([]datastore.Subscription, error) {
+	return s.subscriptions, nil
+}
+
+// fakeChat records the posted messages by channel (and the private messages by user)
+type fakeChat struct {
+	interfaces.ChatProvider
+	posts map[string][]string
+}
+
+func (c *fakeChat) PostMessage(ctx context.Context, msg, channel string) string {
+	c.posts[channel] = append(c.posts[channel], msg)
+	return ""
+}
+
+func (c *fakeChat) PostPrivateMessage(ctx context.Context, msg string, user string) {
+	c.posts["@"+user] = append(c.posts["@"+user], msg)
+}
+
+func plan(status eve.DeploymentPlanStatus, result eve.DeployArtifactResult) eve.NSDeploymentPlan {
+	return eve.NSDeploymentPlan{
+		Namespace:       &eve.NamespaceRequest{Alias: "current"},
+		EnvironmentName: "prod",
+		Services: eve.DeployServices{{
+			DeployArtifact: &eve.DeployArtifact{ArtifactName: "billing", DeployedVersion: "1.1", AvailableVersion: "1.2", Result: result},
+			ServiceName:    "billing",
+		}},
+		Status: status,
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	store := &fakeStore{subscriptions: []datastore.Subscription{
+		{Owner: "U1", Namespace: "current", Environment: "prod"},
+		{Owner: "U1", Service: "billing"},
+		{Owner: "U2", Environment: "prod"},
+		{Owner: "U2", Environment: "prod", Channel: "C1"},
+		{Owner: "U3", Environment: "prod", Channel: "C1"},
+		{Owner: "U4", Environment: "int"},
+	}}
+	chat := &fakeChat{posts: map[string][]string{}}
+	n := New(store, chat)
+
+	n.Notify(context.Background(), plan(eve.DeploymentPlanStatusComplete, eve.DeployArtifactResultSuccess), "U2")
+
+	if len(chat.posts["@U1"]) != 1 {
+		t.Errorf("U1 posts = %v, want a single private message", chat.posts["@U1"])
+	}
+	if len(chat.posts["@U2"]) != 0 {
+		t.Errorf("U2 posts = %v, want no private message (U2 ran the deployment)", chat.posts["@U2"])
+	}
+	if len(chat.posts["C1"]) != 1 {
+		t.Fatalf("C1 posts = %v, want a single message", chat.posts["C1"])
+	}
+	for _, want := range []string{"a deployment by <@U2>", "*current* ( _prod_ ) complete", "billing: 1.1 -> 1.2 (success)"} {
+		if !strings.Contains(chat.posts["C1"][0], want) {
+			t.Errorf("C1 message = %q, want %q", chat.posts["C1"][0], want)
+		}
+	}
+	if len(chat.posts["@U4"]) != 0 {
+		t.Errorf("U4 posts = %v, want no message", chat.posts["@U4"])
+	}
+}
+
+func TestNotifier_NotifyNotTerminal(t *testing.T) {
+	store := &fakeStore{subscriptions: []datastore.Subscription{{Owner: "U1", Environment: "prod"}}}
+	chat := &fakeChat{posts: map[string][]string{}}
+	n := New(store, chat)
+
+	n.Notify(context.Background(), plan(eve.DeploymentPlanStatusPending, eve.DeployArtifactResultSuccess), "")
+	n.Notify(context.Background(), plan(eve.DeploymentPlanStatusComplete, eve.DeployArtifactResultNoop), "")
+	if len(chat.posts) != 0 {
+		t.Errorf("posts = %v, want none", chat.posts)
+	}
+
+	n.Notify(context.Background(), plan(eve.DeploymentPlanStatusErrors, eve.DeployArtifactResultFailed), "")
+	if msgs := chat.posts["@U1"]; len(msgs) != 1 || !strings.Contains(msgs[0], "a scheduled deployment errors") {
+		t.Errorf("U1 posts = %v, want the scheduled deployment errors", msgs)
+	}
+}
@@ -0,0 +1,158 @@
+package sweeper
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/errors"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Config is the overdue deployment sweeper config (0 disables the sweeper or the deadline of a plan type)
+// EVEBOT_DEPLOYMENT_SWEEP_INTERVAL
+// EVEBOT_DEPLOYMENT_DEADLINE_APPLICATION
+// EVEBOT_DEPLOYMENT_DEADLINE_JOB
+// EVEBOT_DEPLOYMENT_DEADLINE_RESTART
+type Config struct {
+	DeploymentSweepInterval time.Duration `split_words:"true" default:"1m"`
+	// the expected completion of the deployment plans by type (after which the missing callbacks are reported)
+	DeploymentDeadlineApplication time.Duration `split_words:"true" default:"30m"`
+	DeploymentDeadlineJob         time.Duration `split_words:"true" default:"1h"`
+	DeploymentDeadlineRestart     time.Duration `split_words:"true" default:"15m"`
+}
+
+// DeploymentDeadline returns the expected duration of a deployment plan type (0 when it isn't tracked)
+func (c Config) DeploymentDeadline(planType eve.PlanType) time.Duration {
+	switch planType {
+	case eve.DeploymentPlanTypeApplication:
+		return c.DeploymentDeadlineApplication
+	case eve.DeploymentPlanTypeJob:
+		return c.DeploymentDeadlineJob
+	case eve.DeploymentPlanTypeRestart:
+		return c.DeploymentDeadlineRestart
+	}
+	return 0
+}
+
+// Sweeper reports the deployments that never called back (i.e. the eve-api crashed mid-deploy)
+// to the thread of the command and to the devops monitoring channel
+type Sweeper struct {
+	cfg               Config
+	store             interfaces.DeploymentStore
+	chat              interfaces.ChatProvider
+	monitoringChannel string
+	dashboardURL      string
+	now               func() time.Time
+}
+
+// New creates a Sweeper
+func New(cfg Config, store interfaces.DeploymentStore, chat interfaces.ChatProvider, monitoringChannel, dashboardURL string) *Sweeper {
+	return &Sweeper{
+		cfg:               cfg,
+		store:             store,
+		chat:              chat,
+		monitoringChannel: monitoringChannel,
+		dashboardURL:      dashboardURL,
+		now:               time.Now,
+	}
+}
+
+// Run sweeps the overdue deployments every interval until the context is done
+func (s *Sweeper) Run(ctx context.Context) {
+	if s.cfg.DeploymentSweepInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.DeploymentSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep reports the overdue deployments (once, the reported deployments are marked)
+func (s *Sweeper) Sweep(ctx context.Context) {
+	now := s.now()
+	overdue, err := s.store.ListOverdueDeployments(ctx, now)
+	if err != nil {
+		log.Logger.Error("failed to sweep the overdue deployments", zap.Error(err))
+		return
+	}
+	for _, d := range overdue {
+		if err := s.store.MarkDeploymentOverdue(ctx, d.ID, now); err != nil {
+			if !goerrors.Is(err, errors.ErrNotFound) {
+				log.Logger.Error("failed to mark the overdue deployment", zap.Error(err), zap.String("deployment", d.ID))
+			}
+			continue
+		}
+		log.Logger.Warn("overdue deployment", zap.String("deployment", d.ID), zap.Time("deadline", d.Deadline))
+		link := s.logsLink(d, now)
+		s.chat.PostMessageThread(ctx, overdueMsg(d, now, link), d.Channel, d.TS)
+		s.chat.PostMessage(ctx, fmt.Sprintf("<@%s>'s %s in <#%s> never called back\n%s", d.User, d.Command, d.Channel, overdueMsg(d, now, link)), s.monitoringChannel)
+	}
+}
+
+// logsLink is the logging dashboard link for the time range of the deployment
+func (s *Sweeper) logsLink(d datastore.Deployment, now time.Time) string {
+	u, err := url.Parse(s.dashboardURL)
+	if err != nil || len(s.dashboardURL) == 0 {
+		return s.dashboardURL
+	}
+	q := u.Query()
+	q.Set("from", strconv.FormatInt(d.CreatedAt.Add(-time.Minute).UnixNano()/int64(time.Millisecond), 10))
+	q.Set("to", strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// overdueMsg describes the overdue deployment plan
+func overdueMsg(d datastore.Deployment, now time.Time, link string) string {
+	var artifacts []string
+	for _, a := range d.Plan.Artifacts {
+		if len(a.RequestedVersion) > 0 {
+			artifacts = append(artifacts, a.Name+":"+a.RequestedVersion)
+		} else {
+			artifacts = append(artifacts, a.Name)
+		}
+	}
+	if len(artifacts) == 0 {
+		artifacts = append(artifacts, "all")
+	}
+	callbacks := fmt.Sprintf("%d/%d", completedPlans(d), len(d.PlanIDs))
+	if len(d.PlanIDs) == 0 {
+		callbacks = "none"
+	}
+
+	msg := fmt.Sprintf(":hourglass: the %s plan requested at %s is overdue (no callback after %s), the eve-api may have failed mid-deploy\n",
+		d.Plan.Type, d.CreatedAt.Format("15:04"), now.Sub(d.CreatedAt).Round(time.Minute))
+	msg += fmt.Sprintf("```deployment: %s\nenvironment: %s\nnamespaces: %s\nartifacts: %s\ncallbacks: %s```",
+		d.ID, d.Plan.Environment, strings.Join(d.Plan.NamespaceAliases, ", "), strings.Join(artifacts, ", "), callbacks)
+	if len(link) > 0 {
+		msg += fmt.Sprintf("\n<%s|Logs>", link)
+	}
+	return msg
+}
+
+// completedPlans counts the namespace plans with a terminal status
+func completedPlans(d datastore.Deployment) int {
+	completed := 0
+	for _, id := range d.PlanIDs {
+		if status, ok := d.Plans[id]; ok && status != eve.DeploymentPlanStatusPending {
+			completed++
+		}
+	}
+	return completed
+}
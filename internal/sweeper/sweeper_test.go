@@ -0,0 +1,94 @@
+package sweeper
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve-bot/internal/botcommander/interfaces"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/errors"
+)
+
+// fakeStore serves the overdue deployments (the other calls aren't used by the sweeper)
+type fakeStore struct {
+	interfaces.DeploymentStore
+	overdue []datastore.Deployment
+	marked  map[string]bool
+}
+
+func (s *fakeStore) ListOverdueDeployments(ctx context.Context, now time.Time) ([]datastore.Deployment, error) {
+	return s.overdue, nil
+}
+
+func (s *fakeStore) MarkDeploymentOverdue(ctx context.Context, id string, at time.Time) error {
+	if s.marked[id] {
+		return errors.ErrNotFound
+	}
+	s.marked[id] = true
+	return nil
+}
+
+// fakeChat records the posted messages by channel (and thread)
+type fakeChat struct {
+	interfaces.ChatProvider
+	posts map[string][]string
+}
+
+func (c *fakeChat) PostMessage(ctx context.Context, msg, channel string) string {
+	c.posts[channel] = append(c.posts[channel], msg)
+	return ""
+}
+
+func (c *fakeChat) PostMessageThread(ctx context.Context, msg, channel, ts string) string {
+	c.posts[channel+"/"+ts] = append(c.posts[channel+"/"+ts], msg)
+	return ""
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{marked: map[string]bool{}, overdue: []datastore.Deployment{{
+		ID:      "d1",
+		Command: "deploy",
+		User:    "U1",
+		Channel: "C1",
+		TS:      "1.1",
+		Plan: eve.DeploymentPlanOptions{
+			Environment:      "int",
+			NamespaceAliases: eve.StringList{"current"},
+			Artifacts:        eve.ArtifactDefinitions{{Name: "api", RequestedVersion: "1.2"}},
+			Type:             eve.DeploymentPlanTypeApplication,
+		},
+		PlanIDs:   []string{"p1", "p2"},
+		Plans:     map[string]eve.DeploymentPlanStatus{"p1": eve.DeploymentPlanStatusComplete},
+		CreatedAt: now.Add(-45 * time.Minute),
+	}}}
+	chat := &fakeChat{posts: map[string][]string{}}
+	s := New(Config{}, store, chat, "devops", "https://grafana/d/logs?orgId=1")
+	s.now = func() time.Time { return now }
+
+	s.Sweep(context.Background())
+	s.Sweep(context.Background())
+
+	thread := chat.posts["C1/1.1"]
+	if len(thread) != 1 {
+		t.Fatalf("thread posts = %v, want a single overdue message", thread)
+	}
+	for _, want := range []string{"application plan", "45m0s", "api:1.2", "callbacks: 1/2", "from=1622546040000", "orgId=1"} {
+		if !strings.Contains(thread[0], want) {
+			t.Errorf("thread message = %q, want %q", thread[0], want)
+		}
+	}
+	if devops := chat.posts["devops"]; len(devops) != 1 || !strings.Contains(devops[0], "<@U1>'s deploy in <#C1>") {
+		t.Errorf("devops posts = %v, want the overdue deployment", devops)
+	}
+}
+
+func TestConfig_DeploymentDeadline(t *testing.T) {
+	cfg := Config{DeploymentDeadlineApplication: time.Minute, DeploymentDeadlineJob: time.Hour, DeploymentDeadlineRestart: time.Second}
+	if cfg.DeploymentDeadline(eve.DeploymentPlanTypeJob) != time.Hour || cfg.DeploymentDeadline("migration") != 0 {
+		t.Errorf("unexpected deadlines")
+	}
+}
@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/unanet/eve-bot"
+
+// the exporters of the spans
+const (
+	// ExporterNone doesn't export the spans (the trace context is still propagated)
+	ExporterNone = "none"
+	// ExporterStdout writes the spans to stdout
+	ExporterStdout = "stdout"
+	// ExporterOTLP sends the spans to an OTLP (http) collector
+	ExporterOTLP = "otlp"
+)
+
+// Config is the tracing config
+// EVEBOT_TRACING_EXPORTER
+// EVEBOT_TRACING_OTLP_ENDPOINT
+// EVEBOT_TRACING_OTLP_INSECURE
+// EVEBOT_TRACING_SAMPLE_RATIO
+type Config struct {
+	// TracingExporter is one of none, stdout or otlp
+	TracingExporter string `split_words:"true" default:"none"`
+	// TracingOtlpEndpoint is the host:port of the OTLP collector (i.e. otel-collector:4318)
+	TracingOtlpEndpoint string  `split_words:"true" default:"localhost:4318"`
+	TracingOtlpInsecure bool    `split_words:"true" default:"false"`
+	TracingSampleRatio  float64 `split_words:"true" default:"1"`
+}
+
+// Init sets the global tracer provider (with the configured exporter) and the W3C trace context propagator
+// the returned func flushes the spans on shutdown
+func Init(ctx context.Context, cfg Config, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(cfg.TracingExporter) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.TracingOtlpEndpoint)}
+		if cfg.TracingOtlpInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span (a child of the span in the context)
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Error records the error on the span (and sets its status)
+func Error(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// End records the error (when there is one) and ends the span
+func End(span trace.Span, err error) {
+	Error(span, err)
+	span.End()
+}
+
+// Detach returns a context with the span of the ctx but without its deadline/cancellation
+// (i.e. the work that continues after the http request)
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// queryCarrier is the TextMapCarrier of the url query parameters
+type queryCarrier url.Values
+
+func (q queryCarrier) Get(key string) string {
+	return url.Values(q).Get(key)
+}
+
+func (q queryCarrier) Set(key, value string) {
+	url.Values(q).Set(key, value)
+}
+
+func (q queryCarrier) Keys() []string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// InjectQuery adds the trace context of the ctx to the query parameters (i.e. traceparent)
+func InjectQuery(ctx context.Context, query url.Values) {
+	otel.GetTextMapPropagator().Inject(ctx, queryCarrier(query))
+}
+
+// ExtractQuery returns the ctx with the trace context of the query parameters (the remote parent of the next spans)
+func ExtractQuery(ctx context.Context, query url.Values) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, queryCarrier(query))
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtractQuery(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tp := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx, span := Start(context.Background(), "deploy")
+	defer span.End()
+
+	query := url.Values{}
+	query.Set("channel", "C1")
+	InjectQuery(ctx, query)
+	if len(query.Get("traceparent")) == 0 {
+		t.Fatalf("expected a traceparent param, got %v", query)
+	}
+
+	// the callback url round trip
+	parsed, err := url.ParseQuery(query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := trace.SpanContextFromContext(ExtractQuery(context.Background(), parsed))
+	if !remote.IsRemote() || remote.TraceID() != span.SpanContext().TraceID() || remote.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected the span context of the deploy span, got %v", remote)
+	}
+
+	_, child := Start(ExtractQuery(context.Background(), parsed), "eve.callback")
+	defer child.End()
+	if child.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("expected the callback span in the deploy trace")
+	}
+}
+
+func TestExtractQuery_NoTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if sc := trace.SpanContextFromContext(ExtractQuery(context.Background(), url.Values{"channel": {"C1"}})); sc.IsValid() {
+		t.Errorf("expected no span context, got %v", sc)
+	}
+}
+
+func TestInit_UnknownExporter(t *testing.T) {
+	if _, err := Init(context.Background(), Config{TracingExporter: "jaeger"}, "eve-bot"); err == nil {
+		t.Error("expected an unknown exporter error")
+	}
+}
@@ -0,0 +1,426 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/unanet/eve-bot/internal/datastore"
+	"github.com/unanet/eve/pkg/eve"
+	"github.com/unanet/go/pkg/log"
+	"go.uber.org/zap"
+)
+
+// the event types
+const (
+	EventCommandExecuted     = "command.executed"
+	EventDeploymentCompleted = "deployment.completed"
+	EventDeploymentFailed    = "deployment.failed"
+	EventAuthDenied          = "auth.denied"
+	EventMetadataChanged     = "metadata.changed"
+)
+
+// the headers of the webhook requests
+const (
+	EventHeader     = "X-Evebot-Event"
+	DeliveryHeader  = "X-Evebot-Delivery"
+	TimestampHeader = "X-Evebot-Timestamp"
+	SignatureHeader = "X-Evebot-Signature"
+)
+
+var (
+	statDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evebot_webhook_deliveries_total",
+		Help: "Number of webhook delivery attempts by event type and result (success, retry, dead_letter)",
+	}, []string{"event", "result"})
+)
+
+// Config is the outbound webhooks config
+// EVEBOT_WEBHOOKS
+// EVEBOT_WEBHOOK_MAX_ATTEMPTS
+// EVEBOT_WEBHOOK_RETRY_BACKOFF
+// EVEBOT_WEBHOOK_TIMEOUT
+// EVEBOT_WEBHOOK_QUEUE_SIZE
+// EVEBOT_WEBHOOK_DRAIN_TIMEOUT
+type Config struct {
+	// Webhooks are the subscribers of the events (a json array)
+	Webhooks Subscribers `split_words:"true"`
+	// WebhookMaxAttempts is the number of attempts of a delivery before it is dead lettered
+	WebhookMaxAttempts int `split_words:"true" default:"5"`
+	// WebhookRetryBackoff is the wait before the first retry (doubled after every attempt)
+	WebhookRetryBackoff time.Duration `split_words:"true" default:"1s"`
+	WebhookTimeout      time.Duration `split_words:"true" default:"5s"`
+	// WebhookQueueSize is the number of pending deliveries of a subscriber (the events are dead lettered when it is full)
+	WebhookQueueSize int `split_words:"true" default:"100"`
+	// WebhookDrainTimeout is the time left to deliver the queued events on shutdown (the rest is dead lettered)
+	WebhookDrainTimeout time.Duration `split_words:"true" default:"10s"`
+}
+
+// Subscriber is a webhook receiver
+type Subscriber struct {
+	URL string `json:"url"`
+	// Secret signs the requests (see Signature), empty doesn't sign them
+	Secret string `json:"secret"`
+	// Events are the (glob) patterns of the event types (i.e. deployment.*), empty receives every event
+	Events []string `json:"events"`
+}
+
+// Accepts checks if the subscriber receives the event type
+func (s Subscriber) Accepts(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, pattern := range s.Events {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribers are the webhook receivers
+type Subscribers []Subscriber
+
+// Decode satisfies the envconfig.Decoder interface (the subscribers are a json array)
+func (s *Subscribers) Decode(value string) error {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil
+	}
+	var subscribers Subscribers
+	if err := json.Unmarshal([]byte(value), &subscribers); err != nil {
+		return fmt.Errorf("invalid webhooks: %w", err)
+	}
+	for i, sub := range subscribers {
+		u, err := url.Parse(sub.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid webhook %d: invalid url", i)
+		}
+		for _, pattern := range sub.Events {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid webhook %d: %w", i, err)
+			}
+		}
+	}
+	*s = subscribers
+	return nil
+}
+
+// Event is the json body of the webhook requests
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Time      time.Time   `json:"time"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// CommandData is the data of the command.executed events
+type CommandData struct {
+	Command  string  `json:"command"`
+	User     string  `json:"user"`
+	Channel  string  `json:"channel"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+// DeploymentData is the data of the deployment.completed and deployment.failed events
+type DeploymentData struct {
+	// Deployment is the eve-bot deployment id (empty for the scheduled deployments)
+	Deployment string                     `json:"deployment,omitempty"`
+	User       string                     `json:"user,omitempty"`
+	Channel    string                     `json:"channel,omitempty"`
+	Result     datastore.DeploymentResult `json:"result"`
+}
+
+// AuthDeniedData is the data of the auth.denied events
+type AuthDeniedData struct {
+	User    string `json:"user"`
+	Command string `json:"command"`
+	Role    string `json:"role"`
+}
+
+// MetadataData is the data of the metadata.changed events (the keys only, the values may be secrets)
+type MetadataData struct {
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	User   string   `json:"user"`
+	Keys   []string `json:"keys"`
+}
+
+// DeploymentEventType is the event type of a terminal deployment plan status (empty for the other statuses)
+func DeploymentEventType(status eve.DeploymentPlanStatus) string {
+	switch status {
+	case eve.DeploymentPlanStatusComplete:
+		return EventDeploymentCompleted
+	case eve.DeploymentPlanStatusErrors:
+		return EventDeploymentFailed
+	}
+	return ""
+}
+
+// Signature is the hex HMAC-SHA256 of the timestamp and the body ("{timestamp}.{body}"),
+// the receivers compare it with the SignatureHeader (sha256={signature}) and reject the old timestamps
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp + "."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is a pending event of a subscriber
+type delivery struct {
+	event Event
+	body  []byte
+}
+
+// Dispatcher delivers the events to the subscribers in the background (a queue per subscriber),
+// the failed deliveries are retried with an exponential backoff and dead lettered after the last attempt
+// the queues are in memory: they are drained on shutdown (until the WebhookDrainTimeout), the events left are dead lettered
+type Dispatcher struct {
+	cfg        Config
+	client     *http.Client
+	queues     []chan delivery
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) bool
+	deadLetter func(sub Subscriber, d delivery, attempts int, err error)
+
+	// stopped is set once the queues are drained, the events published after are dead lettered
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// New creates a Dispatcher
+func New(cfg Config) *Dispatcher {
+	d := &Dispatcher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.WebhookTimeout},
+		now:    time.Now,
+		sleep:  sleep,
+	}
+	d.deadLetter = d.logDeadLetter
+	for range cfg.Webhooks {
+		d.queues = append(d.queues, make(chan delivery, cfg.WebhookQueueSize))
+	}
+	return d
+}
+
+// Publish queues the event for the subscribers of its type (it never blocks the caller)
+func (d *Dispatcher) Publish(ctx context.Context, eventType string, data interface{}) {
+	if d == nil || len(d.cfg.Webhooks) == 0 {
+		return
+	}
+	event := Event{
+		ID:        newEventID(),
+		Type:      eventType,
+		Time:      d.now().UTC(),
+		RequestID: log.GetReqID(ctx),
+		Data:      data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Logger.Error("failed to marshal the webhook event", zap.Error(err), zap.String("event", eventType))
+		return
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for i, sub := range d.cfg.Webhooks {
+		if !sub.Accepts(eventType) {
+			continue
+		}
+		if d.stopped {
+			d.deadLetter(sub, delivery{event: event, body: body}, 0, errStopped)
+			continue
+		}
+		select {
+		case d.queues[i] <- delivery{event: event, body: body}:
+		default:
+			d.deadLetter(sub, delivery{event: event, body: body}, 0, fmt.Errorf("the webhook queue is full"))
+		}
+	}
+}
+
+// errStopped is the dead letter error of the events that weren't delivered before the shutdown
+var errStopped = fmt.Errorf("the webhook dispatcher is stopped")
+
+// Run delivers the queued events until the context is done,
+// then drains the queues until the WebhookDrainTimeout (the deliveries in progress continue until then)
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d == nil {
+		return
+	}
+	deliverCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-deliverCtx.Done():
+			return
+		}
+		timer := time.NewTimer(d.cfg.WebhookDrainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-deliverCtx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i, sub := range d.cfg.Webhooks {
+		wg.Add(1)
+		go func(sub Subscriber, queue chan delivery) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					d.drain(deliverCtx, sub, queue)
+					return
+				case dl := <-queue:
+					d.deliver(deliverCtx, sub, dl)
+				}
+			}
+		}(sub, d.queues[i])
+	}
+	wg.Wait()
+
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+	// the events published while the last deliveries were in progress
+	for i, sub := range d.cfg.Webhooks {
+		d.drop(sub, d.queues[i])
+	}
+}
+
+// drain delivers the queued events until the queue is empty or the ctx is done (the drain deadline)
+func (d *Dispatcher) drain(ctx context.Context, sub Subscriber, queue chan delivery) {
+	for ctx.Err() == nil {
+		select {
+		case dl := <-queue:
+			d.deliver(ctx, sub, dl)
+		default:
+			return
+		}
+	}
+	d.drop(sub, queue)
+}
+
+// drop dead letters the queued events (they can be replayed from the dead letter logs)
+func (d *Dispatcher) drop(sub Subscriber, queue chan delivery) {
+	dropped := 0
+	for {
+		select {
+		case dl := <-queue:
+			d.deadLetter(sub, dl, 0, errStopped)
+			dropped++
+		default:
+			if dropped > 0 {
+				log.Logger.Warn("dropped the queued webhook events on shutdown",
+					zap.String("webhook", webhookHost(sub.URL)), zap.Int("dropped", dropped))
+			}
+			return
+		}
+	}
+}
+
+// deliver posts the event to the subscriber, retrying the transient failures
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscriber, dl delivery) {
+	backoff := d.cfg.WebhookRetryBackoff
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		var retry bool
+		if retry, err = d.post(ctx, sub, dl); err == nil {
+			statDeliveries.WithLabelValues(dl.event.Type, "success").Inc()
+			return
+		}
+		if !retry || attempt >= d.cfg.WebhookMaxAttempts {
+			break
+		}
+		statDeliveries.WithLabelValues(dl.event.Type, "retry").Inc()
+		if !d.sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+	d.deadLetter(sub, dl, attempt, err)
+}
+
+// post sends the signed event, the network errors, 429 and 5xx responses are retried
+func (d *Dispatcher) post(ctx context.Context, sub Subscriber, dl delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(dl.body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.event.Type)
+	req.Header.Set(DeliveryHeader, dl.event.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if len(sub.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Signature(sub.Secret, timestamp, dl.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+}
+
+// logDeadLetter logs the undelivered event (with its body so it can be replayed)
+func (d *Dispatcher) logDeadLetter(sub Subscriber, dl delivery, attempts int, err error) {
+	statDeliveries.WithLabelValues(dl.event.Type, "dead_letter").Inc()
+	log.Logger.Error("webhook dead letter",
+		zap.String("webhook", webhookHost(sub.URL)),
+		zap.String("event", dl.event.Type),
+		zap.String("delivery", dl.event.ID),
+		zap.Int("attempts", attempts),
+		zap.ByteString("body", dl.body),
+		zap.Error(err))
+}
+
+// webhookHost is the host of the webhook url (the path and the query may have tokens)
+func webhookHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// newEventID is a random event id (the receivers dedupe the retried deliveries by id)
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
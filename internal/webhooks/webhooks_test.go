@@ -0,0 +1,221 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unanet/eve/pkg/eve"
+)
+
+// receiver records the webhook requests and responds with the queued status codes (200 once they're used)
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newReceiver(statuses ...int) (*receiver, *httptest.Server) {
+	r := &receiver{statuses: statuses, received: make(chan struct{}, 10)}
+	return r, httptest.NewServer(r)
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.mu.Unlock()
+	w.WriteHeader(status)
+	r.received <- struct{}{}
+}
+
+func testDispatcher(subscribers ...Subscriber) (*Dispatcher, *[]string) {
+	d := New(Config{Webhooks: subscribers, WebhookMaxAttempts: 3, WebhookRetryBackoff: time.Millisecond, WebhookTimeout: time.Second, WebhookQueueSize: 10, WebhookDrainTimeout: 5 * time.Second})
+	d.now = func() time.Time { return time.Unix(1622548800, 0) }
+	var deadLetters []string
+	d.deadLetter = func(sub Subscriber, dl delivery, attempts int, err error) {
+		deadLetters = append(deadLetters, dl.event.Type)
+	}
+	return d, &deadLetters
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	rcv, srv := newReceiver()
+	defer srv.Close()
+	d, deadLetters := testDispatcher(Subscriber{URL: srv.URL, Secret: "s3cr3t", Events: []string{"deployment.*"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Publish(ctx, EventCommandExecuted, CommandData{Command: "deploy"})
+	d.Publish(ctx, EventDeploymentFailed, DeploymentData{Deployment: "d1"})
+	select {
+	case <-rcv.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't delivered")
+	}
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if len(rcv.requests) != 1 {
+		t.Fatalf("requests = %d, want only the deployment event", len(rcv.requests))
+	}
+	req, body := rcv.requests[0], rcv.bodies[0]
+	if req.Header.Get(EventHeader) != EventDeploymentFailed || req.Header.Get(TimestampHeader) != "1622548800" {
+		t.Errorf("unexpected headers %v", req.Header)
+	}
+	if req.Header.Get(SignatureHeader) != "sha256="+Signature("s3cr3t", "1622548800", body) {
+		t.Errorf("signature = %q, want the HMAC of the body", req.Header.Get(SignatureHeader))
+	}
+	var event struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data DeploymentData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventDeploymentFailed || event.Data.Deployment != "d1" || event.ID != req.Header.Get(DeliveryHeader) {
+		t.Errorf("unexpected event %s", body)
+	}
+	if len(*deadLetters) != 0 {
+		t.Errorf("dead letters = %v, want none", *deadLetters)
+	}
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantDead     bool
+	}{
+		{name: "success", wantRequests: 1},
+		{name: "retried", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, wantRequests: 3},
+		{name: "dead letter after the last attempt", statuses: []int{500, 502, 503, 504}, wantRequests: 3, wantDead: true},
+		{name: "rejected", statuses: []int{http.StatusBadRequest}, wantRequests: 1, wantDead: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rcv, srv := newReceiver(tt.statuses...)
+			defer srv.Close()
+			sub := Subscriber{URL: srv.URL}
+			d, deadLetters := testDispatcher(sub)
+
+			d.deliver(context.Background(), sub, delivery{event: Event{ID: "1", Type: EventAuthDenied}, body: []byte(`{}`)})
+			if len(rcv.requests) != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", len(rcv.requests), tt.wantRequests)
+			}
+			if dead := len(*deadLetters) > 0; dead != tt.wantDead {
+				t.Errorf("dead letters = %v, want dead %v", *deadLetters, tt.wantDead)
+			}
+			if len(rcv.requests) > 0 && len(rcv.requests[0].Header.Get(SignatureHeader)) > 0 {
+				t.Errorf("unexpected signature without a secret")
+			}
+		})
+	}
+}
+
+func TestDispatcher_PublishQueueFull(t *testing.T) {
+	d, deadLetters := testDispatcher(Subscriber{URL: "http://localhost"})
+	for i := 0; i < 11; i++ {
+		d.Publish(context.Background(), EventMetadataChanged, MetadataData{Key: "eve-bot:api:current"})
+	}
+	if len(*deadLetters) != 1 {
+		t.Errorf("dead letters = %v, want the event that didn't fit in the queue", *deadLetters)
+	}
+}
+
+func TestDispatcher_DrainsOnShutdown(t *testing.T) {
+	rcv, srv := newReceiver()
+	defer srv.Close()
+	d, deadLetters := testDispatcher(Subscriber{URL: srv.URL})
+	d.Publish(context.Background(), EventCommandExecuted, CommandData{Command: "deploy"})
+	d.Publish(context.Background(), EventCommandExecuted, CommandData{Command: "restart"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+	rcv.mu.Lock()
+	if len(rcv.requests) != 2 || len(*deadLetters) != 0 {
+		t.Errorf("requests = %d, dead letters = %v, want the queued events delivered", len(rcv.requests), *deadLetters)
+	}
+	rcv.mu.Unlock()
+
+	// the events published after the shutdown are dead lettered
+	d.Publish(context.Background(), EventCommandExecuted, CommandData{Command: "deploy"})
+	if len(*deadLetters) != 1 {
+		t.Errorf("dead letters = %v, want the event published after the shutdown", *deadLetters)
+	}
+}
+
+func TestDispatcher_DrainDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	d, deadLetters := testDispatcher(Subscriber{URL: srv.URL})
+	d.cfg.WebhookDrainTimeout = 50 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		d.Publish(context.Background(), EventCommandExecuted, CommandData{Command: "deploy"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() didn't return at the drain deadline")
+	}
+	if len(*deadLetters) != 3 {
+		t.Errorf("dead letters = %v, want the undelivered events", *deadLetters)
+	}
+}
+
+func TestSubscribers_Decode(t *testing.T) {
+	var subscribers Subscribers
+	if err := subscribers.Decode(`[{"url": "https://status/hooks", "secret": "x", "events": ["deployment.*", "auth.denied"]}]`); err != nil {
+		t.Fatal(err)
+	}
+	if !subscribers[0].Accepts(EventDeploymentCompleted) || !subscribers[0].Accepts(EventAuthDenied) || subscribers[0].Accepts(EventCommandExecuted) {
+		t.Errorf("unexpected event filter %v", subscribers[0].Events)
+	}
+	if !(Subscriber{}).Accepts(EventMetadataChanged) {
+		t.Error("expected a subscriber without events to accept every event")
+	}
+	for _, invalid := range []string{`{}`, `[{"url": "status/hooks"}]`, `[{"url": "https://status", "events": ["["]}]`} {
+		if err := subscribers.Decode(invalid); err == nil {
+			t.Errorf("Decode(%s) = nil, want an error", invalid)
+		}
+	}
+}
+
+func TestDeploymentEventType(t *testing.T) {
+	if DeploymentEventType(eve.DeploymentPlanStatusComplete) != EventDeploymentCompleted ||
+		DeploymentEventType(eve.DeploymentPlanStatusErrors) != EventDeploymentFailed ||
+		DeploymentEventType(eve.DeploymentPlanStatusPending) != "" {
+		t.Error("unexpected deployment event types")
+	}
+}